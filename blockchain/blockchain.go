@@ -42,17 +42,20 @@ func New(genesisHeader util.BlockHeader, db database.ChainStore) (*BlockChain, e
 	return &BlockChain{db: db}, nil
 }
 
-func (b *BlockChain) CommitBlock(block *util.Block) (newTip, reorg bool, newHeight, fps uint32, err error) {
+// CommitBlock saves the block into database, if the block causes a chain
+// reorganization, the returned reorg describes the detached and attached
+// blocks, otherwise it is nil.
+func (b *BlockChain) CommitBlock(block *util.Block) (newTip bool, reorg *util.Reorg, newHeight, fps uint32, err error) {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 	newTip = false
-	reorg = false
+	isReorg := false
 	var header = &block.Header
 	var commonAncestor *util.Header
 	// Fetch our current best header from the db
 	bestHeader, err := b.db.Headers().GetBest()
 	if err != nil {
-		return false, false, 0, 0, err
+		return false, nil, 0, 0, err
 	}
 	tipHash := bestHeader.Hash()
 	var parentHeader *util.Header
@@ -64,17 +67,17 @@ func (b *BlockChain) CommitBlock(block *util.Block) (newTip, reorg bool, newHeig
 	} else {
 		parentHeader, err = b.db.Headers().GetPrevious(header)
 		if err != nil {
-			return false, false, 0, 0, OrphanBlockError
+			return false, nil, 0, 0, OrphanBlockError
 		}
 	}
 	valid := b.checkHeader(header, parentHeader)
 	if !valid {
-		return false, false, 0, 0, nil
+		return false, nil, 0, 0, nil
 	}
 	// If this block is already the tip, return
 	headerHash := header.Hash()
 	if tipHash.IsEqual(headerHash) {
-		return false, false, 0, 0, nil
+		return false, nil, 0, 0, nil
 	}
 	// Add the work of this header to the total work stored at the previous header
 	cumulativeWork := new(big.Int).Add(parentHeader.TotalWork, CalcWork(header.Bits()))
@@ -86,7 +89,7 @@ func (b *BlockChain) CommitBlock(block *util.Block) (newTip, reorg bool, newHeig
 
 		// If this header is not extending the previous best header then we have a reorg.
 		if !tipHash.IsEqual(parentHeader.Hash()) {
-			reorg = true
+			isReorg = true
 		}
 	}
 
@@ -94,14 +97,17 @@ func (b *BlockChain) CommitBlock(block *util.Block) (newTip, reorg bool, newHeig
 	newHeight = parentHeader.Height + 1
 	header.Height = newHeight
 	header.TotalWork = cumulativeWork
-	fps, err = b.db.CommitBlock(block, newTip)
+
+	// A block causing reorganize is saved as a fork block first, then moved to
+	// main chain with the other blocks of the new best chain.
+	fps, err = b.db.CommitBlock(block, newTip && !isReorg)
 	if err != nil {
-		return newTip, reorg, 0, 0, err
+		return newTip, nil, 0, 0, err
 	}
 
 	// If not meet a reorg, just return.
-	if !reorg {
-		return newTip, reorg, newHeight, fps, nil
+	if !isReorg {
+		return newTip, nil, newHeight, fps, nil
 	}
 
 	// Find common ancestor of the fork chain, so we can rollback chain to the
@@ -111,15 +117,15 @@ func (b *BlockChain) CommitBlock(block *util.Block) (newTip, reorg bool, newHeig
 		// This should not happen, because we didn't store orphan blocks in
 		// database, all headers should be connected.
 		log.Errorf("Error calculating common ancestor: %s", err.Error())
-		return newTip, reorg, 0, 0, err
+		return newTip, nil, 0, 0, err
 	}
 
 	// Process block chain reorganize.
 	log.Infof("REORG!!! At block %d, Wiped out %d blocks",
 		bestHeader.Height, bestHeader.Height-commonAncestor.Height)
-	err = b.db.ProcessReorganize(commonAncestor, bestHeader, header)
+	reorg, err = b.db.ProcessReorganize(commonAncestor, bestHeader, header)
 	if err != nil {
-		return newTip, reorg, 0, 0, err
+		return newTip, nil, 0, 0, err
 	}
 	return newTip, reorg, newHeight, fps, nil
 }
@@ -136,7 +142,7 @@ func (b *BlockChain) checkHeader(header *util.Header, prevHeader *util.Header) b
 	}
 
 	// Check if there's a valid proof of work.  That whole "Bitcoin" thing.
-	if !checkProofOfWork(*header) {
+	if !CheckProofOfWork(header.BlockHeader) {
 		log.Debugf("Block %d bad proof of work.\n", height+1)
 		return false
 	}
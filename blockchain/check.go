@@ -0,0 +1,93 @@
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// ChainCheck is the result of CheckChain.
+type ChainCheck struct {
+	// Best is the chain tip stored in database.
+	Best *util.Header
+
+	// LastGood is the highest header that all headers from genesis to it
+	// are consistent, it is nil if the chain can not be walked to genesis.
+	LastGood *util.Header
+
+	// Hashes are the main chain header hashes indexed by height, from
+	// genesis to LastGood.
+	Hashes map[uint32]common.Uint256
+
+	// Errors are the inconsistencies found from chain tip to genesis.
+	Errors []error
+}
+
+// CheckChain walks the header chain from the chain tip to genesis, checks the
+// height and TotalWork of every header continue from it's previous header.
+func CheckChain(headers database.Headers) (*ChainCheck, error) {
+	best, err := headers.GetBest()
+	if err != nil {
+		return nil, err
+	}
+
+	check := &ChainCheck{
+		Best:   best,
+		Hashes: make(map[uint32]common.Uint256),
+	}
+
+	// lastBad is the lowest header which is not consistent with it's
+	// previous header.
+	var lastBad *util.Header
+	header := best
+	for header.Height > 0 {
+		check.Hashes[header.Height] = header.Hash()
+
+		parent, err := headers.GetPrevious(header)
+		if err != nil {
+			check.Errors = append(check.Errors, fmt.Errorf("header %s on"+
+				" height %d missing previous header", header.Hash(),
+				header.Height))
+			return check, nil
+		}
+
+		if header.Height != parent.Height+1 {
+			lastBad = header
+			check.Errors = append(check.Errors, fmt.Errorf("header %s on"+
+				" height %d not continue previous height %d", header.Hash(),
+				header.Height, parent.Height))
+		}
+
+		work := CalcWork(header.Bits())
+		if parent.TotalWork != nil {
+			work.Add(work, parent.TotalWork)
+		}
+		if header.TotalWork == nil || header.TotalWork.Cmp(work) != 0 {
+			lastBad = header
+			check.Errors = append(check.Errors, fmt.Errorf("header %s on"+
+				" height %d total work %v, expect %v", header.Hash(),
+				header.Height, header.TotalWork, work))
+		}
+
+		header = parent
+	}
+	check.Hashes[header.Height] = header.Hash()
+
+	check.LastGood = best
+	if lastBad != nil {
+		check.LastGood, err = headers.GetPrevious(lastBad)
+		if err != nil {
+			return nil, err
+		}
+		for height := range check.Hashes {
+			if height > check.LastGood.Height {
+				delete(check.Hashes, height)
+			}
+		}
+	}
+
+	return check, nil
+}
@@ -0,0 +1,73 @@
+package blockchain
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestHeader(prev *util.Header) *util.Header {
+	header := &types.Header{Bits: 0x1d00ffff}
+	if prev == nil {
+		return &util.Header{BlockHeader: iutil.NewHeader(header),
+			TotalWork: new(big.Int)}
+	}
+	header.Previous = prev.Hash()
+	header.Height = prev.Height + 1
+	return &util.Header{
+		BlockHeader: iutil.NewHeader(header),
+		Height:      prev.Height + 1,
+		TotalWork:   new(big.Int).Add(prev.TotalWork, CalcWork(header.Bits)),
+	}
+}
+
+func TestCheckChain(t *testing.T) {
+	db := database.NewMemHeaders()
+
+	headers := []*util.Header{newTestHeader(nil)}
+	for i := 1; i < 10; i++ {
+		headers = append(headers, newTestHeader(headers[i-1]))
+	}
+	for _, header := range headers {
+		db.Put(header, true)
+	}
+
+	check, err := CheckChain(db)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Empty(t, check.Errors)
+	assert.Equal(t, headers[9].Hash(), check.LastGood.Hash())
+	assert.Equal(t, 10, len(check.Hashes))
+
+	// Break total work on height 6.
+	headers[6].TotalWork = new(big.Int).Add(headers[6].TotalWork, big.NewInt(1))
+	db.Put(headers[6], false)
+
+	check, err = CheckChain(db)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	// Both height 6 and 7 not continue their previous total work.
+	assert.Equal(t, 2, len(check.Errors))
+	assert.Equal(t, headers[5].Hash(), check.LastGood.Hash())
+	assert.Equal(t, 6, len(check.Hashes))
+
+	// Missing previous header.
+	db = database.NewMemHeaders()
+	for _, header := range headers[5:] {
+		db.Put(header, true)
+	}
+	check, err = CheckChain(db)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Nil(t, check.LastGood)
+	assert.NotEmpty(t, check.Errors)
+}
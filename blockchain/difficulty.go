@@ -9,6 +9,17 @@ import (
 
 var PowLimit = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(1))
 
+const (
+	// BlocksPerRetarget is the number of blocks between difficulty
+	// retargets, the target timespan of a day divided by the two minutes
+	// target time per block.
+	BlocksPerRetarget = 720
+
+	// RetargetAdjustmentFactor is the factor limits how much easier the
+	// target can be after a retarget.
+	RetargetAdjustmentFactor = 4
+)
+
 func CalcWork(bits uint32) *big.Int {
 	// Return a work value of zero if the passed difficulty bits represent
 	// a negative number. Note this should not happen in practice with valid
@@ -23,7 +34,9 @@ func CalcWork(bits uint32) *big.Int {
 	return new(big.Int).Div(new(big.Int).Lsh(big.NewInt(1), 256), denominator)
 }
 
-func checkProofOfWork(header util.Header) bool {
+// CheckProofOfWork returns if the header's target difficulty is in range and
+// it's proof of work hash meets the target.
+func CheckProofOfWork(header util.BlockHeader) bool {
 	// The target difficulty must be larger than zero.
 	target := CompactToBig(header.Bits())
 	if target.Sign() <= 0 {
@@ -45,6 +58,23 @@ func checkProofOfWork(header util.Header) bool {
 	return true
 }
 
+// CheckDifficultyTransition returns if the header on the given height can
+// claim bits after a previous header of prevBits.  The target is not changed
+// except on retarget heights, and a retarget can not make it easier than
+// RetargetAdjustmentFactor times of the previous target.
+func CheckDifficultyTransition(height, prevBits, bits uint32) bool {
+	if height%BlocksPerRetarget != 0 {
+		return bits == prevBits
+	}
+
+	maxTarget := new(big.Int).Mul(CompactToBig(prevBits),
+		big.NewInt(RetargetAdjustmentFactor))
+	if maxTarget.Cmp(PowLimit) > 0 {
+		maxTarget = PowLimit
+	}
+	return CompactToBig(bits).Cmp(maxTarget) <= 0
+}
+
 func HashToBig(hash *common.Uint256) *big.Int {
 	// A Hash is in little-endian, but the big package wants the bytes in
 	// big-endian, so reverse them.
@@ -32,11 +32,25 @@ func minUint32(a, b uint32) uint32 {
 	return b
 }
 
+// UpdateType defines how the filter is updated when a transaction matches,
+// like the nFlags of BIP37 filterload message.
+type UpdateType uint8
+
+const (
+	// UpdateNone indicates the filter is not updated on matches.
+	UpdateNone UpdateType = iota
+
+	// UpdateAll indicates the outpoints of all matched outputs are added to
+	// the filter, so transactions spending them will be matched.
+	UpdateAll
+)
+
 // Filter defines a bitcoin bloom filter that provides easy manipulation of raw
 // filter data.
 type Filter struct {
-	mtx sync.Mutex
-	msg *msg.FilterLoad
+	mtx    sync.Mutex
+	msg    *msg.FilterLoad
+	update UpdateType
 }
 
 // NewFilter creates a new bloom filter instance, mainly to be used by SPV
@@ -240,6 +254,68 @@ func (bf *Filter) AddOutPoint(outpoint *util.OutPoint) {
 	bf.mtx.Unlock()
 }
 
+// EstimatedFalsePositiveRate returns the false positive rate estimated by the
+// ratio of set bits in the filter, it increases as elements are added.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) EstimatedFalsePositiveRate() float64 {
+	bf.mtx.Lock()
+	defer bf.mtx.Unlock()
+
+	if bf.msg == nil || len(bf.msg.Filter) == 0 {
+		return 1.0
+	}
+
+	var set int
+	for _, b := range bf.msg.Filter {
+		for ; b != 0; b &= b - 1 {
+			set++
+		}
+	}
+	ratio := float64(set) / float64(len(bf.msg.Filter)*8)
+	return math.Pow(ratio, float64(bf.msg.HashFuncs))
+}
+
+// SetUpdateType sets how the filter is updated by MatchTxAndUpdate.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) SetUpdateType(update UpdateType) {
+	bf.mtx.Lock()
+	bf.update = update
+	bf.mtx.Unlock()
+}
+
+// updater applies the filter update type to the elements added by
+// util.Transaction.MatchFilter.
+//
+// The filter lock MUST be held while using updater.
+type updater struct {
+	*Filter
+}
+
+func (u updater) Add(data []byte) {
+	if u.update == UpdateAll {
+		u.add(data)
+	}
+}
+
+func (u updater) Matches(data []byte) bool {
+	return u.matches(data)
+}
+
+// MatchTxAndUpdate returns true if the bloom filter matches data within the
+// passed transaction, and updates the filter by the update type, so it has
+// the same state as the filter loaded to a peer which matched the same
+// transactions.
+//
+// This function is safe for concurrent access.
+func (bf *Filter) MatchTxAndUpdate(tx util.Transaction) bool {
+	bf.mtx.Lock()
+	match := tx.MatchFilter(updater{bf})
+	bf.mtx.Unlock()
+	return match
+}
+
 func (bf *Filter) GetFilterLoadMsg() *msg.FilterLoad {
 	return bf.msg
 }
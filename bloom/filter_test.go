@@ -0,0 +1,74 @@
+package bloom
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_EstimatedFalsePositiveRate(t *testing.T) {
+	f := NewFilter(100, 0, 0.0001)
+	assert.Equal(t, float64(0), f.EstimatedFalsePositiveRate())
+
+	// The estimated rate is close to the target rate when the filter is
+	// filled with the elements it was created for.
+	for i := 0; i < 100; i++ {
+		var data [21]byte
+		rand.Read(data[:])
+		f.Add(data[:])
+	}
+	rate := f.EstimatedFalsePositiveRate()
+	assert.True(t, rate > 0.00001 && rate < 0.001, "rate %f", rate)
+
+	// And increases as more elements added.
+	for i := 0; i < 100; i++ {
+		var data [21]byte
+		rand.Read(data[:])
+		f.Add(data[:])
+	}
+	assert.True(t, f.EstimatedFalsePositiveRate() > rate*10)
+
+	assert.Equal(t, float64(1), NewFilter(0, 0, 0).EstimatedFalsePositiveRate())
+}
+
+func TestFilter_MatchTxAndUpdate(t *testing.T) {
+	var addr common.Uint168
+	rand.Read(addr[:])
+
+	// tx1 pays to addr, tx2 spends the output of tx1.
+	tx1 := iutil.NewTx(&types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Outputs: []*types.Output{{ProgramHash: addr}},
+	})
+	tx2 := iutil.NewTx(&types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Inputs: []*types.Input{
+			{Previous: *types.NewOutPoint(tx1.Hash(), 0)},
+		},
+	})
+
+	none := NewFilter(10, 0, 0.0001)
+	none.Add(addr[:])
+	all := NewFilter(10, 0, 0.0001)
+	all.Add(addr[:])
+	all.SetUpdateType(UpdateAll)
+
+	assert.True(t, none.MatchTxAndUpdate(tx1))
+	assert.True(t, all.MatchTxAndUpdate(tx1))
+
+	op := util.NewOutPoint(tx1.Hash(), 0)
+	assert.False(t, none.MatchesOutPoint(op))
+	assert.True(t, all.MatchesOutPoint(op))
+
+	assert.False(t, none.MatchTxAndUpdate(tx2))
+	assert.True(t, all.MatchTxAndUpdate(tx2))
+}
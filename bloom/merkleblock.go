@@ -307,3 +307,143 @@ func MakeMerkleParent(left *common.Uint256, right *common.Uint256) (*common.Uint
 	parent := common.Uint256(common.Sha256D(sha[:]))
 	return &parent, nil
 }
+
+// partialTree collects the nodes of a merkle tree known from merkle blocks of
+// the same block.
+type partialTree struct {
+	numTx   uint32
+	hashes  map[uint64]*common.Uint256
+	matched map[uint32]struct{}
+}
+
+func nodeKey(height, pos uint32) uint64 {
+	return uint64(height)<<32 | uint64(pos)
+}
+
+func (t *partialTree) calcTreeWidth(height uint32) uint32 {
+	return (t.numTx + (1 << height) - 1) >> height
+}
+
+// parse walks the partial merkle tree of a merkle block in depth-first order
+// and records the hashes of all visited nodes.
+func (t *partialTree) parse(height, pos uint32, hashes *[]*common.Uint256,
+	flags []byte, bit *uint32) (*common.Uint256, error) {
+
+	if *bit >= uint32(len(flags))*8 {
+		return nil, errors.New("ran out of flag bits")
+	}
+	isParent := flags[*bit/8]&(1<<(*bit%8)) != 0
+	*bit++
+
+	if height == 0 || !isParent {
+		if len(*hashes) == 0 {
+			return nil, errors.New("ran out of hashes")
+		}
+		hash := (*hashes)[0]
+		*hashes = (*hashes)[1:]
+		t.hashes[nodeKey(height, pos)] = hash
+		if height == 0 && isParent {
+			t.matched[pos] = struct{}{}
+		}
+		return hash, nil
+	}
+
+	left, err := t.parse(height-1, pos*2, hashes, flags, bit)
+	if err != nil {
+		return nil, err
+	}
+	right := left
+	if pos*2+1 < t.calcTreeWidth(height-1) {
+		right, err = t.parse(height-1, pos*2+1, hashes, flags, bit)
+		if err != nil {
+			return nil, err
+		}
+	}
+	hash := HashMerkleBranches(left, right)
+	t.hashes[nodeKey(height, pos)] = hash
+	return hash, nil
+}
+
+// build creates the partial merkle tree including all matched leaves.
+func (t *partialTree) build(height, pos uint32, m *mBlock) error {
+	var isParent byte
+	for i := pos << height; i < (pos+1)<<height && i < t.numTx; i++ {
+		if _, ok := t.matched[i]; ok {
+			isParent = 0x01
+			break
+		}
+	}
+	m.Bits = append(m.Bits, isParent)
+
+	if height == 0 || isParent == 0x00 {
+		hash, ok := t.hashes[nodeKey(height, pos)]
+		if !ok {
+			return fmt.Errorf("missing hash of node %d on height %d",
+				pos, height)
+		}
+		m.FinalHashes = append(m.FinalHashes, hash)
+		return nil
+	}
+
+	if err := t.build(height-1, pos*2, m); err != nil {
+		return err
+	}
+	if pos*2+1 < t.calcTreeWidth(height-1) {
+		return t.build(height-1, pos*2+1, m)
+	}
+	return nil
+}
+
+// MergeMerkleBlocks merges the merkle blocks of the same block filtered by
+// different filters into one merkle block, which matches all transactions
+// matched by the given merkle blocks.
+func MergeMerkleBlocks(blocks []*msg.MerkleBlock) (*msg.MerkleBlock, error) {
+	if len(blocks) == 0 {
+		return nil, errors.New("no merkle blocks to merge")
+	}
+
+	first := blocks[0]
+	hash := first.Header.(util.BlockHeader).Hash()
+	tree := partialTree{
+		numTx:   first.Transactions,
+		hashes:  make(map[uint64]*common.Uint256),
+		matched: make(map[uint32]struct{}),
+	}
+	height := uint32(0)
+	for tree.calcTreeWidth(height) > 1 {
+		height++
+	}
+
+	for _, m := range blocks {
+		if m.Header.(util.BlockHeader).Hash() != hash ||
+			m.Transactions != tree.numTx {
+			return nil, errors.New("merge merkle blocks of different blocks")
+		}
+		if _, err := CheckMerkleBlock(*m); err != nil {
+			return nil, err
+		}
+
+		var bit uint32
+		hashes := m.Hashes
+		_, err := tree.parse(height, 0, &hashes, m.Flags, &bit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var mBlock mBlock
+	if err := tree.build(height, 0, &mBlock); err != nil {
+		return nil, err
+	}
+
+	merkleBlock := &msg.MerkleBlock{
+		Header:       first.Header,
+		Transactions: tree.numTx,
+		Hashes:       mBlock.FinalHashes,
+		Flags:        make([]byte, (len(mBlock.Bits)+7)/8),
+	}
+	for i := uint32(0); i < uint32(len(mBlock.Bits)); i++ {
+		merkleBlock.Flags[i/8] |= mBlock.Bits[i] << (i % 8)
+	}
+	return merkleBlock, nil
+}
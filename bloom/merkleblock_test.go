@@ -0,0 +1,96 @@
+package bloom
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/p2p/msg"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMerkleBlock(hashes []*common.Uint256,
+	matches map[uint32]bool) msg.MerkleBlock {
+
+	txs := uint32(len(hashes))
+	mBlock := mBlock{
+		NumTx:       txs,
+		AllHashes:   hashes,
+		MatchedBits: make([]byte, 0, txs),
+	}
+	for i := uint32(0); i < txs; i++ {
+		if matches[i] {
+			mBlock.MatchedBits = append(mBlock.MatchedBits, 0x01)
+		} else {
+			mBlock.MatchedBits = append(mBlock.MatchedBits, 0x00)
+		}
+	}
+
+	height := uint32(0)
+	for mBlock.calcTreeWidth(height) > 1 {
+		height++
+	}
+	mBlock.traverseAndBuild(height, 0)
+
+	merkleBlock := msg.MerkleBlock{
+		Header: &header{&types.Header{
+			MerkleRoot: *mBlock.calcHash(treeDepth(txs), 0),
+		}},
+		Transactions: txs,
+		Hashes:       mBlock.FinalHashes,
+		Flags:        make([]byte, (len(mBlock.Bits)+7)/8),
+	}
+	for i := uint32(0); i < uint32(len(mBlock.Bits)); i++ {
+		merkleBlock.Flags[i/8] |= mBlock.Bits[i] << (i % 8)
+	}
+	return merkleBlock
+}
+
+func TestMergeMerkleBlocks(t *testing.T) {
+	for txs := uint32(1); txs < 100; txs++ {
+		hashes := make([]*common.Uint256, 0, txs)
+		for i := uint32(0); i < txs; i++ {
+			hashes = append(hashes, randHash())
+		}
+
+		m1 := newTestMerkleBlock(hashes, randMatches(txs))
+		m2 := newTestMerkleBlock(hashes, randMatches(txs))
+		merged, err := MergeMerkleBlocks([]*msg.MerkleBlock{&m1, &m2})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		ids1, err := CheckMerkleBlock(m1)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		ids2, err := CheckMerkleBlock(m2)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		ids, err := CheckMerkleBlock(*merged)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		// The merged block matches transactions of both blocks in the
+		// block order.
+		matched := make(map[common.Uint256]struct{})
+		for _, id := range append(ids1, ids2...) {
+			matched[*id] = struct{}{}
+		}
+		var expect []*common.Uint256
+		for _, hash := range hashes {
+			if _, ok := matched[*hash]; ok {
+				expect = append(expect, hash)
+			}
+		}
+		assert.Equal(t, expect, ids)
+	}
+
+	// Merkle blocks of different blocks can not be merged.
+	m1 := newTestMerkleBlock([]*common.Uint256{randHash()}, nil)
+	m2 := newTestMerkleBlock([]*common.Uint256{randHash()}, nil)
+	_, err := MergeMerkleBlocks([]*msg.MerkleBlock{&m1, &m2})
+	assert.Error(t, err)
+}
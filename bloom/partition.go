@@ -0,0 +1,60 @@
+package bloom
+
+import (
+	"math"
+)
+
+// MaxFilterElements returns the maximum number of elements a filter of
+// MaxFilterLoadFilterSize can hold within the false positive rate.
+func MaxFilterElements(fprate float64) uint32 {
+	if fprate > 1.0 {
+		fprate = 1.0
+	}
+	if fprate < 1e-9 {
+		fprate = 1e-9
+	}
+
+	// Equivalent to n = -(m*ln(2)^2 / ln(p)), where m is in bits.
+	n := -1 * float64(MaxFilterLoadFilterSize*8) * ln2Squared / math.Log(fprate)
+	if n < 1 {
+		return 1
+	}
+	return uint32(n)
+}
+
+// NewFilters creates filters holding the given elements within the false
+// positive rate.  Elements are partitioned into multiple filters if they can
+// not be held by one filter, the partition of an element is decided by it's
+// hash, so it stays in the same partition while the number of partitions is
+// not changed.
+func NewFilters(elements [][]byte, tweak uint32, fprate float64) []*Filter {
+	// Leave some room for uneven partitions.
+	max := MaxFilterElements(fprate) * 9 / 10
+	if max == 0 {
+		max = 1
+	}
+	n := (uint32(len(elements)) + max - 1) / max
+	if n <= 1 {
+		f := NewFilter(uint32(len(elements)), tweak, fprate)
+		for _, e := range elements {
+			f.Add(e)
+		}
+		return []*Filter{f}
+	}
+
+	partitions := make([][][]byte, n)
+	for _, e := range elements {
+		i := MurmurHash3(tweak, e) % n
+		partitions[i] = append(partitions[i], e)
+	}
+
+	filters := make([]*Filter, 0, n)
+	for _, partition := range partitions {
+		f := NewFilter(uint32(len(partition)), tweak, fprate)
+		for _, e := range partition {
+			f.Add(e)
+		}
+		filters = append(filters, f)
+	}
+	return filters
+}
@@ -0,0 +1,331 @@
+package bloom
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/elastos/Elastos.ELA.SPV/blockchain"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/auxpow"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/p2p/msg"
+)
+
+const (
+	// MaxProofBundleHeaders is the maximum number of headers in a proof
+	// bundle, it limits the memory used to decode a bundle.
+	MaxProofBundleHeaders = 100000
+
+	// maxBranchesPerProof is the maximum depth of a merkle tree.
+	maxBranchesPerProof = 32
+)
+
+// ProofBundle is a self-contained proof of a transaction included in a block.
+// Unlike MerkleProof, which references the block by hash, it carries the
+// header chain from a trusted checkpoint to the block, so it can be verified
+// with VerifyProofBundle without a header store.
+type ProofBundle struct {
+	// CheckpointHeight is the height of the trusted checkpoint block.
+	CheckpointHeight uint32
+
+	// Height is the height of the block including the transaction.
+	Height uint32
+
+	// Headers is the header chain starts from the checkpoint block, it
+	// includes the block of the transaction and may include blocks after
+	// it as confirmations.
+	Headers []util.BlockHeader
+
+	// Tx is the transaction to prove.
+	Tx util.Transaction
+
+	// Branch is the merkle branch from the transaction to the merkle root.
+	Branch MerkleBranch
+
+	newHeader func() util.BlockHeader
+	newTx     func() util.Transaction
+}
+
+// NewProofBundle returns an empty proof bundle to decode into, the given
+// functions create the header and transaction instances to decode.
+func NewProofBundle(newHeader func() util.BlockHeader,
+	newTx func() util.Transaction) *ProofBundle {
+	return &ProofBundle{newHeader: newHeader, newTx: newTx}
+}
+
+// CreateProofBundle creates a proof bundle of the transaction in the merkle
+// block.  The headers start from the checkpoint block at checkpointHeight,
+// and must include the header of the merkle block at height.
+func CreateProofBundle(checkpointHeight, height uint32,
+	headers []util.BlockHeader, tx util.Transaction,
+	merkleBlock msg.MerkleBlock) (*ProofBundle, error) {
+
+	if height < checkpointHeight ||
+		height-checkpointHeight >= uint32(len(headers)) {
+		return nil, errors.New("headers not include the block")
+	}
+
+	txId := tx.Hash()
+	branch, err := GetTxMerkleBranch(merkleBlock, &txId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ProofBundle{
+		CheckpointHeight: checkpointHeight,
+		Height:           height,
+		Headers:          headers,
+		Tx:               tx,
+		Branch:           *branch,
+	}, nil
+}
+
+// Checkpoint returns the hash of the checkpoint block the header chain starts
+// from.
+func (b *ProofBundle) Checkpoint() common.Uint256 {
+	if len(b.Headers) == 0 {
+		return common.EmptyHash
+	}
+	return b.Headers[0].Hash()
+}
+
+// Confirmations returns the number of blocks on the header chain since the
+// block including the transaction, including the block itself.
+func (b *ProofBundle) Confirmations() uint32 {
+	if b.Height < b.CheckpointHeight ||
+		b.Height-b.CheckpointHeight >= uint32(len(b.Headers)) {
+		return 0
+	}
+	return uint32(len(b.Headers)) - (b.Height - b.CheckpointHeight)
+}
+
+func (b *ProofBundle) Serialize(w io.Writer) error {
+	err := common.WriteElements(w, b.CheckpointHeight, b.Height)
+	if err != nil {
+		return err
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(b.Headers))); err != nil {
+		return err
+	}
+	for _, header := range b.Headers {
+		if err := header.Serialize(w); err != nil {
+			return err
+		}
+	}
+
+	if err := b.Tx.Serialize(w); err != nil {
+		return err
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(b.Branch.Branches))); err != nil {
+		return err
+	}
+	for _, hash := range b.Branch.Branches {
+		if err := hash.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return common.WriteUint32(w, uint32(b.Branch.Index))
+}
+
+func (b *ProofBundle) Deserialize(r io.Reader) error {
+	if b.newHeader == nil || b.newTx == nil {
+		return errors.New("proof bundle not created by NewProofBundle")
+	}
+
+	err := common.ReadElements(r, &b.CheckpointHeight, &b.Height)
+	if err != nil {
+		return err
+	}
+
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+	if count > MaxProofBundleHeaders {
+		return fmt.Errorf("ProofBundle.Deserialize too many headers"+
+			" [count %d, max %d]", count, MaxProofBundleHeaders)
+	}
+	b.Headers = make([]util.BlockHeader, 0, count)
+	for i := uint64(0); i < count; i++ {
+		header := b.newHeader()
+		if err := header.Deserialize(r); err != nil {
+			return err
+		}
+		b.Headers = append(b.Headers, header)
+	}
+
+	b.Tx = b.newTx()
+	if err := b.Tx.Deserialize(r); err != nil {
+		return err
+	}
+
+	count, err = common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+	if count > maxBranchesPerProof {
+		return fmt.Errorf("ProofBundle.Deserialize too many merkle"+
+			" branches [count %d, max %d]", count, maxBranchesPerProof)
+	}
+	b.Branch.Branches = make([]common.Uint256, count)
+	for i := range b.Branch.Branches {
+		if err := b.Branch.Branches[i].Deserialize(r); err != nil {
+			return err
+		}
+	}
+	index, err := common.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	b.Branch.Index = int(index)
+	return nil
+}
+
+// proofBundleJSON is the JSON encoding of ProofBundle, headers and the
+// transaction are encoded as hex strings of their binary encoding.
+type proofBundleJSON struct {
+	CheckpointHeight uint32
+	Height           uint32
+	Headers          []string
+	Tx               string
+	Branches         []string
+	Index            int
+}
+
+func (b *ProofBundle) MarshalJSON() ([]byte, error) {
+	j := proofBundleJSON{
+		CheckpointHeight: b.CheckpointHeight,
+		Height:           b.Height,
+		Headers:          make([]string, 0, len(b.Headers)),
+		Branches:         make([]string, 0, len(b.Branch.Branches)),
+		Index:            b.Branch.Index,
+	}
+
+	buf := new(bytes.Buffer)
+	for _, header := range b.Headers {
+		buf.Reset()
+		if err := header.Serialize(buf); err != nil {
+			return nil, err
+		}
+		j.Headers = append(j.Headers, common.BytesToHexString(buf.Bytes()))
+	}
+
+	buf.Reset()
+	if err := b.Tx.Serialize(buf); err != nil {
+		return nil, err
+	}
+	j.Tx = common.BytesToHexString(buf.Bytes())
+
+	for _, hash := range b.Branch.Branches {
+		j.Branches = append(j.Branches, hash.String())
+	}
+	return json.Marshal(j)
+}
+
+func (b *ProofBundle) UnmarshalJSON(data []byte) error {
+	if b.newHeader == nil || b.newTx == nil {
+		return errors.New("proof bundle not created by NewProofBundle")
+	}
+
+	var j proofBundleJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if len(j.Headers) > MaxProofBundleHeaders {
+		return fmt.Errorf("ProofBundle.UnmarshalJSON too many headers"+
+			" [count %d, max %d]", len(j.Headers), MaxProofBundleHeaders)
+	}
+	if len(j.Branches) > maxBranchesPerProof {
+		return fmt.Errorf("ProofBundle.UnmarshalJSON too many merkle"+
+			" branches [count %d, max %d]", len(j.Branches),
+			maxBranchesPerProof)
+	}
+
+	b.CheckpointHeight = j.CheckpointHeight
+	b.Height = j.Height
+	b.Headers = make([]util.BlockHeader, 0, len(j.Headers))
+	for _, str := range j.Headers {
+		data, err := common.HexStringToBytes(str)
+		if err != nil {
+			return err
+		}
+		header := b.newHeader()
+		if err := header.Deserialize(bytes.NewReader(data)); err != nil {
+			return err
+		}
+		b.Headers = append(b.Headers, header)
+	}
+
+	txData, err := common.HexStringToBytes(j.Tx)
+	if err != nil {
+		return err
+	}
+	b.Tx = b.newTx()
+	if err := b.Tx.Deserialize(bytes.NewReader(txData)); err != nil {
+		return err
+	}
+
+	b.Branch.Branches = make([]common.Uint256, 0, len(j.Branches))
+	for _, str := range j.Branches {
+		hash, err := common.Uint256FromHexString(str)
+		if err != nil {
+			return err
+		}
+		b.Branch.Branches = append(b.Branch.Branches, *hash)
+	}
+	b.Branch.Index = j.Index
+	return nil
+}
+
+// VerifyProofBundle verifies the proof bundle against the trusted checkpoint.
+// It checks the header chain connects to the checkpoint, the proof of work
+// of every header, the difficulty transitions from the checkpoint so no
+// header claims an easier target than allowed, and the merkle branch of the
+// transaction to the merkle root of it's block.
+func VerifyProofBundle(b *ProofBundle, checkpoint common.Uint256) error {
+	if len(b.Headers) == 0 {
+		return errors.New("no headers in proof bundle")
+	}
+	if b.Checkpoint() != checkpoint {
+		return fmt.Errorf("header chain starts from %s, expect checkpoint"+
+			" %s", b.Checkpoint(), checkpoint)
+	}
+	if b.Height < b.CheckpointHeight ||
+		b.Height-b.CheckpointHeight >= uint32(len(b.Headers)) {
+		return fmt.Errorf("block height %d not on the header chain",
+			b.Height)
+	}
+
+	for i, header := range b.Headers {
+		if i > 0 && header.Previous() != b.Headers[i-1].Hash() {
+			return fmt.Errorf("header %s not connect to previous header",
+				header.Hash())
+		}
+		if i > 0 && !blockchain.CheckProofOfWork(header) {
+			return fmt.Errorf("header %s proof of work check failed",
+				header.Hash())
+		}
+		if i > 0 && !blockchain.CheckDifficultyTransition(
+			b.CheckpointHeight+uint32(i), b.Headers[i-1].Bits(),
+			header.Bits()) {
+			return fmt.Errorf("header %s difficulty bits %08x not allowed"+
+				" after %08x", header.Hash(), header.Bits(),
+				b.Headers[i-1].Bits())
+		}
+	}
+
+	header := b.Headers[b.Height-b.CheckpointHeight]
+	root := auxpow.GetMerkleRoot(b.Tx.Hash(), b.Branch.Branches,
+		b.Branch.Index)
+	if root != header.MerkleRoot() {
+		return fmt.Errorf("transaction merkle root %s not match block"+
+			" merkle root %s", root, header.MerkleRoot())
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package bloom
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+// Ensure powHeader implement BlockHeader interface.
+var _ util.BlockHeader = (*powHeader)(nil)
+
+// powHeader is a header always passes the proof of work check, it claims
+// 0x1d00ffff difficulty bits if bits is not set.
+type powHeader struct {
+	previous common.Uint256
+	root     common.Uint256
+	bits     uint32
+}
+
+func (h *powHeader) Previous() common.Uint256 {
+	return h.previous
+}
+
+func (h *powHeader) Bits() uint32 {
+	if h.bits == 0 {
+		return 0x1d00ffff
+	}
+	return h.bits
+}
+
+func (h *powHeader) MerkleRoot() common.Uint256 {
+	return h.root
+}
+
+func (h *powHeader) Hash() common.Uint256 {
+	buf := new(bytes.Buffer)
+	h.Serialize(buf)
+	return common.Uint256(common.Sha256D(buf.Bytes()))
+}
+
+func (h *powHeader) PowHash() common.Uint256 {
+	return common.EmptyHash
+}
+
+func (h *powHeader) Serialize(w io.Writer) error {
+	return common.WriteElements(w, &h.previous, &h.root)
+}
+
+func (h *powHeader) Deserialize(r io.Reader) error {
+	return common.ReadElements(r, &h.previous, &h.root)
+}
+
+func newTestProofBundle() *ProofBundle {
+	return NewProofBundle(func() util.BlockHeader {
+		return &powHeader{}
+	}, func() util.Transaction {
+		return iutil.NewTx(&types.Transaction{})
+	})
+}
+
+func TestProofBundle(t *testing.T) {
+	txs := make([]util.Transaction, 0, 3)
+	hashes := make([]*common.Uint256, 0, 3)
+	for i := 0; i < 3; i++ {
+		var addr common.Uint168
+		rand.Read(addr[:])
+		tx := iutil.NewTx(&types.Transaction{
+			TxType:  types.TransferAsset,
+			Payload: &payload.TransferAsset{},
+			Outputs: []*types.Output{{ProgramHash: addr}},
+		})
+		hash := tx.Hash()
+		txs = append(txs, tx)
+		hashes = append(hashes, &hash)
+	}
+	mb := newTestMerkleBlock(hashes, map[uint32]bool{1: true})
+
+	// The checkpoint, the block of the transaction and a confirmation.
+	checkpoint := &powHeader{previous: *randHash(), root: *randHash()}
+	block := &powHeader{
+		previous: checkpoint.Hash(),
+		root:     mb.Header.(util.BlockHeader).MerkleRoot(),
+	}
+	next := &powHeader{previous: block.Hash(), root: *randHash()}
+	headers := []util.BlockHeader{checkpoint, block, next}
+
+	bundle, err := CreateProofBundle(10, 11, headers, txs[1], mb)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, uint32(2), bundle.Confirmations())
+	assert.NoError(t, VerifyProofBundle(bundle, checkpoint.Hash()))
+
+	// Binary encoding.
+	buf := new(bytes.Buffer)
+	if !assert.NoError(t, bundle.Serialize(buf)) {
+		t.FailNow()
+	}
+	decoded := newTestProofBundle()
+	if !assert.NoError(t, decoded.Deserialize(buf)) {
+		t.FailNow()
+	}
+	assert.Equal(t, bundle.Tx.Hash(), decoded.Tx.Hash())
+	assert.NoError(t, VerifyProofBundle(decoded, checkpoint.Hash()))
+
+	// JSON encoding.
+	data, err := json.Marshal(bundle)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	decoded = newTestProofBundle()
+	if !assert.NoError(t, json.Unmarshal(data, decoded)) {
+		t.FailNow()
+	}
+	assert.Equal(t, bundle.Branch, decoded.Branch)
+	assert.NoError(t, VerifyProofBundle(decoded, checkpoint.Hash()))
+
+	// Untrusted checkpoint.
+	assert.Error(t, VerifyProofBundle(bundle, block.Hash()))
+
+	// Transaction not in the block.
+	decoded.Tx = txs[0]
+	assert.Error(t, VerifyProofBundle(decoded, checkpoint.Hash()))
+
+	// Broken header chain.
+	bundle.Headers = []util.BlockHeader{checkpoint, next, block}
+	bundle.Height = 12
+	assert.Error(t, VerifyProofBundle(bundle, checkpoint.Hash()))
+}
+
+func TestVerifyProofBundleDifficulty(t *testing.T) {
+	tx := iutil.NewTx(&types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+	})
+	hash := tx.Hash()
+	mb := newTestMerkleBlock([]*common.Uint256{&hash},
+		map[uint32]bool{0: true})
+
+	// newBundle creates a proof bundle of the transaction in the block after
+	// the checkpoint on checkpointHeight, the block claims the given bits.
+	newBundle := func(checkpointHeight, bits uint32) (*ProofBundle,
+		common.Uint256) {
+		checkpoint := &powHeader{previous: *randHash(), root: *randHash()}
+		block := &powHeader{
+			previous: checkpoint.Hash(),
+			root:     mb.Header.(util.BlockHeader).MerkleRoot(),
+			bits:     bits,
+		}
+		bundle, err := CreateProofBundle(checkpointHeight,
+			checkpointHeight+1, []util.BlockHeader{checkpoint, block}, tx, mb)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		return bundle, checkpoint.Hash()
+	}
+
+	// A low difficulty chain is rejected even the proof of work of every
+	// header meets it's own target.
+	bundle, checkpoint := newBundle(10, 0x207fffff)
+	assert.Error(t, VerifyProofBundle(bundle, checkpoint))
+
+	// The difficulty can not be changed except on retarget heights.
+	bundle, checkpoint = newBundle(10, 0x1c00ffff)
+	assert.Error(t, VerifyProofBundle(bundle, checkpoint))
+	bundle, checkpoint = newBundle(10, 0x1d00ffff)
+	assert.NoError(t, VerifyProofBundle(bundle, checkpoint))
+
+	// A retarget can make the target at most four times easier.
+	bundle, checkpoint = newBundle(719, 0x1d03fffc)
+	assert.NoError(t, VerifyProofBundle(bundle, checkpoint))
+	bundle, checkpoint = newBundle(719, 0x1d0400ff)
+	assert.Error(t, VerifyProofBundle(bundle, checkpoint))
+	bundle, checkpoint = newBundle(719, 0x1c00ffff)
+	assert.NoError(t, VerifyProofBundle(bundle, checkpoint))
+}
@@ -1,14 +1,20 @@
 package database
 
 import (
+	"sync"
+
 	"github.com/elastos/Elastos.ELA.SPV/util"
 
 	"github.com/elastos/Elastos.ELA/common"
 )
 
 type chainDB struct {
-	h Headers
-	t TxsDB
+	h     Headers
+	t     TxsDB
+	j     Journal
+	opts  *ChainOptions
+	mtx   sync.Mutex
+	reorg *util.Reorg
 }
 
 // Headers returns the headers database that stored
@@ -20,50 +26,199 @@ func (d *chainDB) Headers() Headers {
 // CommitBlock save a block into database, returns how many
 // false positive transactions are and error.
 func (d *chainDB) CommitBlock(block *util.Block, newTip bool) (fps uint32, err error) {
-	err = d.h.Put(&block.Header, newTip)
+	hash := block.Hash()
+
+	// Only blocks with transactions need the merkle proof.
+	header := &block.Header
+	if d.opts.PruneProofs && len(block.Transactions) == 0 {
+		slim := block.Header
+		slim.Hashes, slim.Flags = nil, nil
+		header = &slim
+	}
+
+	// Fork block transactions are saved before the header, so an interrupted
+	// commit leaves the block unknown and it will be requested again.
+	if !newTip {
+		err = d.t.PutForkTxs(block.Transactions, &hash)
+		if err != nil {
+			return 0, err
+		}
+		return 0, d.h.Put(header, false)
+	}
+
+	// Record the commit, so the transactions can be rolled back if the
+	// header has not been saved when interrupted.
+	err = d.j.Put(&JournalEntry{
+		Op:     OpCommitBlock,
+		Height: block.Height,
+		Hashes: []common.Uint256{hash},
+	})
 	if err != nil {
 		return 0, err
 	}
 
-	if newTip {
-		return d.t.PutTxs(block.Transactions, block.Height)
+	fps, err = d.t.PutTxs(block.Transactions, block.Height)
+	if err != nil {
+		return 0, err
 	}
 
-	hash := block.Hash()
-	return 0, d.t.PutForkTxs(block.Transactions, &hash)
+	err = d.h.Put(header, true)
+	if err != nil {
+		return 0, err
+	}
+
+	// The headers database syncs the new tip before the journal is removed.
+	// Transactions are not synced to keep syncing fast, they are kept by the
+	// OS if only the process crashed.
+	if err := d.j.Del(); err != nil {
+		return 0, err
+	}
+
+	if err := d.updateHeaderFile(header); err != nil {
+		return 0, err
+	}
+	return fps, d.pruneHeaders(header)
 }
 
-// ProcessReorganize switch chain data to the new best chain.
-func (d *chainDB) ProcessReorganize(commonAncestor, prevTip, newTip *util.Header) error {
-	// 1. Move previous main chain data to fork.
-	root := commonAncestor.Hash()
-	header := prevTip
-	hash := header.Hash()
-	for !hash.IsEqual(root) {
-		// Move transactions to fork.
-		txs, err := d.t.GetTxs(header.Height)
-		if err != nil {
+// ProcessReorganize switch chain data to the new best chain, returns the
+// detached and attached blocks of the reorganization.
+func (d *chainDB) ProcessReorganize(commonAncestor, prevTip, newTip *util.Header) (*util.Reorg, error) {
+	// Record the reorganize, so it can be continued if interrupted.
+	entry := &JournalEntry{
+		Op:    OpReorganize,
+		Stage: StageDetach,
+		Hashes: []common.Uint256{commonAncestor.Hash(), prevTip.Hash(),
+			newTip.Hash()},
+	}
+	if err := d.j.Put(entry); err != nil {
+		return nil, err
+	}
+
+	reorg, err := d.processReorganize(entry, commonAncestor, prevTip, newTip)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.j.Del(); err != nil {
+		return nil, err
+	}
+
+	return reorg, d.updateHeaderFile(newTip)
+}
+
+// updateHeaderFile writes the main chain headers ending with tip into the
+// header file, back to the height which record matches the main chain, and
+// removes the records above tip.
+func (d *chainDB) updateHeaderFile(tip *util.Header) error {
+	f := d.opts.HeaderFile
+	if f == nil {
+		return nil
+	}
+
+	if err := f.Truncate(tip.Height + 1); err != nil {
+		return err
+	}
+
+	header := tip
+	for {
+		hash := header.Hash()
+		record, err := f.Get(header.Height)
+		if err == nil && record.Hash.IsEqual(hash) {
+			return nil
+		}
+
+		if err := f.Put(NewCompactHeader(header)); err != nil {
 			return err
 		}
-		err = d.t.PutForkTxs(txs, &hash)
+
+		if header.Height == 0 {
+			return nil
+		}
+		header, err = d.h.GetPrevious(header)
 		if err != nil {
-			return err
+			// Stop at pruned headers.
+			return nil
+		}
+	}
+}
+
+// pruneHeaders deletes the main chain headers older than KeepHeaders from
+// tip, except genesis, checkpoints and blocks with transactions.  It stops at
+// the first height already pruned.
+func (d *chainDB) pruneHeaders(tip *util.Header) error {
+	keep := d.opts.KeepHeaders
+	if keep == 0 || tip.Height <= keep {
+		return nil
+	}
+
+	interval := d.opts.CheckpointInterval
+	for height := tip.Height - keep; height > 0; height-- {
+		header, err := d.h.GetByHeight(height)
+		if err != nil {
+			return nil
+		}
+
+		if interval > 0 && height%interval == 0 {
+			continue
 		}
 
-		// Delete transactions from main chain.
-		err = d.t.DelTxs(header.Height)
+		txs, err := d.t.GetTxs(height)
 		if err != nil {
 			return err
 		}
+		if len(txs) > 0 {
+			continue
+		}
+
+		hash := header.Hash()
+		if err := d.h.Del(&hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processReorganize moves chain data by the stage of the journal entry.  Every
+// step can be applied repeatedly, so an interrupted reorganize can be
+// continued by running it again.
+func (d *chainDB) processReorganize(entry *JournalEntry, commonAncestor,
+	prevTip, newTip *util.Header) (*util.Reorg, error) {
+
+	reorg := &util.Reorg{CommonAncestor: commonAncestor}
+
+	// 1. Move previous main chain data to fork.  If it has been moved by an
+	// interrupted reorganize, the detached blocks are collected from fork.
+	root := commonAncestor.Hash()
+	header := prevTip
+	hash := header.Hash()
+	for !hash.IsEqual(root) {
+		var txs []util.Transaction
+		var err error
+		if entry.Stage == StageDetach {
+			txs, err = d.detach(header)
+		} else {
+			txs, err = d.t.GetForkTxs(&hash)
+		}
+		if err != nil {
+			return nil, err
+		}
+		reorg.Detached = append(reorg.Detached, util.NewReorgBlock(header, txs))
 
 		// Move to previous header.
 		header, err = d.h.GetPrevious(header)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		hash = header.Hash()
 	}
 
+	if entry.Stage == StageDetach {
+		entry.Stage = StageAttach
+		if err := d.j.Put(entry); err != nil {
+			return nil, err
+		}
+	}
+
 	// 2. Move new best chain data from fork to main chain.
 	// subChain stores all fork chain headers by their previous hash, so we can
 	// index next header by previous header hash.
@@ -78,34 +233,127 @@ func (d *chainDB) ProcessReorganize(commonAncestor, prevTip, newTip *util.Header
 		var err error
 		header, err = d.h.GetPrevious(header)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		hash = header.Hash()
 	}
 
 	// Connect sub chain to main chain from fork root.  It is important to put
-	// transactions by order, so we can process UTXOs STXOs correctly.
+	// transactions by order, so we can process UTXOs STXOs correctly.  The new
+	// tip is stored as a fork block by CommitBlock, so it is moved too.
 	tip := newTip.Hash()
-	header = subChain[root]
-	newRoot := header.Hash()
-	for !newRoot.IsEqual(tip) {
+	for header = subChain[root]; ; header = subChain[hash] {
 		// Put transactions to main chain.
-		txs, err := d.t.GetForkTxs(&newRoot)
+		hash = header.Hash()
+		txs, err := d.t.GetForkTxs(&hash)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		_, err = d.t.PutTxs(txs, header.Height)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		reorg.Attached = append(reorg.Attached, util.NewReorgBlock(header, txs))
 
-		// Move to next header.
-		header = subChain[newRoot]
-		newRoot = header.Hash()
+		if hash.IsEqual(tip) {
+			break
+		}
 	}
 
 	// Set new chain tip.
-	return d.h.Put(newTip, true)
+	return reorg, d.h.Put(newTip, true)
+}
+
+// detach moves the transactions of the main chain block to fork, returns the
+// transactions moved.
+func (d *chainDB) detach(header *util.Header) ([]util.Transaction, error) {
+	hash := header.Hash()
+	txs, err := d.t.GetTxs(header.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	// Transactions have been moved to fork by an interrupted reorganize, do
+	// not overwrite them.
+	moved := false
+	if len(txs) == 0 {
+		ftxs, err := d.t.GetForkTxs(&hash)
+		if err == nil {
+			txs, moved = ftxs, true
+		}
+	}
+
+	// Move transactions to fork.
+	if !moved {
+		if err := d.t.PutForkTxs(txs, &hash); err != nil {
+			return nil, err
+		}
+	}
+
+	// Delete transactions from main chain.
+	return txs, d.t.DelTxs(header.Height)
+}
+
+// recover completes or rolls back the operation recorded in journal, which
+// was interrupted by a crash or power failure.
+func (d *chainDB) recover() error {
+	entry, err := d.j.Get()
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return nil
+	}
+
+	switch entry.Op {
+	case OpCommitBlock:
+		// The header is saved at last, if it has not become the chain tip,
+		// rollback the transactions saved on it's height.
+		best, err := d.h.GetBest()
+		if err != nil {
+			return err
+		}
+		hash := best.Hash()
+		if !hash.IsEqual(entry.Hashes[0]) {
+			if err := d.t.DelTxs(entry.Height); err != nil {
+				return err
+			}
+		}
+
+	case OpReorganize:
+		headers := make([]*util.Header, 0, len(entry.Hashes))
+		for i := range entry.Hashes {
+			header, err := d.h.Get(&entry.Hashes[i])
+			if err != nil {
+				return err
+			}
+			headers = append(headers, header)
+		}
+		reorg, err := d.processReorganize(entry, headers[0], headers[1],
+			headers[2])
+		if err != nil {
+			return err
+		}
+
+		// Keep the reorganize to be notified to the listeners, they have
+		// not seen it before the crash.
+		d.mtx.Lock()
+		d.reorg = reorg
+		d.mtx.Unlock()
+	}
+
+	return d.j.Del()
+}
+
+// RecoveredReorg returns the chain reorganize interrupted last time and
+// completed when the database was opened, or nil if there is none.
+func (d *chainDB) RecoveredReorg() *util.Reorg {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	reorg := d.reorg
+	d.reorg = nil
+	return reorg
 }
 
 // Clear delete all data in database.
@@ -116,7 +364,12 @@ func (d *chainDB) Clear() error {
 	if err := d.t.Clear(); err != nil {
 		return err
 	}
-	return nil
+	if d.opts.HeaderFile != nil {
+		if err := d.opts.HeaderFile.Clear(); err != nil {
+			return err
+		}
+	}
+	return d.j.Clear()
 }
 
 // Close database.
@@ -127,5 +380,10 @@ func (d *chainDB) Close() error {
 	if err := d.t.Close(); err != nil {
 		return err
 	}
-	return nil
+	if d.opts.HeaderFile != nil {
+		if err := d.opts.HeaderFile.Close(); err != nil {
+			return err
+		}
+	}
+	return d.j.Close()
 }
@@ -16,10 +16,68 @@ type ChainStore interface {
 	// false positive transactions are and error.
 	CommitBlock(block *util.Block, newTip bool) (fps uint32, err error)
 
-	// ProcessReorganize switch chain data to the new best chain.
-	ProcessReorganize(commonAncestor, prevTip, newTip *util.Header) error
+	// ProcessReorganize switch chain data to the new best chain, returns the
+	// detached and attached blocks of the reorganization.
+	ProcessReorganize(commonAncestor, prevTip, newTip *util.Header) (*util.Reorg, error)
+
+	// RecoveredReorg returns the chain reorganize interrupted last time and
+	// completed when the database was opened, or nil if there is none.  It
+	// is returned only once, so it is notified to the listeners only once.
+	RecoveredReorg() *util.Reorg
+}
+
+// ChainOptions configures how chain data is stored, the zero value stores
+// all data.
+type ChainOptions struct {
+	// PruneProofs drops the merkle proof of blocks without transactions,
+	// proofs are only needed to verify the stored transactions.
+	PruneProofs bool
+
+	// KeepHeaders is the number of recent main chain headers to keep, older
+	// headers are deleted except checkpoints and blocks with transactions.
+	// Zero keeps all headers.
+	KeepHeaders uint32
+
+	// CheckpointInterval keeps one of every CheckpointInterval old headers
+	// when pruning headers, zero keeps no checkpoints.
+	CheckpointInterval uint32
+
+	// HeaderFile stores compact records of all main chain headers, which are
+	// kept when headers are pruned.  Nil disables the header file.
+	HeaderFile *HeaderFile
+}
+
+// NewChainDB creates a ChainStore by the given headers and transactions
+// database, the journal is used to make block commit and chain reorganize
+// crash-safe, an operation interrupted last time will be recovered here.
+// The options can be nil to store all data.
+func NewChainDB(h Headers, t TxsDB, j Journal, opts *ChainOptions) (ChainStore, error) {
+	if opts == nil {
+		opts = new(ChainOptions)
+	}
+	db := &chainDB{h: h, t: t, j: j, opts: opts}
+	if err := db.recover(); err != nil {
+		return nil, err
+	}
+
+	// Catch up the header file and pruning after recover.
+	best, err := h.GetBest()
+	if err != nil {
+		// Nothing to do on a new database.
+		return db, nil
+	}
+	if err := db.updateHeaderFile(best); err != nil {
+		return nil, err
+	}
+	if err := db.pruneHeaders(best); err != nil {
+		return nil, err
+	}
+	return db, nil
 }
 
-func NewChainDB(h Headers, t TxsDB) ChainStore {
-	return &chainDB{h: h, t: t}
+// NewMemChainDB creates a ChainStore that keeps all headers and transactions
+// in memory, it is useful for ephemeral services, tests and benchmarking.
+func NewMemChainDB() ChainStore {
+	return &chainDB{h: NewMemHeaders(), t: NewMemTxsDB(), j: NewMemJournal(),
+		opts: new(ChainOptions)}
 }
@@ -0,0 +1,32 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/database/dbtest"
+)
+
+func TestMemHeadersConformance(t *testing.T) {
+	dbtest.TestHeaders(t, func(t *testing.T) (database.Headers, error) {
+		return database.NewMemHeaders(), nil
+	})
+}
+
+func TestChainDBConformance(t *testing.T) {
+	t.Run("Mem", func(t *testing.T) {
+		dbtest.TestChainStore(t, func(t *testing.T) (database.ChainStore, error) {
+			return database.NewMemChainDB(), nil
+		})
+	})
+	t.Run("Journal", func(t *testing.T) {
+		dbtest.TestChainStore(t, func(t *testing.T) (database.ChainStore, error) {
+			j, err := database.NewJournal(t.TempDir())
+			if err != nil {
+				return nil, err
+			}
+			return database.NewChainDB(database.NewMemHeaders(),
+				database.NewMemTxsDB(), j, nil)
+		})
+	})
+}
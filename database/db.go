@@ -1,5 +1,10 @@
 package database
 
+import "errors"
+
+// ErrNotFound is returned when the requested data does not exist in database.
+var ErrNotFound = errors.New("not found")
+
 // DB is the common interface to all database implementations.
 type DB interface {
 	// Clear delete all data in database.
@@ -0,0 +1,359 @@
+// Package dbtest provides the conformance tests of database.Headers and
+// database.ChainStore, every implementation is expected to pass them.
+package dbtest
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// tx is a transaction only has a hash.
+type tx struct {
+	hash common.Uint256
+}
+
+func (tx *tx) Hash() common.Uint256                { return tx.hash }
+func (tx *tx) Serialize(w io.Writer) error         { return tx.hash.Serialize(w) }
+func (tx *tx) Deserialize(r io.Reader) error       { return tx.hash.Deserialize(r) }
+func (tx *tx) MatchFilter(filter util.Filter) bool { return true }
+
+// newHeader creates the header after prev, or a genesis header if prev is
+// nil, the nonce makes headers on the same height different.
+func newHeader(prev *util.Header, nonce uint32) *util.Header {
+	header := &types.Header{Nonce: nonce}
+	height := uint32(0)
+	work := big.NewInt(1)
+	if prev != nil {
+		header.Previous = prev.Hash()
+		height = prev.Height + 1
+		work.Add(work, prev.TotalWork)
+	}
+	header.Height = height
+	return &util.Header{
+		BlockHeader: iutil.NewHeader(header),
+		Height:      height,
+		TotalWork:   work,
+	}
+}
+
+// newChain creates count headers after prev, or from genesis if prev is nil.
+func newChain(prev *util.Header, count int, nonce uint32) []*util.Header {
+	headers := make([]*util.Header, 0, count)
+	for i := 0; i < count; i++ {
+		header := newHeader(prev, nonce)
+		headers = append(headers, header)
+		prev = header
+	}
+	return headers
+}
+
+// newBlock creates a block of the header with a random transaction.
+func newBlock(header *util.Header) *util.Block {
+	tx := new(tx)
+	rand.Read(tx.hash[:])
+	return &util.Block{Header: *header,
+		Transactions: []util.Transaction{tx}}
+}
+
+// assertHeader asserts the header stored is the expected header.
+func assertHeader(t *testing.T, expect, header *util.Header) {
+	assert.Equal(t, expect.Hash(), header.Hash())
+	assert.Equal(t, expect.Height, header.Height)
+	assert.Equal(t, 0, expect.TotalWork.Cmp(header.TotalWork))
+}
+
+// assertMainChain asserts the height index of headers is the chain.
+func assertMainChain(t *testing.T, db database.Headers, chain []*util.Header) {
+	headers, err := db.GetRange(chain[0].Height,
+		chain[len(chain)-1].Height)
+	if !assert.NoError(t, err) || !assert.Len(t, headers, len(chain)) {
+		t.FailNow()
+	}
+	for i, header := range headers {
+		assertHeader(t, chain[i], header)
+	}
+}
+
+// putChain puts the headers to db, the last header is the new tip if newTip
+// is true.
+func putChain(t *testing.T, db database.Headers, chain []*util.Header,
+	newTip bool) {
+	for _, header := range chain {
+		if !assert.NoError(t, db.Put(header, newTip)) {
+			t.FailNow()
+		}
+	}
+}
+
+var headersTests = []struct {
+	name string
+	test func(t *testing.T, db database.Headers)
+}{
+	{"Empty", func(t *testing.T, db database.Headers) {
+		_, err := db.GetBest()
+		assert.Error(t, err)
+		_, err = db.GetByHeight(0)
+		assert.Error(t, err)
+		_, err = db.Get(&common.Uint256{})
+		assert.Error(t, err)
+		assert.Error(t, db.Iterate(0, func(*util.Header) bool {
+			return true
+		}))
+	}},
+	{"PutAndGet", func(t *testing.T, db database.Headers) {
+		main := newChain(nil, 5, 0)
+		putChain(t, db, main, true)
+
+		best, err := db.GetBest()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assertHeader(t, main[4], best)
+		for i, expect := range main {
+			hash := expect.Hash()
+			header, err := db.Get(&hash)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assertHeader(t, expect, header)
+
+			header, err = db.GetByHeight(uint32(i))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assertHeader(t, expect, header)
+
+			if i > 0 {
+				header, err = db.GetPrevious(expect)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				assertHeader(t, main[i-1], header)
+			}
+		}
+
+		headers, err := db.GetRange(1, 3)
+		if !assert.NoError(t, err) || !assert.Len(t, headers, 3) {
+			t.FailNow()
+		}
+		for i, header := range headers {
+			assertHeader(t, main[i+1], header)
+		}
+		headers, err = db.GetRange(3, 1)
+		assert.NoError(t, err)
+		assert.Empty(t, headers)
+		_, err = db.GetRange(3, 5)
+		assert.Error(t, err)
+	}},
+	{"Fork", func(t *testing.T, db database.Headers) {
+		// Main chain 0 <- 1 <- 2 <- 3 <- 4.
+		main := newChain(nil, 5, 0)
+		putChain(t, db, main, true)
+
+		// Fork headers 1 <- 2' <- 3' do not change the height index.
+		fork := newChain(main[1], 2, 1)
+		putChain(t, db, fork, false)
+		assertMainChain(t, db, main)
+
+		// The fork becomes the main chain 1 <- 2' <- 3' <- 4' <- 5'.
+		fork = append(fork, newChain(fork[1], 3, 1)...)
+		putChain(t, db, fork[2:], true)
+		assertMainChain(t, db, append(main[:2:2], fork...))
+
+		// Switch back to the shorter main chain removes height 5.
+		if !assert.NoError(t, db.Put(main[4], true)) {
+			t.FailNow()
+		}
+		assertMainChain(t, db, main)
+		_, err := db.GetByHeight(5)
+		assert.Error(t, err)
+	}},
+	{"Iterate", func(t *testing.T, db database.Headers) {
+		main := newChain(nil, 5, 0)
+		putChain(t, db, main, true)
+
+		var heights []uint32
+		err := db.Iterate(2, func(header *util.Header) bool {
+			heights = append(heights, header.Height)
+			return true
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint32{2, 3, 4}, heights)
+
+		heights = nil
+		err = db.Iterate(0, func(header *util.Header) bool {
+			heights = append(heights, header.Height)
+			return header.Height < 1
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint32{0, 1}, heights)
+	}},
+	{"Del", func(t *testing.T, db database.Headers) {
+		main := newChain(nil, 5, 0)
+		putChain(t, db, main, true)
+
+		hash := main[2].Hash()
+		if !assert.NoError(t, db.Del(&hash)) {
+			t.FailNow()
+		}
+		_, err := db.Get(&hash)
+		assert.Error(t, err)
+		_, err = db.GetByHeight(2)
+		assert.Error(t, err)
+		header, err := db.GetByHeight(3)
+		if assert.NoError(t, err) {
+			assertHeader(t, main[3], header)
+		}
+	}},
+	{"Clear", func(t *testing.T, db database.Headers) {
+		main := newChain(nil, 5, 0)
+		putChain(t, db, main, true)
+
+		if !assert.NoError(t, db.Clear()) {
+			t.FailNow()
+		}
+		_, err := db.GetBest()
+		assert.Error(t, err)
+		hash := main[4].Hash()
+		_, err = db.Get(&hash)
+		assert.Error(t, err)
+		_, err = db.GetByHeight(0)
+		assert.Error(t, err)
+
+		// The database can be used again after cleared.
+		putChain(t, db, main[:2], true)
+		assertMainChain(t, db, main[:2])
+	}},
+}
+
+// TestHeaders runs the conformance tests of database.Headers, newHeaders is
+// called to create an empty database for every test.
+func TestHeaders(t *testing.T,
+	newHeaders func(t *testing.T) (database.Headers, error)) {
+	for _, test := range headersTests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := newHeaders(t)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			defer db.Close()
+			test.test(t, db)
+		})
+	}
+}
+
+// commitChain commits the blocks of headers to db.
+func commitChain(t *testing.T, db database.ChainStore, chain []*util.Header,
+	newTip bool) []*util.Block {
+	blocks := make([]*util.Block, 0, len(chain))
+	for _, header := range chain {
+		block := newBlock(header)
+		if _, err := db.CommitBlock(block, newTip); !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+var chainStoreTests = []struct {
+	name string
+	test func(t *testing.T, db database.ChainStore)
+}{
+	{"CommitBlock", func(t *testing.T, db database.ChainStore) {
+		// Nothing is recovered by a new database.
+		assert.Nil(t, db.RecoveredReorg())
+
+		main := newChain(nil, 4, 0)
+		commitChain(t, db, main, true)
+
+		best, err := db.Headers().GetBest()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assertHeader(t, main[3], best)
+		assertMainChain(t, db.Headers(), main)
+
+		// Blocks not on the new tip are stored without changing the chain.
+		fork := newChain(main[1], 2, 1)
+		commitChain(t, db, fork, false)
+		best, err = db.Headers().GetBest()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assertHeader(t, main[3], best)
+		hash := fork[1].Hash()
+		header, err := db.Headers().Get(&hash)
+		if assert.NoError(t, err) {
+			assertHeader(t, fork[1], header)
+		}
+	}},
+	{"ProcessReorganize", func(t *testing.T, db database.ChainStore) {
+		// Main chain 0 <- 1 <- 2 <- 3, fork chain 1 <- 2' <- 3' <- 4'.
+		main := newChain(nil, 4, 0)
+		commitChain(t, db, main, true)
+		fork := newChain(main[1], 3, 1)
+		commitChain(t, db, fork, false)
+
+		reorg, err := db.ProcessReorganize(main[1], main[3], fork[2])
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		if !assert.Len(t, reorg.Detached, 2) ||
+			!assert.Len(t, reorg.Attached, 3) {
+			t.FailNow()
+		}
+		assert.Equal(t, main[3].Hash(), reorg.Detached[0].Hash())
+		assert.Equal(t, main[2].Hash(), reorg.Detached[1].Hash())
+		for i, block := range reorg.Attached {
+			assert.Equal(t, fork[i].Hash(), block.Hash())
+		}
+
+		best, err := db.Headers().GetBest()
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assertHeader(t, fork[2], best)
+		assertMainChain(t, db.Headers(), append(main[:2:2], fork...))
+	}},
+	{"Clear", func(t *testing.T, db database.ChainStore) {
+		main := newChain(nil, 4, 0)
+		commitChain(t, db, main, true)
+
+		if !assert.NoError(t, db.Clear()) {
+			t.FailNow()
+		}
+		_, err := db.Headers().GetBest()
+		assert.Error(t, err)
+
+		// The database can be used again after cleared.
+		commitChain(t, db, main[:2], true)
+		assertMainChain(t, db.Headers(), main[:2])
+	}},
+}
+
+// TestChainStore runs the conformance tests of database.ChainStore, newStore
+// is called to create an empty database for every test.
+func TestChainStore(t *testing.T,
+	newStore func(t *testing.T) (database.ChainStore, error)) {
+	for _, test := range chainStoreTests {
+		t.Run(test.name, func(t *testing.T) {
+			db, err := newStore(t)
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			defer db.Close()
+			test.test(t, db)
+		})
+	}
+}
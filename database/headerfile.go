@@ -0,0 +1,213 @@
+package database
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+const (
+	// CompactHeaderSize is the size of a compact header record.
+	CompactHeaderSize = 32*3 + 4*3 + 32
+
+	// headerFileMagic is written at the beginning of a header file.
+	headerFileMagic = "SPVHDRS\x01"
+)
+
+// CompactHeader is the fixed-size record of a main chain header stored in
+// HeaderFile, it keeps the fields needed to verify chain work and merkle
+// roots without the proof and the origin block header.
+type CompactHeader struct {
+	Hash       common.Uint256
+	Previous   common.Uint256
+	MerkleRoot common.Uint256
+	Bits       uint32
+	Height     uint32
+	NumTxs     uint32
+	TotalWork  *big.Int
+}
+
+// NewCompactHeader creates the compact record of the given header.
+func NewCompactHeader(header *util.Header) *CompactHeader {
+	return &CompactHeader{
+		Hash:       header.Hash(),
+		Previous:   header.Previous(),
+		MerkleRoot: header.MerkleRoot(),
+		Bits:       header.Bits(),
+		Height:     header.Height,
+		NumTxs:     header.NumTxs,
+		TotalWork:  header.TotalWork,
+	}
+}
+
+func (h *CompactHeader) bytes() []byte {
+	buf := make([]byte, CompactHeaderSize)
+	copy(buf[0:], h.Hash[:])
+	copy(buf[32:], h.Previous[:])
+	copy(buf[64:], h.MerkleRoot[:])
+	binary.BigEndian.PutUint32(buf[96:], h.Bits)
+	binary.BigEndian.PutUint32(buf[100:], h.Height)
+	binary.BigEndian.PutUint32(buf[104:], h.NumTxs)
+	if h.TotalWork != nil {
+		work := h.TotalWork.Bytes()
+		if len(work) > 32 {
+			work = work[len(work)-32:]
+		}
+		copy(buf[CompactHeaderSize-len(work):], work)
+	}
+	return buf
+}
+
+func (h *CompactHeader) setBytes(buf []byte) {
+	copy(h.Hash[:], buf[0:32])
+	copy(h.Previous[:], buf[32:64])
+	copy(h.MerkleRoot[:], buf[64:96])
+	h.Bits = binary.BigEndian.Uint32(buf[96:])
+	h.Height = binary.BigEndian.Uint32(buf[100:])
+	h.NumTxs = binary.BigEndian.Uint32(buf[104:])
+	h.TotalWork = new(big.Int).SetBytes(buf[108:CompactHeaderSize])
+}
+
+// Ensure HeaderFile implement DB interface.
+var _ DB = (*HeaderFile)(nil)
+
+// HeaderFile stores main chain headers as CompactHeader records in a file,
+// the record of height n is at a fixed offset, so headers can be located by
+// height and read sequentially fast.  It is derived data of the headers
+// database and not synced to disk on every write.
+type HeaderFile struct {
+	sync.RWMutex
+	file *os.File
+}
+
+// OpenHeaderFile opens or creates a header file on path.
+func OpenHeaderFile(path string) (*HeaderFile, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	magic := make([]byte, len(headerFileMagic))
+	_, err = file.ReadAt(magic, 0)
+	switch {
+	case err == io.EOF:
+		_, err = file.WriteAt([]byte(headerFileMagic), 0)
+	case err == nil && !bytes.Equal(magic, []byte(headerFileMagic)):
+		err = errors.New("invalid header file " + path)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &HeaderFile{file: file}, nil
+}
+
+func offset(height uint32) int64 {
+	return int64(len(headerFileMagic)) + int64(height)*CompactHeaderSize
+}
+
+// Count returns the number of records in file, which is the chain tip
+// height plus one.
+func (f *HeaderFile) Count() (uint32, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	info, err := f.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return uint32((info.Size() - offset(0)) / CompactHeaderSize), nil
+}
+
+// Put writes the header record on it's height.
+func (f *HeaderFile) Put(header *CompactHeader) error {
+	f.Lock()
+	defer f.Unlock()
+
+	_, err := f.file.WriteAt(header.bytes(), offset(header.Height))
+	return err
+}
+
+// Get returns the header record on the given height, or ErrNotFound if there
+// is no record.
+func (f *HeaderFile) Get(height uint32) (*CompactHeader, error) {
+	f.RLock()
+	defer f.RUnlock()
+
+	buf := make([]byte, CompactHeaderSize)
+	_, err := f.file.ReadAt(buf, offset(height))
+	if err == io.EOF {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var header CompactHeader
+	header.setBytes(buf)
+	if header.Hash == (common.Uint256{}) {
+		return nil, ErrNotFound
+	}
+	return &header, nil
+}
+
+// Iterate calls fn with the header records from height from in ascending
+// height order, until the end of file or fn returns false.
+func (f *HeaderFile) Iterate(from uint32, fn func(header *CompactHeader) bool) error {
+	f.RLock()
+	defer f.RUnlock()
+
+	r := bufio.NewReaderSize(io.NewSectionReader(f.file, offset(from),
+		1<<62), 256*CompactHeaderSize)
+	buf := make([]byte, CompactHeaderSize)
+	for {
+		_, err := io.ReadFull(r, buf)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var header CompactHeader
+		header.setBytes(buf)
+		// Skip the gap not written.
+		if header.Hash == (common.Uint256{}) {
+			continue
+		}
+		if !fn(&header) {
+			return nil
+		}
+	}
+}
+
+// Truncate removes the records from the given height.
+func (f *HeaderFile) Truncate(height uint32) error {
+	f.Lock()
+	defer f.Unlock()
+
+	return f.file.Truncate(offset(height))
+}
+
+// Clear delete all data in database.
+func (f *HeaderFile) Clear() error {
+	return f.Truncate(0)
+}
+
+// Close database.
+func (f *HeaderFile) Close() error {
+	f.Lock()
+	defer f.Unlock()
+
+	return f.file.Close()
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"os"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainOptions(t *testing.T) {
+	f, err := OpenHeaderFile("test.dat")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.Remove("test.dat")
+
+	db, err := NewChainDB(NewMemHeaders(), NewMemTxsDB(), NewMemJournal(),
+		&ChainOptions{
+			PruneProofs:        true,
+			KeepHeaders:        5,
+			CheckpointInterval: 4,
+			HeaderFile:         f,
+		})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+
+	blocks := []*util.Block{newTestBlock(nil, 0)}
+	for i := 1; i < 20; i++ {
+		blocks = append(blocks, newTestBlock(&blocks[i-1].Header, 0))
+	}
+	for i, block := range blocks {
+		block.NumTxs = 1
+		block.Hashes = []*common.Uint256{new(common.Uint256)}
+		block.Flags = []byte{1}
+		// Only blocks on odd heights have transactions.
+		if i%2 == 0 {
+			block.Transactions = nil
+		}
+		_, err := db.CommitBlock(block, true)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+
+	for i, block := range blocks {
+		header, err := db.Headers().GetByHeight(uint32(i))
+		switch {
+		case i%2 == 1:
+			// Blocks with transactions keep the proof.
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Equal(t, block.Flags, header.Flags)
+		case i == 0 || i%4 == 0 || i > 19-5:
+			// Genesis, checkpoints and recent headers without proof.
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			assert.Nil(t, header.Flags)
+		default:
+			assert.Error(t, err)
+		}
+	}
+
+	count, err := f.Count()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, uint32(len(blocks)), count)
+
+	var height uint32
+	err = f.Iterate(0, func(header *CompactHeader) bool {
+		assert.Equal(t, height, header.Height)
+		assert.Equal(t, blocks[height].Hash(), header.Hash)
+		assert.Equal(t, 0, blocks[height].TotalWork.Cmp(header.TotalWork))
+		height++
+		return true
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, uint32(len(blocks)), height)
+
+	if !assert.NoError(t, f.Truncate(10)) {
+		t.FailNow()
+	}
+	_, err = f.Get(10)
+	assert.Equal(t, ErrNotFound, err)
+	header, err := f.Get(9)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, blocks[9].Hash(), header.Hash)
+}
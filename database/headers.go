@@ -10,7 +10,8 @@ type Headers interface {
 	// Extend from DB interface
 	DB
 
-	// Save a header to database
+	// Save a header to database, the height index is updated to the main
+	// chain ending with the header if it is the new tip.
 	Put(header *util.Header, newTip bool) error
 
 	// Get previous block of the given header
@@ -21,4 +22,60 @@ type Headers interface {
 
 	// Get the header on chain tip
 	GetBest() (*util.Header, error)
+
+	// Del deletes the header with the given hash, it is used to prune old
+	// headers, the height index is kept.
+	Del(hash *common.Uint256) error
+
+	// GetByHeight returns the main chain header on the given height.
+	GetByHeight(height uint32) (*util.Header, error)
+
+	// GetRange returns the main chain headers from height from to height to,
+	// both inclusive, in ascending height order.
+	GetRange(from, to uint32) ([]*util.Header, error)
+
+	// Iterate calls fn with the main chain headers from height from to the
+	// chain tip in ascending height order, until fn returns false.
+	Iterate(from uint32, fn func(header *util.Header) bool) error
+}
+
+// GetHeadersRange implements Headers.GetRange by GetByHeight.
+func GetHeadersRange(db Headers, from, to uint32) ([]*util.Header, error) {
+	if to < from {
+		return nil, nil
+	}
+
+	headers := make([]*util.Header, 0, to-from+1)
+	for height := from; ; height++ {
+		header, err := db.GetByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		headers = append(headers, header)
+
+		if height == to {
+			break
+		}
+	}
+	return headers, nil
+}
+
+// IterateHeaders implements Headers.Iterate by GetBest and GetByHeight, no
+// lock is held when calling fn, so fn can access the database.
+func IterateHeaders(db Headers, from uint32, fn func(header *util.Header) bool) error {
+	best, err := db.GetBest()
+	if err != nil {
+		return err
+	}
+
+	for height := from; height <= best.Height; height++ {
+		header, err := db.GetByHeight(height)
+		if err != nil {
+			return err
+		}
+		if !fn(header) {
+			break
+		}
+	}
+	return nil
 }
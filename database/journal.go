@@ -0,0 +1,190 @@
+package database
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+const (
+	// journalFilename is the file name of the journal within data dir.
+	journalFilename = "journal"
+)
+
+// JournalOp indicates which operation a journal entry records.
+type JournalOp byte
+
+const (
+	// OpCommitBlock records a block commit on the main chain.
+	OpCommitBlock JournalOp = iota + 1
+
+	// OpReorganize records a chain reorganize.
+	OpReorganize
+)
+
+const (
+	// StageDetach indicates the reorganize is moving the previous main chain
+	// data to fork.
+	StageDetach uint8 = iota
+
+	// StageAttach indicates the reorganize is moving the new best chain data
+	// from fork to main chain.
+	StageAttach
+)
+
+// JournalEntry is the record of a block commit or chain reorganize operation
+// that is in progress.
+type JournalEntry struct {
+	// Op is the operation recorded by this entry.
+	Op JournalOp
+
+	// Stage is the progress of the operation.
+	Stage uint8
+
+	// Height is the block height of a block commit.
+	Height uint32
+
+	// Hashes of the headers related to the operation.  It is the committed
+	// block hash for a block commit, or the common ancestor, previous tip and
+	// new tip hashes for a chain reorganize.
+	Hashes []common.Uint256
+}
+
+func (e *JournalEntry) Serialize(w io.Writer) error {
+	err := common.WriteElements(w, e.Op, e.Stage, e.Height,
+		uint8(len(e.Hashes)))
+	if err != nil {
+		return err
+	}
+	for _, hash := range e.Hashes {
+		if err := hash.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *JournalEntry) Deserialize(r io.Reader) error {
+	var count uint8
+	err := common.ReadElements(r, &e.Op, &e.Stage, &e.Height, &count)
+	if err != nil {
+		return err
+	}
+	e.Hashes = make([]common.Uint256, count)
+	for i := range e.Hashes {
+		if err := e.Hashes[i].Deserialize(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Journal is a write-ahead journal records the block commit or chain
+// reorganize operation in progress, so an interrupted operation can be
+// recovered on next start.
+type Journal interface {
+	// Extend from DB interface
+	DB
+
+	// Put records the operation in progress.
+	Put(entry *JournalEntry) error
+
+	// Get returns the operation in progress, or nil if there is none.
+	Get() (*JournalEntry, error)
+
+	// Del removes the operation record after it has been fully applied.
+	Del() error
+}
+
+// Ensure journal implement Journal interface.
+var _ Journal = (*journal)(nil)
+
+type journal struct {
+	sync.Mutex
+	path string
+}
+
+// NewJournal creates a file based journal within the given data dir.
+func NewJournal(dataDir string) (Journal, error) {
+	if err := os.MkdirAll(dataDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &journal{path: filepath.Join(dataDir, journalFilename)}, nil
+}
+
+// Put records the operation in progress.  The entry is written to a
+// temporary file and then renamed, so the journal file is never torn.
+func (j *journal) Put(entry *JournalEntry) error {
+	j.Lock()
+	defer j.Unlock()
+
+	buf := new(bytes.Buffer)
+	if err := entry.Serialize(buf); err != nil {
+		return err
+	}
+
+	tmp := j.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, j.path)
+}
+
+// Get returns the operation in progress, or nil if there is none.
+func (j *journal) Get() (*JournalEntry, error) {
+	j.Lock()
+	defer j.Unlock()
+
+	data, err := ioutil.ReadFile(j.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entry JournalEntry
+	if err := entry.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Del removes the operation record after it has been fully applied.
+func (j *journal) Del() error {
+	j.Lock()
+	defer j.Unlock()
+
+	err := os.Remove(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Clear delete all data in database.
+func (j *journal) Clear() error {
+	return j.Del()
+}
+
+// Close database.
+func (j *journal) Close() error {
+	return nil
+}
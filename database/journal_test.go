@@ -0,0 +1,64 @@
+package database
+
+import (
+	"crypto/rand"
+	"os"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournal(t *testing.T) {
+	j, err := NewJournal("test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll("test")
+
+	entry, err := j.Get()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Nil(t, entry) {
+		t.FailNow()
+	}
+
+	hashes := make([]common.Uint256, 3)
+	for i := range hashes {
+		rand.Read(hashes[i][:])
+	}
+	err = j.Put(&JournalEntry{Op: OpReorganize, Stage: StageDetach,
+		Hashes: hashes})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	err = j.Put(&JournalEntry{Op: OpReorganize, Stage: StageAttach,
+		Hashes: hashes})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	entry, err = j.Get()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, OpReorganize, entry.Op)
+	assert.Equal(t, StageAttach, entry.Stage)
+	assert.Equal(t, hashes, entry.Hashes)
+
+	err = j.Del()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	entry, err = j.Get()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Nil(t, entry)
+
+	// Delete again should not fail.
+	assert.NoError(t, j.Del())
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"crypto/rand"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type testTx struct {
+	hash common.Uint256
+}
+
+func (tx *testTx) Hash() common.Uint256                { return tx.hash }
+func (tx *testTx) Serialize(w io.Writer) error         { return tx.hash.Serialize(w) }
+func (tx *testTx) Deserialize(r io.Reader) error       { return tx.hash.Deserialize(r) }
+func (tx *testTx) MatchFilter(filter util.Filter) bool { return true }
+
+func newTestBlock(prev *util.Header, nonce uint32) *util.Block {
+	header := &types.Header{Nonce: nonce}
+	height := uint32(0)
+	work := big.NewInt(1)
+	if prev != nil {
+		header.Previous = prev.Hash()
+		height = prev.Height + 1
+		work.Add(work, prev.TotalWork)
+	}
+	header.Height = height
+
+	tx := new(testTx)
+	rand.Read(tx.hash[:])
+
+	return &util.Block{
+		Header: util.Header{
+			BlockHeader: iutil.NewHeader(header),
+			Height:      height,
+			TotalWork:   work,
+		},
+		Transactions: []util.Transaction{tx},
+	}
+}
+
+func TestMemChainDB(t *testing.T) {
+	db := NewMemChainDB().(*chainDB)
+	defer db.Close()
+
+	_, err := db.Headers().GetBest()
+	if !assert.Equal(t, ErrNotFound, err) {
+		t.FailNow()
+	}
+
+	// Main chain 0 <- 1 <- 2 <- 3
+	main := []*util.Block{newTestBlock(nil, 0)}
+	for i := 1; i < 4; i++ {
+		main = append(main, newTestBlock(&main[i-1].Header, 0))
+	}
+	for _, block := range main {
+		_, err := db.CommitBlock(block, true)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+
+	// Fork chain 1 <- 2' <- 3' <- 4'
+	fork := []*util.Block{main[1]}
+	for i := 1; i < 4; i++ {
+		fork = append(fork, newTestBlock(&fork[i-1].Header, 1))
+		_, err := db.CommitBlock(fork[i], false)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+
+	best, err := db.Headers().GetBest()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Equal(t, main[3].Hash(), best.Hash()) {
+		t.FailNow()
+	}
+
+	reorg, err := db.ProcessReorganize(&main[1].Header, &main[3].Header,
+		&fork[3].Header)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Equal(t, 2, len(reorg.Detached)) ||
+		!assert.Equal(t, 3, len(reorg.Attached)) {
+		t.FailNow()
+	}
+	assert.Equal(t, main[3].Hash(), reorg.Detached[0].Hash())
+	assert.Equal(t, fork[3].Hash(), reorg.Attached[2].Hash())
+
+	best, err = db.Headers().GetBest()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, fork[3].Hash(), best.Hash())
+
+	// Height index follows the new best chain.
+	headers, err := db.Headers().GetRange(0, 4)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for i, header := range headers[1:] {
+		assert.Equal(t, fork[i].Hash(), header.Hash())
+	}
+
+	for _, block := range fork[1:] {
+		txs, err := db.t.GetTxs(block.Height)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, block.Transactions, txs)
+	}
+	for _, block := range main[2:] {
+		txId := block.Transactions[0].Hash()
+		have, err := db.t.HaveTx(&txId)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.False(t, have)
+	}
+
+	if !assert.NoError(t, db.Clear()) {
+		t.FailNow()
+	}
+	_, err = db.Headers().GetBest()
+	assert.Equal(t, ErrNotFound, err)
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// Ensure memHeaders implement Headers interface.
+var _ Headers = (*memHeaders)(nil)
+
+// memHeaders implements Headers and keeps all headers in memory.
+type memHeaders struct {
+	sync.RWMutex
+	headers map[common.Uint256]*util.Header
+	heights map[uint32]common.Uint256
+	tip     *util.Header
+}
+
+// NewMemHeaders creates a Headers database that keeps all headers in memory,
+// all data will be lost after the process exits.
+func NewMemHeaders() Headers {
+	return &memHeaders{
+		headers: make(map[common.Uint256]*util.Header),
+		heights: make(map[uint32]common.Uint256),
+	}
+}
+
+// Save a header to database
+func (h *memHeaders) Put(header *util.Header, newTip bool) error {
+	h.Lock()
+	defer h.Unlock()
+
+	h.headers[header.Hash()] = header
+	if newTip {
+		h.index(header)
+		h.tip = header
+	}
+	return nil
+}
+
+// index updates the height index to the main chain ending with tip.  Heights
+// above tip are removed, and heights of the previous main chain are replaced
+// back to the common ancestor, or a missing header.
+func (h *memHeaders) index(tip *util.Header) {
+	if h.tip != nil {
+		for height := h.tip.Height; height > tip.Height; height-- {
+			delete(h.heights, height)
+		}
+	}
+
+	h.heights[tip.Height] = tip.Hash()
+	for header := tip; header.Height > 0; {
+		prev := header.Previous()
+		if hash, ok := h.heights[header.Height-1]; ok && hash.IsEqual(prev) {
+			break
+		}
+
+		var ok bool
+		header, ok = h.headers[prev]
+		if !ok {
+			break
+		}
+		h.heights[header.Height] = prev
+	}
+}
+
+// Get previous block of the given header
+func (h *memHeaders) GetPrevious(header *util.Header) (*util.Header, error) {
+	hash := header.Previous()
+	return h.Get(&hash)
+}
+
+// Get full header with it's hash
+func (h *memHeaders) Get(hash *common.Uint256) (*util.Header, error) {
+	h.RLock()
+	defer h.RUnlock()
+
+	header, ok := h.headers[*hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return header, nil
+}
+
+// Get the header on chain tip
+func (h *memHeaders) GetBest() (*util.Header, error) {
+	h.RLock()
+	defer h.RUnlock()
+
+	if h.tip == nil {
+		return nil, ErrNotFound
+	}
+	return h.tip, nil
+}
+
+// Del deletes the header with the given hash.
+func (h *memHeaders) Del(hash *common.Uint256) error {
+	h.Lock()
+	defer h.Unlock()
+
+	delete(h.headers, *hash)
+	return nil
+}
+
+// GetByHeight returns the main chain header on the given height.
+func (h *memHeaders) GetByHeight(height uint32) (*util.Header, error) {
+	h.RLock()
+	defer h.RUnlock()
+
+	header, ok := h.headers[h.heights[height]]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return header, nil
+}
+
+// GetRange returns the main chain headers from height from to height to.
+func (h *memHeaders) GetRange(from, to uint32) ([]*util.Header, error) {
+	return GetHeadersRange(h, from, to)
+}
+
+// Iterate calls fn with the main chain headers from height from to the chain
+// tip.
+func (h *memHeaders) Iterate(from uint32, fn func(header *util.Header) bool) error {
+	return IterateHeaders(h, from, fn)
+}
+
+// Clear delete all data in database.
+func (h *memHeaders) Clear() error {
+	h.Lock()
+	defer h.Unlock()
+
+	h.headers = make(map[common.Uint256]*util.Header)
+	h.heights = make(map[uint32]common.Uint256)
+	h.tip = nil
+	return nil
+}
+
+// Close database.
+func (h *memHeaders) Close() error {
+	return nil
+}
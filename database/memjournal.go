@@ -0,0 +1,57 @@
+package database
+
+import (
+	"sync"
+)
+
+// Ensure memJournal implement Journal interface.
+var _ Journal = (*memJournal)(nil)
+
+// memJournal implements Journal in memory, it is used by in memory databases
+// which have nothing to recover after restart.
+type memJournal struct {
+	sync.Mutex
+	entry *JournalEntry
+}
+
+// NewMemJournal creates a Journal that keeps the entry in memory.
+func NewMemJournal() Journal {
+	return new(memJournal)
+}
+
+// Put records the operation in progress.
+func (j *memJournal) Put(entry *JournalEntry) error {
+	j.Lock()
+	defer j.Unlock()
+
+	copied := *entry
+	j.entry = &copied
+	return nil
+}
+
+// Get returns the operation in progress, or nil if there is none.
+func (j *memJournal) Get() (*JournalEntry, error) {
+	j.Lock()
+	defer j.Unlock()
+
+	return j.entry, nil
+}
+
+// Del removes the operation record after it has been fully applied.
+func (j *memJournal) Del() error {
+	j.Lock()
+	defer j.Unlock()
+
+	j.entry = nil
+	return nil
+}
+
+// Clear delete all data in database.
+func (j *memJournal) Clear() error {
+	return j.Del()
+}
+
+// Close database.
+func (j *memJournal) Close() error {
+	return nil
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// Ensure memTxsDB implement TxsDB interface.
+var _ TxsDB = (*memTxsDB)(nil)
+
+// memTxsDB implements TxsDB and keeps all transactions in memory.  It does not
+// filter transactions, so all transactions are stored and no false positive
+// transactions will be reported.
+type memTxsDB struct {
+	sync.RWMutex
+	txs     map[uint32][]util.Transaction
+	forkTxs map[common.Uint256][]util.Transaction
+	txIds   map[common.Uint256]uint32
+}
+
+// NewMemTxsDB creates a TxsDB that keeps all transactions in memory, all data
+// will be lost after the process exits.
+func NewMemTxsDB() TxsDB {
+	db := new(memTxsDB)
+	db.clear()
+	return db
+}
+
+// PutTxs persists the main chain transactions into database and can be
+// queried by GetTxs(height).  Returns the false positive transaction count
+// and error.
+func (d *memTxsDB) PutTxs(txs []util.Transaction, height uint32) (uint32, error) {
+	d.Lock()
+	defer d.Unlock()
+
+	d.delTxs(height)
+	d.txs[height] = txs
+	for _, tx := range txs {
+		d.txIds[tx.Hash()] = height
+	}
+	return 0, nil
+}
+
+// PutForkTxs persists the fork chain transactions into database with the
+// fork block hash and can be queried by GetForkTxs(hash).
+func (d *memTxsDB) PutForkTxs(txs []util.Transaction, hash *common.Uint256) error {
+	d.Lock()
+	defer d.Unlock()
+
+	d.forkTxs[*hash] = txs
+	return nil
+}
+
+// HaveTx returns if the transaction already saved in database
+// by it's id.
+func (d *memTxsDB) HaveTx(txId *common.Uint256) (bool, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	_, ok := d.txIds[*txId]
+	return ok, nil
+}
+
+// GetTxs returns all transactions in main chain within the given height.
+func (d *memTxsDB) GetTxs(height uint32) ([]util.Transaction, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	return d.txs[height], nil
+}
+
+// GetForkTxs returns all transactions within the fork block hash.
+func (d *memTxsDB) GetForkTxs(hash *common.Uint256) ([]util.Transaction, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	txs, ok := d.forkTxs[*hash]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return txs, nil
+}
+
+// DelTxs remove all transactions in main chain within the given height.
+func (d *memTxsDB) DelTxs(height uint32) error {
+	d.Lock()
+	defer d.Unlock()
+
+	d.delTxs(height)
+	return nil
+}
+
+func (d *memTxsDB) delTxs(height uint32) {
+	for _, tx := range d.txs[height] {
+		delete(d.txIds, tx.Hash())
+	}
+	delete(d.txs, height)
+}
+
+// Clear delete all data in database.
+func (d *memTxsDB) Clear() error {
+	d.Lock()
+	defer d.Unlock()
+
+	d.clear()
+	return nil
+}
+
+func (d *memTxsDB) clear() {
+	d.txs = make(map[uint32][]util.Transaction)
+	d.forkTxs = make(map[common.Uint256][]util.Transaction)
+	d.txIds = make(map[common.Uint256]uint32)
+}
+
+// Close database.
+func (d *memTxsDB) Close() error {
+	return nil
+}
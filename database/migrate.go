@@ -0,0 +1,85 @@
+package database
+
+import (
+	"fmt"
+)
+
+// Versioned is a database that records the version of it's data format.
+type Versioned interface {
+	// Version returns the recorded data format version, 0 means no version
+	// has been recorded, which is a new database or a database created before
+	// versioning was introduced.
+	Version() (uint32, error)
+
+	// SetVersion records the data format version.
+	SetVersion(version uint32) error
+}
+
+// Migration is a step to upgrade the data format from the previous version
+// to Version.
+type Migration struct {
+	// Version is the data format version after this migration.
+	Version uint32
+
+	// Description describes what is changed by this migration.
+	Description string
+
+	// Upgrade converts the data from the previous version, it will not be
+	// called on databases already in Version.
+	Upgrade func() error
+}
+
+// VersionError is returned when the database is created by a newer version of
+// the program, which data format can not be recognized.
+type VersionError struct {
+	Name      string
+	Version   uint32
+	Supported uint32
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("%s database version %d is newer than the supported"+
+		" version %d, it was created by a newer version of the program",
+		e.Name, e.Version, e.Supported)
+}
+
+// Upgrade runs the migrations newer than the version recorded in db by the
+// ascending Version order, the version is recorded after each step, so an
+// interrupted upgrade continues from the failed step next time.  The name of
+// the database is used in error messages.
+func Upgrade(name string, db Versioned, migrations []Migration) error {
+	if len(migrations) == 0 {
+		return nil
+	}
+
+	version, err := db.Version()
+	if err != nil {
+		return fmt.Errorf("%s database get version failed, %s", name, err)
+	}
+
+	latest := migrations[len(migrations)-1].Version
+	if version > latest {
+		return &VersionError{Name: name, Version: version, Supported: latest}
+	}
+
+	for _, m := range migrations {
+		if m.Version <= version {
+			continue
+		}
+
+		if m.Upgrade != nil {
+			if err := m.Upgrade(); err != nil {
+				return fmt.Errorf("%s database upgrade to version %d (%s)"+
+					" failed, %s", name, m.Version, m.Description, err)
+			}
+		}
+
+		if err := db.SetVersion(m.Version); err != nil {
+			return fmt.Errorf("%s database set version %d failed, %s",
+				name, m.Version, err)
+		}
+		version = m.Version
+	}
+
+	return nil
+}
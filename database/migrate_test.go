@@ -0,0 +1,83 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testVersioned struct {
+	version uint32
+}
+
+func (v *testVersioned) Version() (uint32, error) {
+	return v.version, nil
+}
+
+func (v *testVersioned) SetVersion(version uint32) error {
+	v.version = version
+	return nil
+}
+
+func TestUpgrade(t *testing.T) {
+	var steps []uint32
+	fail := false
+	migrations := []Migration{
+		{Version: 1, Description: "initial"},
+		{Version: 2, Upgrade: func() error {
+			steps = append(steps, 2)
+			return nil
+		}},
+		{Version: 3, Upgrade: func() error {
+			if fail {
+				return errors.New("failed")
+			}
+			steps = append(steps, 3)
+			return nil
+		}},
+	}
+
+	// Upgrade a new database.
+	db := new(testVersioned)
+	err := Upgrade("test", db, migrations)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, uint32(3), db.version)
+	assert.Equal(t, []uint32{2, 3}, steps)
+
+	// Nothing to do on the latest version.
+	steps = nil
+	err = Upgrade("test", db, migrations)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Nil(t, steps)
+
+	// Failed step stops upgrade and keeps the version of the last step.
+	fail = true
+	db = &testVersioned{version: 1}
+	err = Upgrade("test", db, migrations)
+	if !assert.Error(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, uint32(2), db.version)
+
+	// Continue from the failed step.
+	fail = false
+	steps = nil
+	err = Upgrade("test", db, migrations)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []uint32{3}, steps)
+
+	// Database created by a newer version.
+	db = &testVersioned{version: 4}
+	err = Upgrade("test", db, migrations)
+	if !assert.IsType(t, &VersionError{}, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, uint32(4), db.version)
+}
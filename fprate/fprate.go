@@ -29,6 +29,13 @@ func (r *FpRate) Update(block *util.Block, fps uint32) (fpRate float64) {
 	return r.fpRate
 }
 
+// Rate returns the current measured false positive rate.
+func (r *FpRate) Rate() float64 {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.fpRate
+}
+
 func (r *FpRate) Reset() {
 	r.mtx.Lock()
 	r.fpRate = ReducedFalsePositiveRate
@@ -0,0 +1,118 @@
+package _interface
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+)
+
+// CrossChainOutput is a deposit to an address on the sidechain.
+type CrossChainOutput struct {
+	// Index is the index of the output paid to the sidechain genesis
+	// address.
+	Index uint16
+
+	// TargetAddress is the address on the sidechain receives the deposit.
+	TargetAddress string
+
+	// AssetID is the asset of the deposit.
+	AssetID common.Uint256
+
+	// Amount is the amount the target address receives on the sidechain.
+	Amount common.Fixed64
+
+	// Fee is the cross chain fee, the output value minus the amount.
+	Fee common.Fixed64
+}
+
+// CrossChainDeposit is a TransferCrossChainAsset transaction deposits to a
+// sidechain, decoded from the transaction payload.
+type CrossChainDeposit struct {
+	// GenesisAddress is the sidechain genesis address the deposits paid to.
+	GenesisAddress string
+
+	// Outputs are the deposits to the sidechain addresses.
+	Outputs []*CrossChainOutput
+
+	// Proof is the merkle proof of the transaction.
+	Proof bloom.MerkleProof
+
+	// Tx is the TransferCrossChainAsset transaction.
+	Tx types.Transaction
+}
+
+// CrossChainListener is a TransactionListener receives typed cross chain
+// deposit notifications.  Register a listener with Type() returns
+// TransferCrossChainAsset and Address() returns the sidechain genesis
+// address, then NotifyDeposit is called instead of Notify.
+type CrossChainListener interface {
+	TransactionListener
+
+	// NotifyDeposit is the method to callback the received cross chain
+	// deposit, the notifyId must be submitted with the receipt like Notify.
+	NotifyDeposit(notifyId common.Uint256, deposit *CrossChainDeposit)
+}
+
+// ParseCrossChainDeposit decodes the deposits to the sidechain genesis address
+// from a TransferCrossChainAsset transaction.
+func ParseCrossChainDeposit(tx *types.Transaction,
+	genesisAddress string) (*CrossChainDeposit, error) {
+
+	if tx.TxType != types.TransferCrossChainAsset {
+		return nil, fmt.Errorf("transaction type %s is not %s",
+			tx.TxType.Name(), types.TransferCrossChainAsset.Name())
+	}
+	p, ok := tx.Payload.(*payload.TransferCrossChainAsset)
+	if !ok {
+		return nil, errors.New("invalid TransferCrossChainAsset payload")
+	}
+	if len(p.CrossChainAddresses) != len(p.OutputIndexes) ||
+		len(p.CrossChainAddresses) != len(p.CrossChainAmounts) {
+		return nil, errors.New("TransferCrossChainAsset payload fields" +
+			" length not match")
+	}
+
+	genesis, err := common.Uint168FromAddress(genesisAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid genesis address %s", genesisAddress)
+	}
+
+	deposit := &CrossChainDeposit{
+		GenesisAddress: genesisAddress,
+		Tx:             *tx,
+	}
+	for i, address := range p.CrossChainAddresses {
+		index := p.OutputIndexes[i]
+		if index >= uint64(len(tx.Outputs)) {
+			return nil, fmt.Errorf("cross chain output index %d out of"+
+				" range", index)
+		}
+		output := tx.Outputs[index]
+		if !output.ProgramHash.IsEqual(*genesis) {
+			continue
+		}
+
+		amount := p.CrossChainAmounts[i]
+		if amount < 0 || amount > output.Value {
+			return nil, fmt.Errorf("invalid cross chain amount %s of"+
+				" output value %s", amount, output.Value)
+		}
+		deposit.Outputs = append(deposit.Outputs, &CrossChainOutput{
+			Index:         uint16(index),
+			TargetAddress: address,
+			AssetID:       output.AssetID,
+			Amount:        amount,
+			Fee:           output.Value - amount,
+		})
+	}
+	if len(deposit.Outputs) == 0 {
+		return nil, fmt.Errorf("no deposit to genesis address %s",
+			genesisAddress)
+	}
+	return deposit, nil
+}
@@ -0,0 +1,59 @@
+package _interface
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCrossChainDeposit(t *testing.T) {
+	var genesis, change common.Uint168
+	rand.Read(genesis[:])
+	rand.Read(change[:])
+	genesis[0], change[0] = 0x4b, 0x21
+	genesisAddress, err := genesis.ToAddress()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	tx := &types.Transaction{
+		TxType: types.TransferCrossChainAsset,
+		Payload: &payload.TransferCrossChainAsset{
+			CrossChainAddresses: []string{"EXa1", "EXa2"},
+			OutputIndexes:       []uint64{0, 2},
+			CrossChainAmounts:   []common.Fixed64{90, 190},
+		},
+		Outputs: []*types.Output{
+			{ProgramHash: genesis, Value: 100},
+			{ProgramHash: change, Value: 1000},
+			{ProgramHash: genesis, Value: 200},
+		},
+	}
+
+	deposit, err := ParseCrossChainDeposit(tx, genesisAddress)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, genesisAddress, deposit.GenesisAddress)
+	if !assert.Equal(t, 2, len(deposit.Outputs)) {
+		t.FailNow()
+	}
+	assert.Equal(t, CrossChainOutput{Index: 0, TargetAddress: "EXa1",
+		Amount: 90, Fee: 10}, *deposit.Outputs[0])
+	assert.Equal(t, CrossChainOutput{Index: 2, TargetAddress: "EXa2",
+		Amount: 190, Fee: 10}, *deposit.Outputs[1])
+
+	// Amount larger than the output value.
+	tx.Payload.(*payload.TransferCrossChainAsset).CrossChainAmounts[0] = 101
+	_, err = ParseCrossChainDeposit(tx, genesisAddress)
+	assert.Error(t, err)
+
+	// Not a cross chain transaction.
+	tx.TxType = types.TransferAsset
+	_, err = ParseCrossChainDeposit(tx, genesisAddress)
+	assert.Error(t, err)
+}
@@ -1,8 +1,16 @@
 package _interface
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
 	"github.com/elastos/Elastos.ELA.SPV/bloom"
 	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+	"github.com/elastos/Elastos.ELA.SPV/util"
 
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/common/config"
@@ -20,31 +28,115 @@ type Config struct {
 	// PermanentPeers are the peers need to be connected permanently.
 	PermanentPeers []string
 
+	// DBBackend is the key-value database used to store headers and data,
+	// it can be kvdb.LevelDB or kvdb.BoltDB, LevelDB will be used if empty.
+	DBBackend kvdb.Backend
+
+	// PruneProofs drops the merkle proof of blocks without transactions to
+	// save disk space.
+	PruneProofs bool
+
+	// KeepHeaders is the number of recent headers to keep, older headers are
+	// deleted except checkpoints and blocks with transactions, zero keeps all
+	// headers.
+	KeepHeaders uint32
+
+	// CheckpointInterval keeps one of every CheckpointInterval old headers
+	// when pruning headers.
+	CheckpointInterval uint32
+
+	// CompactHeaders stores all main chain headers as fixed-size records in
+	// a header file, which are kept when headers are pruned.
+	CompactHeaders bool
+
+	// ListenerTTL is the period after which a listener not registered again
+	// is removed with it's queued notifications, zero keeps the listeners
+	// and notifications until the listener is unregistered.
+	ListenerTTL time.Duration
+
+	// RetryBaseDelay is the delay to resend a notification not receipted,
+	// the delay doubles on each attempt, 10 seconds will be used if zero.
+	RetryBaseDelay time.Duration
+
+	// RetryMaxDelay is the max delay to resend a notification, 10 minutes
+	// will be used if zero.
+	RetryMaxDelay time.Duration
+
+	// MaxAttempts is the max times to send a notification before it is moved
+	// to dead letters, zero retries until the notification is receipted.
+	MaxAttempts uint32
+
+	// UnconfirmedTimeout is the period after which an unconfirmed transaction
+	// notified to listeners with FlagNotifyUnconfirmed and not packed is
+	// dropped, 24 hours will be used if zero.
+	UnconfirmedTimeout time.Duration
+
 	// Rollback callbacks that, the transactions
 	// on the given height has been rollback
 	OnRollback func(height uint32)
+
+	// Reorganize callbacks that, the main chain has been switched to a new
+	// best chain, with the detached and attached blocks.
+	OnReorganize func(reorg *util.Reorg)
 }
 
 /*
 SPV service is the interface to interactive with the SPV (Simplified Payment Verification)
 service implementation running background, you can register specific accounts that you are
 interested in and receive transaction notifications of these accounts.
+
+The confirmations returned and notified by the service are the number of blocks on top of
+the block a transaction packed in, so a transaction in the best block has zero confirmations.
 */
 type SPVService interface {
-	// RegisterTransactionListener register the listener to receive transaction notifications
-	// listeners must be registered before call Start() method, or some notifications will go missing.
+	// RegisterTransactionListener register the listener to receive transaction notifications,
+	// listeners can be registered at any time, a new address is added to the filter of
+	// connected peers, transactions in blocks synced before registration are not notified.
 	RegisterTransactionListener(TransactionListener) error
 
+	// UnregisterTransactionListener removes the listener and purges it's queued
+	// notifications, the address stays registered.
+	UnregisterTransactionListener(TransactionListener) error
+
+	// ListTransactionListeners returns the registered listeners, including
+	// the listeners restored from last run which are not registered again.
+	ListTransactionListeners() ([]*ListenerInfo, error)
+
+	// Subscribe registers a listener of the filter and returns the channel of it's
+	// events, as an alternative to implement TransactionListener.  The listener is
+	// unregistered with it's queued notifications and the channel is closed when
+	// the context is done.
+	Subscribe(ctx context.Context, filter *SubscriptionFilter) (<-chan *Event, error)
+
 	// After receive the transaction callback, call this method
 	// to confirm that the transaction with the given ID was handled,
 	// so the transaction will be removed from the notify queue.
 	// the notifyId is the key to specify which listener received this notify.
 	SubmitTransactionReceipt(notifyId common.Uint256, txId common.Uint256) error
 
+	// GetDeadLetters returns the notifications not receipted after
+	// MaxAttempts times sent.
+	GetDeadLetters() ([]*DeadLetter, error)
+
+	// ReplayDeadLetter moves the dead letter back to the notify queue, the
+	// notification will be sent again with the attempts reset.
+	ReplayDeadLetter(notifyId common.Uint256, txId common.Uint256) error
+
 	// To verify if a transaction is valid
 	// This method is useful when receive a transaction from other peer
 	VerifyTransaction(bloom.MerkleProof, types.Transaction) error
 
+	// VerifyDeposit verifies the cross chain deposit is included in a block
+	// of the stored main chain with at least the given confirmations, and
+	// the deposits are decoded from the transaction.
+	VerifyDeposit(deposit *CrossChainDeposit, confirmations uint32) error
+
+	// GetProofBundle returns a self-contained proof of the transaction with
+	// the header chain from the checkpoint height to the chain tip, it can
+	// be verified by bloom.VerifyProofBundle without a header store.
+	GetProofBundle(txId *common.Uint256,
+		checkpointHeight uint32) (*bloom.ProofBundle, error)
+
 	// Send a transaction to the P2P network
 	SendTransaction(types.Transaction) error
 
@@ -54,9 +146,30 @@ type SPVService interface {
 	// GetTransactionIds query all transaction hashes on the given block height.
 	GetTransactionIds(height uint32) ([]*common.Uint256, error)
 
+	// GetTransactionsByAddress query the transactions of the given address
+	// sorted by height in the given order, skips offset transactions and
+	// returns at most limit transactions, limit 0 means no limit.
+	GetTransactionsByAddress(address string, offset, limit uint32,
+		order Order) ([]*AddressTx, error)
+
+	// GetBalance returns the balance of the given asset owned by a
+	// registered address.
+	GetBalance(address string, assetId common.Uint256) (*Balance, error)
+
+	// ListUnspent returns the unspent outputs of a registered address.
+	ListUnspent(address string) ([]*UTXO, error)
+
 	// Get headers database
 	HeaderStore() database.Headers
 
+	// CompactHeaders returns the header file of compact main chain headers,
+	// or nil if Config.CompactHeaders is not set.
+	CompactHeaders() *database.HeaderFile
+
+	// IsCurrent returns whether or not the SPV service believes it is synced with
+	// the connected peers.
+	IsCurrent() bool
+
 	// Start the SPV service
 	Start()
 
@@ -67,6 +180,131 @@ type SPVService interface {
 	ClearData() error
 }
 
+// ListenerInfo is a listener registration returned by
+// ListTransactionListeners.
+type ListenerInfo struct {
+	// NotifyId is the key of the listener's notifications.
+	NotifyId common.Uint256
+
+	// Address is the address the listener interested, empty if the
+	// listener was registered before listeners were persisted.
+	Address string
+
+	// Type is the transaction type the listener interested.
+	Type types.TxType
+
+	// Flags are the notification flags of the listener.
+	Flags uint64
+
+	// Registered is the time the listener was first registered.
+	Registered time.Time
+
+	// LastSeen is the last time the listener was registered in the service.
+	LastSeen time.Time
+
+	// Active indicates the listener is registered in the running service
+	// and receives notifications.
+	Active bool
+}
+
+// DeadLetter is a notification given up after MaxAttempts times sent,
+// returned by GetDeadLetters.
+type DeadLetter struct {
+	// NotifyId is the key of the listener the notification sent to.
+	NotifyId common.Uint256
+
+	// TxId is the id of the notified transaction.
+	TxId common.Uint256
+
+	// Height is the height of the block the transaction packed in.
+	Height uint32
+
+	// Attempts is the times the notification was sent.
+	Attempts uint32
+
+	// LastNotify is the last time the notification was sent.
+	LastNotify time.Time
+
+	// Time is the time the notification was moved to dead letters.
+	Time time.Time
+}
+
+// Order is the sort order of query results.
+type Order int
+
+const (
+	// Ascending sorts results from the lowest height.
+	Ascending Order = iota
+
+	// Descending sorts results from the highest height.
+	Descending
+)
+
+// AddressTx is a transaction returned by GetTransactionsByAddress.
+type AddressTx struct {
+	// Tx is the transaction.
+	Tx *types.Transaction
+
+	// Height is the block height the transaction packed in.
+	Height uint32
+
+	// Confirmations is the number of blocks on top of the transaction
+	// block, zero if the transaction is in the best block.
+	Confirmations uint32
+}
+
+// UTXOState is the spendable state of an unspent output.
+type UTXOState int
+
+const (
+	// UTXOConfirmed indicates the output can be spent.
+	UTXOConfirmed UTXOState = iota
+
+	// UTXOImmature indicates the output is a coinbase output without
+	// CoinbaseMaturity confirmations.
+	UTXOImmature
+
+	// UTXOLocked indicates the output is locked by it's OutputLock height.
+	UTXOLocked
+)
+
+// UTXO is an unspent output returned by ListUnspent.
+type UTXO struct {
+	// Op is the outpoint of the output.
+	Op util.OutPoint
+
+	// AssetID is the asset of the output value.
+	AssetID common.Uint256
+
+	// Value is the amount of the output.
+	Value common.Fixed64
+
+	// OutputLock is the height before which the output can not be spent.
+	OutputLock uint32
+
+	// Height is the block height of the transaction created the output.
+	Height uint32
+
+	// Confirmations is the number of blocks on top of the output block,
+	// zero if the output is in the best block.
+	Confirmations uint32
+
+	// State is the spendable state of the output.
+	State UTXOState
+}
+
+// Balance is the balance of an asset returned by GetBalance.
+type Balance struct {
+	// Confirmed is the value can be spent.
+	Confirmed common.Fixed64
+
+	// Immature is the value of coinbase outputs not mature yet.
+	Immature common.Fixed64
+
+	// Locked is the value locked by output lock height.
+	Locked common.Fixed64
+}
+
 const (
 	// FlagNotifyConfirmed indicates if this transaction should be callback after reach the confirmed height,
 	// by default 6 confirmations are needed according to the protocol
@@ -74,8 +312,41 @@ const (
 
 	// FlagNotifyInSyncing indicates if notify this listener when SPV is in syncing.
 	FlagNotifyInSyncing = 1 << 1
+
+	// FlagNotifyUnconfirmed indicates if notify this listener of transactions
+	// announced by peers before they are packed, with an empty proof.  The
+	// confirmed notification follows once the transaction is packed, and a
+	// DropListener is told if the transaction is dropped.  Unconfirmed
+	// notifications are not queued and need no receipt, the transactions
+	// waiting to be packed are kept in memory only, so no drop notice is sent
+	// for them after the service restarted.
+	FlagNotifyUnconfirmed = 1 << 2
+)
+
+// DropReason is the reason an unconfirmed transaction was dropped.
+type DropReason uint8
+
+const (
+	// DropExpired indicates the transaction was not packed within the
+	// UnconfirmedTimeout.
+	DropExpired DropReason = iota
+
+	// DropDoubleSpent indicates an input of the transaction was spent by
+	// another transaction packed in a block.
+	DropDoubleSpent
 )
 
+func (r DropReason) String() string {
+	switch r {
+	case DropExpired:
+		return "expired"
+	case DropDoubleSpent:
+		return "double spent"
+	default:
+		return fmt.Sprintf("DropReason%d", r)
+	}
+}
+
 /*
 Register this listener into the IService RegisterTransactionListener() method
 to receive transaction notifications.
@@ -95,3 +366,91 @@ type TransactionListener interface {
 	// notify message and it must be submitted with the receipt together.
 	Notify(notifyId common.Uint256, proof bloom.MerkleProof, tx types.Transaction)
 }
+
+// RevocationListener is a TransactionListener told when a transaction it was
+// notified of is rolled back by a chain reorganization.  The transaction is
+// notified again if it is included in the new best chain.  Revocations are
+// queued persistently, revocations of a listener not registered are sent
+// after it is registered again.
+type RevocationListener interface {
+	TransactionListener
+
+	// Revoked is the method to callback the notified transaction on the
+	// given height has been rolled back.
+	Revoked(notifyId common.Uint256, txId common.Uint256, height uint32)
+}
+
+// DropListener is a TransactionListener with FlagNotifyUnconfirmed told when
+// an unconfirmed transaction it was notified of will not be packed.
+type DropListener interface {
+	TransactionListener
+
+	// Dropped is the method to callback the unconfirmed transaction has been
+	// dropped for the given reason.
+	Dropped(notifyId common.Uint256, txId common.Uint256, reason DropReason)
+}
+
+// ListenerOptions extends the notification conditions of a listener.
+type ListenerOptions struct {
+	// Types are the transaction types the listener interested, Type() of
+	// the listener is used if it is empty.
+	Types []types.TxType
+
+	// AllTypes notifies transactions of all types, Types and Type() are
+	// ignored if it is true.
+	AllTypes bool
+
+	// Confirmations is the confirmations required to notify a transaction
+	// with FlagNotifyConfirmed, zero uses the default confirmations.
+	Confirmations uint32
+
+	// AssetID notifies only transactions with outputs of the asset paid to
+	// the address, nil notifies all assets.
+	AssetID *common.Uint256
+
+	// MinAmount notifies only transactions paid at least the amount of
+	// AssetID to the address, or of the ELA asset if AssetID is nil, zero
+	// notifies all amounts.
+	MinAmount common.Fixed64
+}
+
+func (o *ListenerOptions) types(listener TransactionListener) []types.TxType {
+	if len(o.Types) == 0 {
+		return []types.TxType{listener.Type()}
+	}
+	return o.Types
+}
+
+func (o *ListenerOptions) serialize(w io.Writer) error {
+	txTypes := make([]byte, 0, len(o.Types))
+	for _, txType := range o.Types {
+		txTypes = append(txTypes, byte(txType))
+	}
+	sort.Slice(txTypes, func(i, j int) bool { return txTypes[i] < txTypes[j] })
+	if err := common.WriteVarBytes(w, txTypes); err != nil {
+		return err
+	}
+	var assetID common.Uint256
+	if o.AssetID != nil {
+		assetID = *o.AssetID
+	}
+	return common.WriteElements(w, o.AllTypes, o.Confirmations, &assetID,
+		o.MinAmount)
+}
+
+// OptionsListener is a TransactionListener with extended notification
+// conditions.  Listeners not implementing it, or returning nil options, are
+// notified of transactions of Type() with the default confirmations.
+type OptionsListener interface {
+	TransactionListener
+
+	// Options returns the notification conditions of the listener, it
+	// must return the same options for the listener every time.
+	Options() *ListenerOptions
+}
+
+// NewProofBundle returns an empty proof bundle of ELA main chain headers and
+// transactions to decode a proof bundle into.
+func NewProofBundle() *bloom.ProofBundle {
+	return bloom.NewProofBundle(newBlockHeader, newTransaction)
+}
@@ -30,6 +30,15 @@ type Keystore interface {
 	FromJson(json string, password string) error
 }
 
+// HDKeystore is a Keystore with the accounts derived from a BIP39 mnemonic
+// by the ELA BIP44 path, it can be restored from the mnemonic.
+type HDKeystore interface {
+	Keystore
+
+	// Mnemonic returns the mnemonic words of the keystore.
+	Mnemonic(password string) (string, error)
+}
+
 type Account interface {
 	// Create a signature of the given data with this account
 	Sign(data []byte) ([]byte, error)
@@ -41,3 +50,10 @@ type Account interface {
 func NewKeystore() Keystore {
 	return &keystore{}
 }
+
+// NewHDKeystore returns a HD keystore, Open creates the keystore from the
+// mnemonic if the keystore file not exist, a new mnemonic is generated if
+// it is empty.
+func NewHDKeystore(mnemonic string) HDKeystore {
+	return &hdKeystore{mnemonic: mnemonic}
+}
@@ -54,3 +54,31 @@ func (impl *keystore) FromJson(str string, password string) error {
 	impl.keystore = new(client.Keystore)
 	return impl.keystore.FromJson(str, password)
 }
+
+type hdKeystore struct {
+	keystore
+	mnemonic string
+}
+
+// This method will open or create a HD keystore with the given password
+func (impl *hdKeystore) Open(password string) (Keystore, error) {
+	var err error
+	// Try to open keystore first
+	impl.keystore.keystore, err = client.OpenKeystore([]byte(password))
+	if err == nil {
+		return impl, nil
+	}
+
+	// Try to create a keystore from the mnemonic
+	impl.keystore.keystore, err = client.CreateHDKeystore([]byte(password),
+		impl.mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	return impl, nil
+}
+
+func (impl *hdKeystore) Mnemonic(password string) (string, error) {
+	return impl.keystore.keystore.Mnemonic([]byte(password))
+}
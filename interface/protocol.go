@@ -1,5 +1,12 @@
 package _interface
 
 const (
+	// DefaultConfirmations is the confirmations needed to notify a
+	// transaction with FlagNotifyConfirmed.  The confirmations of a
+	// transaction are the number of blocks on top of the block it packed in,
+	// a transaction in the best block has zero confirmations.
 	DefaultConfirmations = 6
+
+	// CoinbaseMaturity is the confirmations needed to spend coinbase outputs.
+	CoinbaseMaturity = 100
 )
@@ -0,0 +1,279 @@
+package spvrpc
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/interface"
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA.SPV/interface/spvrpc/pb"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"google.golang.org/grpc"
+)
+
+// Notification is a notification received from the Notify stream.
+type Notification struct {
+	// Type is the type of the notification.
+	Type pb.Notification_Type
+
+	// NotifyId is the key of the listener's notifications.
+	NotifyId common.Uint256
+
+	// TxId is the id of the transaction.
+	TxId common.Uint256
+
+	// Tx is the transaction of a TRANSACTION notification.
+	Tx *types.Transaction
+
+	// Proof is the merkle proof of a TRANSACTION notification, it is empty
+	// for an unconfirmed transaction.
+	Proof bloom.MerkleProof
+
+	// Height is the height of the block the transaction packed in.
+	Height uint32
+
+	// Reason is the reason of a DROPPED notification.
+	Reason _interface.DropReason
+}
+
+// SyncStatus is the sync status of the SPV service.
+type SyncStatus struct {
+	// BestHeight is the height of the chain tip.
+	BestHeight uint32
+
+	// BestHash is the hash of the chain tip.
+	BestHash common.Uint256
+
+	// Current indicates the service believes it is synced with peers.
+	Current bool
+}
+
+// Client is the gRPC client of a SPV service.
+type Client struct {
+	conn   *grpc.ClientConn
+	client pb.SPVClient
+}
+
+// Dial creates a client connected to the gRPC server of the target.
+func Dial(target string, opts ...grpc.DialOption) (*Client, error) {
+	conn, err := grpc.NewClient(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, client: pb.NewSPVClient(conn)}, nil
+}
+
+// NewClient creates a client on the connection, the connection is not
+// closed by Close.
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{client: pb.NewSPVClient(conn)}
+}
+
+// Close closes the connection created by Dial.
+func (c *Client) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// RegisterListener registers a listener and returns it's notify id.
+func (c *Client) RegisterListener(ctx context.Context,
+	req *pb.RegisterListenerRequest) (common.Uint256, error) {
+
+	resp, err := c.client.RegisterListener(ctx, req)
+	if err != nil {
+		return common.Uint256{}, err
+	}
+	notifyId, err := common.Uint256FromBytes(resp.NotifyId)
+	if err != nil {
+		return common.Uint256{}, err
+	}
+	return *notifyId, nil
+}
+
+// UnregisterListener removes the listener of the notify id.
+func (c *Client) UnregisterListener(ctx context.Context,
+	notifyId common.Uint256) error {
+
+	_, err := c.client.UnregisterListener(ctx,
+		&pb.UnregisterListenerRequest{NotifyId: notifyId[:]})
+	return err
+}
+
+// NotifyStream is a stream of the notifications of a listener.
+type NotifyStream struct {
+	stream pb.SPV_NotifyClient
+}
+
+// Notify opens the stream of the notifications of the listener, the stream
+// ends when the context is done.
+func (c *Client) Notify(ctx context.Context,
+	notifyId common.Uint256) (*NotifyStream, error) {
+
+	stream, err := c.client.Notify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = stream.Send(&pb.NotifyRequest{NotifyId: notifyId[:]})
+	if err != nil {
+		return nil, err
+	}
+	return &NotifyStream{stream: stream}, nil
+}
+
+// Recv receives the next notification.
+func (s *NotifyStream) Recv() (*Notification, error) {
+	n, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	notification := Notification{
+		Type:   n.Type,
+		Height: n.Height,
+		Reason: _interface.DropReason(n.DropReason),
+	}
+	notifyId, err := common.Uint256FromBytes(n.NotifyId)
+	if err != nil {
+		return nil, err
+	}
+	txId, err := common.Uint256FromBytes(n.TxId)
+	if err != nil {
+		return nil, err
+	}
+	notification.NotifyId, notification.TxId = *notifyId, *txId
+	if n.Type == pb.Notification_TRANSACTION {
+		var tx types.Transaction
+		if err := tx.Deserialize(bytes.NewReader(n.Tx)); err != nil {
+			return nil, err
+		}
+		notification.Tx = &tx
+		err := notification.Proof.Deserialize(bytes.NewReader(n.Proof))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &notification, nil
+}
+
+// Ack submits the receipt of the notified transaction.
+func (s *NotifyStream) Ack(txId common.Uint256) error {
+	return s.stream.Send(&pb.NotifyRequest{AckTxId: txId[:]})
+}
+
+// Close closes the sending direction of the stream.
+func (s *NotifyStream) Close() error {
+	return s.stream.CloseSend()
+}
+
+// VerifyTransaction verifies the transaction with the merkle proof.
+func (c *Client) VerifyTransaction(ctx context.Context,
+	proof bloom.MerkleProof, tx *types.Transaction) error {
+
+	proofBuf := new(bytes.Buffer)
+	if err := proof.Serialize(proofBuf); err != nil {
+		return err
+	}
+	txBuf := new(bytes.Buffer)
+	if err := tx.Serialize(txBuf); err != nil {
+		return err
+	}
+	_, err := c.client.VerifyTransaction(ctx, &pb.VerifyTransactionRequest{
+		Proof: proofBuf.Bytes(),
+		Tx:    txBuf.Bytes(),
+	})
+	return err
+}
+
+// SendTransaction sends the transaction to the P2P network.
+func (c *Client) SendTransaction(ctx context.Context,
+	tx *types.Transaction) (common.Uint256, error) {
+
+	buf := new(bytes.Buffer)
+	if err := tx.Serialize(buf); err != nil {
+		return common.Uint256{}, err
+	}
+	resp, err := c.client.SendTransaction(ctx,
+		&pb.SendTransactionRequest{Tx: buf.Bytes()})
+	if err != nil {
+		return common.Uint256{}, err
+	}
+	txId, err := common.Uint256FromBytes(resp.TxId)
+	if err != nil {
+		return common.Uint256{}, err
+	}
+	return *txId, nil
+}
+
+// GetTransaction returns the stored transaction of the id.
+func (c *Client) GetTransaction(ctx context.Context,
+	txId common.Uint256) (*types.Transaction, error) {
+
+	resp, err := c.client.GetTransaction(ctx,
+		&pb.GetTransactionRequest{TxId: txId[:]})
+	if err != nil {
+		return nil, err
+	}
+	var tx types.Transaction
+	if err := tx.Deserialize(bytes.NewReader(resp.Tx)); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// GetHeader returns the main chain header on the height, the merkle proof
+// and total work of the header are not returned.
+func (c *Client) GetHeader(ctx context.Context,
+	height uint32) (*util.Header, error) {
+
+	return toUtilHeader(c.client.GetHeader(ctx,
+		&pb.GetHeaderRequest{Height: height}))
+}
+
+// GetHeaderByHash returns the header of the hash.
+func (c *Client) GetHeaderByHash(ctx context.Context,
+	hash common.Uint256) (*util.Header, error) {
+
+	return toUtilHeader(c.client.GetHeader(ctx,
+		&pb.GetHeaderRequest{Hash: hash[:]}))
+}
+
+// GetBestHeader returns the header on the chain tip.
+func (c *Client) GetBestHeader(ctx context.Context) (*util.Header, error) {
+	return toUtilHeader(c.client.GetBestHeader(ctx, &pb.Empty{}))
+}
+
+// GetSyncStatus returns the sync status of the SPV service.
+func (c *Client) GetSyncStatus(ctx context.Context) (*SyncStatus, error) {
+	resp, err := c.client.GetSyncStatus(ctx, &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	hash, err := common.Uint256FromBytes(resp.BestHash)
+	if err != nil {
+		return nil, err
+	}
+	return &SyncStatus{
+		BestHeight: resp.BestHeight,
+		BestHash:   *hash,
+		Current:    resp.Current,
+	}, nil
+}
+
+func toUtilHeader(h *pb.Header, err error) (*util.Header, error) {
+	if err != nil {
+		return nil, err
+	}
+	var header types.Header
+	if err := header.Deserialize(bytes.NewReader(h.Header)); err != nil {
+		return nil, err
+	}
+	return &util.Header{
+		BlockHeader: iutil.NewHeader(&header),
+		Height:      h.Height,
+	}, nil
+}
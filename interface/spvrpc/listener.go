@@ -0,0 +1,124 @@
+package spvrpc
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/interface"
+	"github.com/elastos/Elastos.ELA.SPV/interface/spvrpc/pb"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+)
+
+// Ensure listener implement the listener interfaces.
+var (
+	_ _interface.OptionsListener    = (*listener)(nil)
+	_ _interface.RevocationListener = (*listener)(nil)
+	_ _interface.DropListener       = (*listener)(nil)
+)
+
+// listener is a listener registered by a gRPC client, the notifications are
+// sent to the Notify stream attached to it.  Notifications are dropped if no
+// stream is attached or the stream is full, transactions not acknowledged
+// are sent again by the SPV service.
+type listener struct {
+	address string
+	txType  types.TxType
+	flags   uint64
+	opts    *_interface.ListenerOptions
+
+	mtx    sync.Mutex
+	stream chan<- *pb.Notification
+}
+
+func (l *listener) Address() string {
+	return l.address
+}
+
+func (l *listener) Type() types.TxType {
+	return l.txType
+}
+
+func (l *listener) Flags() uint64 {
+	return l.flags
+}
+
+func (l *listener) Options() *_interface.ListenerOptions {
+	return l.opts
+}
+
+// attach attaches the stream to the listener, it returns false if another
+// stream is attached.
+func (l *listener) attach(stream chan<- *pb.Notification) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.stream != nil {
+		return false
+	}
+	l.stream = stream
+	return true
+}
+
+func (l *listener) detach() {
+	l.mtx.Lock()
+	l.stream = nil
+	l.mtx.Unlock()
+}
+
+func (l *listener) send(n *pb.Notification) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if l.stream == nil {
+		return
+	}
+	select {
+	case l.stream <- n:
+	default:
+	}
+}
+
+func (l *listener) Notify(notifyId common.Uint256, proof bloom.MerkleProof,
+	tx types.Transaction) {
+
+	txBuf := new(bytes.Buffer)
+	if err := tx.Serialize(txBuf); err != nil {
+		return
+	}
+	proofBuf := new(bytes.Buffer)
+	if err := proof.Serialize(proofBuf); err != nil {
+		return
+	}
+	txId := tx.Hash()
+	l.send(&pb.Notification{
+		Type:     pb.Notification_TRANSACTION,
+		NotifyId: notifyId[:],
+		TxId:     txId[:],
+		Tx:       txBuf.Bytes(),
+		Proof:    proofBuf.Bytes(),
+		Height:   proof.Height,
+	})
+}
+
+func (l *listener) Revoked(notifyId common.Uint256, txId common.Uint256,
+	height uint32) {
+
+	l.send(&pb.Notification{
+		Type:     pb.Notification_REVOKED,
+		NotifyId: notifyId[:],
+		TxId:     txId[:],
+		Height:   height,
+	})
+}
+
+func (l *listener) Dropped(notifyId common.Uint256, txId common.Uint256,
+	reason _interface.DropReason) {
+
+	l.send(&pb.Notification{
+		Type:       pb.Notification_DROPPED,
+		NotifyId:   notifyId[:],
+		TxId:       txId[:],
+		DropReason: uint32(reason),
+	})
+}
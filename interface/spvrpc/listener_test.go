@@ -0,0 +1,49 @@
+package spvrpc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/interface/spvrpc/pb"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenerStream(t *testing.T) {
+	l := &listener{txType: types.TransferAsset}
+	tx := types.Transaction{TxType: types.TransferAsset,
+		Payload: &payload.TransferAsset{}}
+	proof := bloom.MerkleProof{Height: 100, Transactions: 1}
+	var notifyId common.Uint256
+	notifyId[0] = 1
+
+	// Notifications are dropped without a stream attached.
+	l.Notify(notifyId, proof, tx)
+
+	stream := make(chan *pb.Notification, 1)
+	assert.True(t, l.attach(stream))
+	assert.False(t, l.attach(make(chan *pb.Notification)))
+
+	l.Notify(notifyId, proof, tx)
+	// The stream is full, the notification is dropped.
+	l.Revoked(notifyId, tx.Hash(), 100)
+	n := <-stream
+	assert.Equal(t, pb.Notification_TRANSACTION, n.Type)
+	assert.Equal(t, notifyId[:], n.NotifyId)
+	assert.Equal(t, uint32(100), n.Height)
+	var got types.Transaction
+	if !assert.NoError(t, got.Deserialize(bytes.NewReader(n.Tx))) {
+		t.FailNow()
+	}
+	assert.Equal(t, tx.Hash(), got.Hash())
+	assert.Equal(t, 0, len(stream))
+
+	l.detach()
+	l.Revoked(notifyId, tx.Hash(), 100)
+	assert.Equal(t, 0, len(stream))
+	assert.True(t, l.attach(stream))
+}
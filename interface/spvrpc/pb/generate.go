@@ -0,0 +1,5 @@
+// Package pb contains the protocol buffers and gRPC code generated from
+// spv.proto.
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative spv.proto
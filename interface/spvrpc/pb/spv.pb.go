@@ -0,0 +1,1025 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: spv.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Notification_Type int32
+
+const (
+	Notification_TRANSACTION Notification_Type = 0
+	Notification_REVOKED     Notification_Type = 1
+	Notification_DROPPED     Notification_Type = 2
+)
+
+// Enum value maps for Notification_Type.
+var (
+	Notification_Type_name = map[int32]string{
+		0: "TRANSACTION",
+		1: "REVOKED",
+		2: "DROPPED",
+	}
+	Notification_Type_value = map[string]int32{
+		"TRANSACTION": 0,
+		"REVOKED":     1,
+		"DROPPED":     2,
+	}
+)
+
+func (x Notification_Type) Enum() *Notification_Type {
+	p := new(Notification_Type)
+	*p = x
+	return p
+}
+
+func (x Notification_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Notification_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_spv_proto_enumTypes[0].Descriptor()
+}
+
+func (Notification_Type) Type() protoreflect.EnumType {
+	return &file_spv_proto_enumTypes[0]
+}
+
+func (x Notification_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Notification_Type.Descriptor instead.
+func (Notification_Type) EnumDescriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{5, 0}
+}
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_spv_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{0}
+}
+
+type RegisterListenerRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Address string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// type is the transaction type of the listener.
+	Type  uint32 `protobuf:"varint,2,opt,name=type,proto3" json:"type,omitempty"`
+	Flags uint64 `protobuf:"varint,3,opt,name=flags,proto3" json:"flags,omitempty"`
+	// types extends the listener to multiple transaction types.
+	Types    []uint32 `protobuf:"varint,4,rep,packed,name=types,proto3" json:"types,omitempty"`
+	AllTypes bool     `protobuf:"varint,5,opt,name=all_types,json=allTypes,proto3" json:"all_types,omitempty"`
+	// confirmations required with FlagNotifyConfirmed, zero uses default.
+	Confirmations uint32 `protobuf:"varint,6,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	// asset_id notifies only outputs of the asset if not empty.
+	AssetId []byte `protobuf:"bytes,7,opt,name=asset_id,json=assetId,proto3" json:"asset_id,omitempty"`
+	// min_amount notifies only transactions paid at least the amount of
+	// asset_id, or of ELA if asset_id is empty.
+	MinAmount     int64 `protobuf:"varint,8,opt,name=min_amount,json=minAmount,proto3" json:"min_amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterListenerRequest) Reset() {
+	*x = RegisterListenerRequest{}
+	mi := &file_spv_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterListenerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterListenerRequest) ProtoMessage() {}
+
+func (x *RegisterListenerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterListenerRequest.ProtoReflect.Descriptor instead.
+func (*RegisterListenerRequest) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegisterListenerRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *RegisterListenerRequest) GetType() uint32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *RegisterListenerRequest) GetFlags() uint64 {
+	if x != nil {
+		return x.Flags
+	}
+	return 0
+}
+
+func (x *RegisterListenerRequest) GetTypes() []uint32 {
+	if x != nil {
+		return x.Types
+	}
+	return nil
+}
+
+func (x *RegisterListenerRequest) GetAllTypes() bool {
+	if x != nil {
+		return x.AllTypes
+	}
+	return false
+}
+
+func (x *RegisterListenerRequest) GetConfirmations() uint32 {
+	if x != nil {
+		return x.Confirmations
+	}
+	return 0
+}
+
+func (x *RegisterListenerRequest) GetAssetId() []byte {
+	if x != nil {
+		return x.AssetId
+	}
+	return nil
+}
+
+func (x *RegisterListenerRequest) GetMinAmount() int64 {
+	if x != nil {
+		return x.MinAmount
+	}
+	return 0
+}
+
+type RegisterListenerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NotifyId      []byte                 `protobuf:"bytes,1,opt,name=notify_id,json=notifyId,proto3" json:"notify_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterListenerResponse) Reset() {
+	*x = RegisterListenerResponse{}
+	mi := &file_spv_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterListenerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterListenerResponse) ProtoMessage() {}
+
+func (x *RegisterListenerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterListenerResponse.ProtoReflect.Descriptor instead.
+func (*RegisterListenerResponse) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RegisterListenerResponse) GetNotifyId() []byte {
+	if x != nil {
+		return x.NotifyId
+	}
+	return nil
+}
+
+type UnregisterListenerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NotifyId      []byte                 `protobuf:"bytes,1,opt,name=notify_id,json=notifyId,proto3" json:"notify_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnregisterListenerRequest) Reset() {
+	*x = UnregisterListenerRequest{}
+	mi := &file_spv_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnregisterListenerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterListenerRequest) ProtoMessage() {}
+
+func (x *UnregisterListenerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnregisterListenerRequest.ProtoReflect.Descriptor instead.
+func (*UnregisterListenerRequest) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *UnregisterListenerRequest) GetNotifyId() []byte {
+	if x != nil {
+		return x.NotifyId
+	}
+	return nil
+}
+
+type NotifyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// notify_id attaches the stream to the listener, set by the first request.
+	NotifyId []byte `protobuf:"bytes,1,opt,name=notify_id,json=notifyId,proto3" json:"notify_id,omitempty"`
+	// ack_tx_id submits the receipt of the notified transaction.
+	AckTxId       []byte `protobuf:"bytes,2,opt,name=ack_tx_id,json=ackTxId,proto3" json:"ack_tx_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotifyRequest) Reset() {
+	*x = NotifyRequest{}
+	mi := &file_spv_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotifyRequest) ProtoMessage() {}
+
+func (x *NotifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotifyRequest.ProtoReflect.Descriptor instead.
+func (*NotifyRequest) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *NotifyRequest) GetNotifyId() []byte {
+	if x != nil {
+		return x.NotifyId
+	}
+	return nil
+}
+
+func (x *NotifyRequest) GetAckTxId() []byte {
+	if x != nil {
+		return x.AckTxId
+	}
+	return nil
+}
+
+type Notification struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Type     Notification_Type      `protobuf:"varint,1,opt,name=type,proto3,enum=spvrpc.Notification_Type" json:"type,omitempty"`
+	NotifyId []byte                 `protobuf:"bytes,2,opt,name=notify_id,json=notifyId,proto3" json:"notify_id,omitempty"`
+	TxId     []byte                 `protobuf:"bytes,3,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"`
+	// tx is the transaction of a TRANSACTION notification.
+	Tx []byte `protobuf:"bytes,4,opt,name=tx,proto3" json:"tx,omitempty"`
+	// proof is the merkle proof of a TRANSACTION notification.
+	Proof  []byte `protobuf:"bytes,5,opt,name=proof,proto3" json:"proof,omitempty"`
+	Height uint32 `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`
+	// drop_reason is the reason of a DROPPED notification.
+	DropReason    uint32 `protobuf:"varint,7,opt,name=drop_reason,json=dropReason,proto3" json:"drop_reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Notification) Reset() {
+	*x = Notification{}
+	mi := &file_spv_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Notification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Notification) ProtoMessage() {}
+
+func (x *Notification) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Notification.ProtoReflect.Descriptor instead.
+func (*Notification) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Notification) GetType() Notification_Type {
+	if x != nil {
+		return x.Type
+	}
+	return Notification_TRANSACTION
+}
+
+func (x *Notification) GetNotifyId() []byte {
+	if x != nil {
+		return x.NotifyId
+	}
+	return nil
+}
+
+func (x *Notification) GetTxId() []byte {
+	if x != nil {
+		return x.TxId
+	}
+	return nil
+}
+
+func (x *Notification) GetTx() []byte {
+	if x != nil {
+		return x.Tx
+	}
+	return nil
+}
+
+func (x *Notification) GetProof() []byte {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *Notification) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Notification) GetDropReason() uint32 {
+	if x != nil {
+		return x.DropReason
+	}
+	return 0
+}
+
+type VerifyTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Proof         []byte                 `protobuf:"bytes,1,opt,name=proof,proto3" json:"proof,omitempty"`
+	Tx            []byte                 `protobuf:"bytes,2,opt,name=tx,proto3" json:"tx,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VerifyTransactionRequest) Reset() {
+	*x = VerifyTransactionRequest{}
+	mi := &file_spv_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VerifyTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyTransactionRequest) ProtoMessage() {}
+
+func (x *VerifyTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyTransactionRequest.ProtoReflect.Descriptor instead.
+func (*VerifyTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *VerifyTransactionRequest) GetProof() []byte {
+	if x != nil {
+		return x.Proof
+	}
+	return nil
+}
+
+func (x *VerifyTransactionRequest) GetTx() []byte {
+	if x != nil {
+		return x.Tx
+	}
+	return nil
+}
+
+type SendTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tx            []byte                 `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendTransactionRequest) Reset() {
+	*x = SendTransactionRequest{}
+	mi := &file_spv_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendTransactionRequest) ProtoMessage() {}
+
+func (x *SendTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendTransactionRequest.ProtoReflect.Descriptor instead.
+func (*SendTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SendTransactionRequest) GetTx() []byte {
+	if x != nil {
+		return x.Tx
+	}
+	return nil
+}
+
+type SendTransactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TxId          []byte                 `protobuf:"bytes,1,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendTransactionResponse) Reset() {
+	*x = SendTransactionResponse{}
+	mi := &file_spv_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendTransactionResponse) ProtoMessage() {}
+
+func (x *SendTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendTransactionResponse.ProtoReflect.Descriptor instead.
+func (*SendTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SendTransactionResponse) GetTxId() []byte {
+	if x != nil {
+		return x.TxId
+	}
+	return nil
+}
+
+type GetTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TxId          []byte                 `protobuf:"bytes,1,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionRequest) Reset() {
+	*x = GetTransactionRequest{}
+	mi := &file_spv_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionRequest) ProtoMessage() {}
+
+func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetTransactionRequest) GetTxId() []byte {
+	if x != nil {
+		return x.TxId
+	}
+	return nil
+}
+
+type GetTransactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tx            []byte                 `protobuf:"bytes,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionResponse) Reset() {
+	*x = GetTransactionResponse{}
+	mi := &file_spv_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionResponse) ProtoMessage() {}
+
+func (x *GetTransactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionResponse.ProtoReflect.Descriptor instead.
+func (*GetTransactionResponse) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetTransactionResponse) GetTx() []byte {
+	if x != nil {
+		return x.Tx
+	}
+	return nil
+}
+
+type GetHeaderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Height        uint32                 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Hash          []byte                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHeaderRequest) Reset() {
+	*x = GetHeaderRequest{}
+	mi := &file_spv_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHeaderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHeaderRequest) ProtoMessage() {}
+
+func (x *GetHeaderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHeaderRequest.ProtoReflect.Descriptor instead.
+func (*GetHeaderRequest) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetHeaderRequest) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *GetHeaderRequest) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+type Header struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Hash      []byte                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Height    uint32                 `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	Previous  []byte                 `protobuf:"bytes,3,opt,name=previous,proto3" json:"previous,omitempty"`
+	Timestamp uint32                 `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// header is the block header.
+	Header        []byte `protobuf:"bytes,5,opt,name=header,proto3" json:"header,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Header) Reset() {
+	*x = Header{}
+	mi := &file_spv_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Header) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Header) ProtoMessage() {}
+
+func (x *Header) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Header.ProtoReflect.Descriptor instead.
+func (*Header) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Header) GetHash() []byte {
+	if x != nil {
+		return x.Hash
+	}
+	return nil
+}
+
+func (x *Header) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Header) GetPrevious() []byte {
+	if x != nil {
+		return x.Previous
+	}
+	return nil
+}
+
+func (x *Header) GetTimestamp() uint32 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Header) GetHeader() []byte {
+	if x != nil {
+		return x.Header
+	}
+	return nil
+}
+
+type SyncStatus struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	BestHeight uint32                 `protobuf:"varint,1,opt,name=best_height,json=bestHeight,proto3" json:"best_height,omitempty"`
+	BestHash   []byte                 `protobuf:"bytes,2,opt,name=best_hash,json=bestHash,proto3" json:"best_hash,omitempty"`
+	// current indicates the service believes it is synced with peers.
+	Current       bool `protobuf:"varint,3,opt,name=current,proto3" json:"current,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SyncStatus) Reset() {
+	*x = SyncStatus{}
+	mi := &file_spv_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SyncStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SyncStatus) ProtoMessage() {}
+
+func (x *SyncStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_spv_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SyncStatus.ProtoReflect.Descriptor instead.
+func (*SyncStatus) Descriptor() ([]byte, []int) {
+	return file_spv_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SyncStatus) GetBestHeight() uint32 {
+	if x != nil {
+		return x.BestHeight
+	}
+	return 0
+}
+
+func (x *SyncStatus) GetBestHash() []byte {
+	if x != nil {
+		return x.BestHash
+	}
+	return nil
+}
+
+func (x *SyncStatus) GetCurrent() bool {
+	if x != nil {
+		return x.Current
+	}
+	return false
+}
+
+var File_spv_proto protoreflect.FileDescriptor
+
+const file_spv_proto_rawDesc = "" +
+	"\n" +
+	"\tspv.proto\x12\x06spvrpc\"\a\n" +
+	"\x05Empty\"\x83\x02\n" +
+	"\x17RegisterListenerRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\rR\x04type\x12\x14\n" +
+	"\x05flags\x18\x03 \x01(\x04R\x05flags\x12\x14\n" +
+	"\x05types\x18\x04 \x03(\rR\x05types\x12\x1b\n" +
+	"\tall_types\x18\x05 \x01(\bR\ballTypes\x12$\n" +
+	"\rconfirmations\x18\x06 \x01(\rR\rconfirmations\x12\x19\n" +
+	"\basset_id\x18\a \x01(\fR\aassetId\x12\x1d\n" +
+	"\n" +
+	"min_amount\x18\b \x01(\x03R\tminAmountJ\x04\b\t\x10\n" +
+	"R\vfrom_height\"7\n" +
+	"\x18RegisterListenerResponse\x12\x1b\n" +
+	"\tnotify_id\x18\x01 \x01(\fR\bnotifyId\"8\n" +
+	"\x19UnregisterListenerRequest\x12\x1b\n" +
+	"\tnotify_id\x18\x01 \x01(\fR\bnotifyId\"H\n" +
+	"\rNotifyRequest\x12\x1b\n" +
+	"\tnotify_id\x18\x01 \x01(\fR\bnotifyId\x12\x1a\n" +
+	"\tack_tx_id\x18\x02 \x01(\fR\aackTxId\"\x81\x02\n" +
+	"\fNotification\x12-\n" +
+	"\x04type\x18\x01 \x01(\x0e2\x19.spvrpc.Notification.TypeR\x04type\x12\x1b\n" +
+	"\tnotify_id\x18\x02 \x01(\fR\bnotifyId\x12\x13\n" +
+	"\x05tx_id\x18\x03 \x01(\fR\x04txId\x12\x0e\n" +
+	"\x02tx\x18\x04 \x01(\fR\x02tx\x12\x14\n" +
+	"\x05proof\x18\x05 \x01(\fR\x05proof\x12\x16\n" +
+	"\x06height\x18\x06 \x01(\rR\x06height\x12\x1f\n" +
+	"\vdrop_reason\x18\a \x01(\rR\n" +
+	"dropReason\"1\n" +
+	"\x04Type\x12\x0f\n" +
+	"\vTRANSACTION\x10\x00\x12\v\n" +
+	"\aREVOKED\x10\x01\x12\v\n" +
+	"\aDROPPED\x10\x02\"@\n" +
+	"\x18VerifyTransactionRequest\x12\x14\n" +
+	"\x05proof\x18\x01 \x01(\fR\x05proof\x12\x0e\n" +
+	"\x02tx\x18\x02 \x01(\fR\x02tx\"(\n" +
+	"\x16SendTransactionRequest\x12\x0e\n" +
+	"\x02tx\x18\x01 \x01(\fR\x02tx\".\n" +
+	"\x17SendTransactionResponse\x12\x13\n" +
+	"\x05tx_id\x18\x01 \x01(\fR\x04txId\",\n" +
+	"\x15GetTransactionRequest\x12\x13\n" +
+	"\x05tx_id\x18\x01 \x01(\fR\x04txId\"(\n" +
+	"\x16GetTransactionResponse\x12\x0e\n" +
+	"\x02tx\x18\x01 \x01(\fR\x02tx\">\n" +
+	"\x10GetHeaderRequest\x12\x16\n" +
+	"\x06height\x18\x01 \x01(\rR\x06height\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\fR\x04hash\"\x86\x01\n" +
+	"\x06Header\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\fR\x04hash\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\rR\x06height\x12\x1a\n" +
+	"\bprevious\x18\x03 \x01(\fR\bprevious\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\rR\ttimestamp\x12\x16\n" +
+	"\x06header\x18\x05 \x01(\fR\x06header\"d\n" +
+	"\n" +
+	"SyncStatus\x12\x1f\n" +
+	"\vbest_height\x18\x01 \x01(\rR\n" +
+	"bestHeight\x12\x1b\n" +
+	"\tbest_hash\x18\x02 \x01(\fR\bbestHash\x12\x18\n" +
+	"\acurrent\x18\x03 \x01(\bR\acurrent2\xe5\x04\n" +
+	"\x03SPV\x12U\n" +
+	"\x10RegisterListener\x12\x1f.spvrpc.RegisterListenerRequest\x1a .spvrpc.RegisterListenerResponse\x12F\n" +
+	"\x12UnregisterListener\x12!.spvrpc.UnregisterListenerRequest\x1a\r.spvrpc.Empty\x129\n" +
+	"\x06Notify\x12\x15.spvrpc.NotifyRequest\x1a\x14.spvrpc.Notification(\x010\x01\x12D\n" +
+	"\x11VerifyTransaction\x12 .spvrpc.VerifyTransactionRequest\x1a\r.spvrpc.Empty\x12R\n" +
+	"\x0fSendTransaction\x12\x1e.spvrpc.SendTransactionRequest\x1a\x1f.spvrpc.SendTransactionResponse\x12O\n" +
+	"\x0eGetTransaction\x12\x1d.spvrpc.GetTransactionRequest\x1a\x1e.spvrpc.GetTransactionResponse\x125\n" +
+	"\tGetHeader\x12\x18.spvrpc.GetHeaderRequest\x1a\x0e.spvrpc.Header\x12.\n" +
+	"\rGetBestHeader\x12\r.spvrpc.Empty\x1a\x0e.spvrpc.Header\x122\n" +
+	"\rGetSyncStatus\x12\r.spvrpc.Empty\x1a\x12.spvrpc.SyncStatusB8Z6github.com/elastos/Elastos.ELA.SPV/interface/spvrpc/pbb\x06proto3"
+
+var (
+	file_spv_proto_rawDescOnce sync.Once
+	file_spv_proto_rawDescData []byte
+)
+
+func file_spv_proto_rawDescGZIP() []byte {
+	file_spv_proto_rawDescOnce.Do(func() {
+		file_spv_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_spv_proto_rawDesc), len(file_spv_proto_rawDesc)))
+	})
+	return file_spv_proto_rawDescData
+}
+
+var file_spv_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_spv_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_spv_proto_goTypes = []any{
+	(Notification_Type)(0),            // 0: spvrpc.Notification.Type
+	(*Empty)(nil),                     // 1: spvrpc.Empty
+	(*RegisterListenerRequest)(nil),   // 2: spvrpc.RegisterListenerRequest
+	(*RegisterListenerResponse)(nil),  // 3: spvrpc.RegisterListenerResponse
+	(*UnregisterListenerRequest)(nil), // 4: spvrpc.UnregisterListenerRequest
+	(*NotifyRequest)(nil),             // 5: spvrpc.NotifyRequest
+	(*Notification)(nil),              // 6: spvrpc.Notification
+	(*VerifyTransactionRequest)(nil),  // 7: spvrpc.VerifyTransactionRequest
+	(*SendTransactionRequest)(nil),    // 8: spvrpc.SendTransactionRequest
+	(*SendTransactionResponse)(nil),   // 9: spvrpc.SendTransactionResponse
+	(*GetTransactionRequest)(nil),     // 10: spvrpc.GetTransactionRequest
+	(*GetTransactionResponse)(nil),    // 11: spvrpc.GetTransactionResponse
+	(*GetHeaderRequest)(nil),          // 12: spvrpc.GetHeaderRequest
+	(*Header)(nil),                    // 13: spvrpc.Header
+	(*SyncStatus)(nil),                // 14: spvrpc.SyncStatus
+}
+var file_spv_proto_depIdxs = []int32{
+	0,  // 0: spvrpc.Notification.type:type_name -> spvrpc.Notification.Type
+	2,  // 1: spvrpc.SPV.RegisterListener:input_type -> spvrpc.RegisterListenerRequest
+	4,  // 2: spvrpc.SPV.UnregisterListener:input_type -> spvrpc.UnregisterListenerRequest
+	5,  // 3: spvrpc.SPV.Notify:input_type -> spvrpc.NotifyRequest
+	7,  // 4: spvrpc.SPV.VerifyTransaction:input_type -> spvrpc.VerifyTransactionRequest
+	8,  // 5: spvrpc.SPV.SendTransaction:input_type -> spvrpc.SendTransactionRequest
+	10, // 6: spvrpc.SPV.GetTransaction:input_type -> spvrpc.GetTransactionRequest
+	12, // 7: spvrpc.SPV.GetHeader:input_type -> spvrpc.GetHeaderRequest
+	1,  // 8: spvrpc.SPV.GetBestHeader:input_type -> spvrpc.Empty
+	1,  // 9: spvrpc.SPV.GetSyncStatus:input_type -> spvrpc.Empty
+	3,  // 10: spvrpc.SPV.RegisterListener:output_type -> spvrpc.RegisterListenerResponse
+	1,  // 11: spvrpc.SPV.UnregisterListener:output_type -> spvrpc.Empty
+	6,  // 12: spvrpc.SPV.Notify:output_type -> spvrpc.Notification
+	1,  // 13: spvrpc.SPV.VerifyTransaction:output_type -> spvrpc.Empty
+	9,  // 14: spvrpc.SPV.SendTransaction:output_type -> spvrpc.SendTransactionResponse
+	11, // 15: spvrpc.SPV.GetTransaction:output_type -> spvrpc.GetTransactionResponse
+	13, // 16: spvrpc.SPV.GetHeader:output_type -> spvrpc.Header
+	13, // 17: spvrpc.SPV.GetBestHeader:output_type -> spvrpc.Header
+	14, // 18: spvrpc.SPV.GetSyncStatus:output_type -> spvrpc.SyncStatus
+	10, // [10:19] is the sub-list for method output_type
+	1,  // [1:10] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_spv_proto_init() }
+func file_spv_proto_init() {
+	if File_spv_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_spv_proto_rawDesc), len(file_spv_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_spv_proto_goTypes,
+		DependencyIndexes: file_spv_proto_depIdxs,
+		EnumInfos:         file_spv_proto_enumTypes,
+		MessageInfos:      file_spv_proto_msgTypes,
+	}.Build()
+	File_spv_proto = out.File
+	file_spv_proto_goTypes = nil
+	file_spv_proto_depIdxs = nil
+}
@@ -0,0 +1,458 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: spv.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SPV_RegisterListener_FullMethodName   = "/spvrpc.SPV/RegisterListener"
+	SPV_UnregisterListener_FullMethodName = "/spvrpc.SPV/UnregisterListener"
+	SPV_Notify_FullMethodName             = "/spvrpc.SPV/Notify"
+	SPV_VerifyTransaction_FullMethodName  = "/spvrpc.SPV/VerifyTransaction"
+	SPV_SendTransaction_FullMethodName    = "/spvrpc.SPV/SendTransaction"
+	SPV_GetTransaction_FullMethodName     = "/spvrpc.SPV/GetTransaction"
+	SPV_GetHeader_FullMethodName          = "/spvrpc.SPV/GetHeader"
+	SPV_GetBestHeader_FullMethodName      = "/spvrpc.SPV/GetBestHeader"
+	SPV_GetSyncStatus_FullMethodName      = "/spvrpc.SPV/GetSyncStatus"
+)
+
+// SPVClient is the client API for SPV service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SPV exposes the SPV service to consumers out of process.  Hashes are the
+// 32 bytes of common.Uint256, transactions, proofs and headers are in the
+// ELA serialization format.
+type SPVClient interface {
+	// RegisterListener registers a listener of the address and returns it's
+	// notify id, notifications are received by the Notify stream.
+	RegisterListener(ctx context.Context, in *RegisterListenerRequest, opts ...grpc.CallOption) (*RegisterListenerResponse, error)
+	// UnregisterListener removes the listener and purges it's queued
+	// notifications.
+	UnregisterListener(ctx context.Context, in *UnregisterListenerRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Notify streams the notifications of a listener.  The first request must
+	// set the notify id, the following requests acknowledge the received
+	// transactions, transactions not acknowledged are sent again.
+	Notify(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[NotifyRequest, Notification], error)
+	// VerifyTransaction verifies the transaction is included in the merkle
+	// proof of a block in the main chain.
+	VerifyTransaction(ctx context.Context, in *VerifyTransactionRequest, opts ...grpc.CallOption) (*Empty, error)
+	// SendTransaction sends the transaction to the P2P network.
+	SendTransaction(ctx context.Context, in *SendTransactionRequest, opts ...grpc.CallOption) (*SendTransactionResponse, error)
+	// GetTransaction returns the stored transaction of the id.
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error)
+	// GetHeader returns the header of the hash, or the main chain header of
+	// the height if hash is empty.
+	GetHeader(ctx context.Context, in *GetHeaderRequest, opts ...grpc.CallOption) (*Header, error)
+	// GetBestHeader returns the header on the chain tip.
+	GetBestHeader(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Header, error)
+	// GetSyncStatus returns the sync status of the SPV service.
+	GetSyncStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SyncStatus, error)
+}
+
+type sPVClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSPVClient(cc grpc.ClientConnInterface) SPVClient {
+	return &sPVClient{cc}
+}
+
+func (c *sPVClient) RegisterListener(ctx context.Context, in *RegisterListenerRequest, opts ...grpc.CallOption) (*RegisterListenerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterListenerResponse)
+	err := c.cc.Invoke(ctx, SPV_RegisterListener_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sPVClient) UnregisterListener(ctx context.Context, in *UnregisterListenerRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SPV_UnregisterListener_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sPVClient) Notify(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[NotifyRequest, Notification], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SPV_ServiceDesc.Streams[0], SPV_Notify_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[NotifyRequest, Notification]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SPV_NotifyClient = grpc.BidiStreamingClient[NotifyRequest, Notification]
+
+func (c *sPVClient) VerifyTransaction(ctx context.Context, in *VerifyTransactionRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, SPV_VerifyTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sPVClient) SendTransaction(ctx context.Context, in *SendTransactionRequest, opts ...grpc.CallOption) (*SendTransactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SendTransactionResponse)
+	err := c.cc.Invoke(ctx, SPV_SendTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sPVClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*GetTransactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTransactionResponse)
+	err := c.cc.Invoke(ctx, SPV_GetTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sPVClient) GetHeader(ctx context.Context, in *GetHeaderRequest, opts ...grpc.CallOption) (*Header, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Header)
+	err := c.cc.Invoke(ctx, SPV_GetHeader_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sPVClient) GetBestHeader(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Header, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Header)
+	err := c.cc.Invoke(ctx, SPV_GetBestHeader_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sPVClient) GetSyncStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*SyncStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncStatus)
+	err := c.cc.Invoke(ctx, SPV_GetSyncStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SPVServer is the server API for SPV service.
+// All implementations must embed UnimplementedSPVServer
+// for forward compatibility.
+//
+// SPV exposes the SPV service to consumers out of process.  Hashes are the
+// 32 bytes of common.Uint256, transactions, proofs and headers are in the
+// ELA serialization format.
+type SPVServer interface {
+	// RegisterListener registers a listener of the address and returns it's
+	// notify id, notifications are received by the Notify stream.
+	RegisterListener(context.Context, *RegisterListenerRequest) (*RegisterListenerResponse, error)
+	// UnregisterListener removes the listener and purges it's queued
+	// notifications.
+	UnregisterListener(context.Context, *UnregisterListenerRequest) (*Empty, error)
+	// Notify streams the notifications of a listener.  The first request must
+	// set the notify id, the following requests acknowledge the received
+	// transactions, transactions not acknowledged are sent again.
+	Notify(grpc.BidiStreamingServer[NotifyRequest, Notification]) error
+	// VerifyTransaction verifies the transaction is included in the merkle
+	// proof of a block in the main chain.
+	VerifyTransaction(context.Context, *VerifyTransactionRequest) (*Empty, error)
+	// SendTransaction sends the transaction to the P2P network.
+	SendTransaction(context.Context, *SendTransactionRequest) (*SendTransactionResponse, error)
+	// GetTransaction returns the stored transaction of the id.
+	GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error)
+	// GetHeader returns the header of the hash, or the main chain header of
+	// the height if hash is empty.
+	GetHeader(context.Context, *GetHeaderRequest) (*Header, error)
+	// GetBestHeader returns the header on the chain tip.
+	GetBestHeader(context.Context, *Empty) (*Header, error)
+	// GetSyncStatus returns the sync status of the SPV service.
+	GetSyncStatus(context.Context, *Empty) (*SyncStatus, error)
+	mustEmbedUnimplementedSPVServer()
+}
+
+// UnimplementedSPVServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSPVServer struct{}
+
+func (UnimplementedSPVServer) RegisterListener(context.Context, *RegisterListenerRequest) (*RegisterListenerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterListener not implemented")
+}
+func (UnimplementedSPVServer) UnregisterListener(context.Context, *UnregisterListenerRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnregisterListener not implemented")
+}
+func (UnimplementedSPVServer) Notify(grpc.BidiStreamingServer[NotifyRequest, Notification]) error {
+	return status.Errorf(codes.Unimplemented, "method Notify not implemented")
+}
+func (UnimplementedSPVServer) VerifyTransaction(context.Context, *VerifyTransactionRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyTransaction not implemented")
+}
+func (UnimplementedSPVServer) SendTransaction(context.Context, *SendTransactionRequest) (*SendTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendTransaction not implemented")
+}
+func (UnimplementedSPVServer) GetTransaction(context.Context, *GetTransactionRequest) (*GetTransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedSPVServer) GetHeader(context.Context, *GetHeaderRequest) (*Header, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHeader not implemented")
+}
+func (UnimplementedSPVServer) GetBestHeader(context.Context, *Empty) (*Header, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBestHeader not implemented")
+}
+func (UnimplementedSPVServer) GetSyncStatus(context.Context, *Empty) (*SyncStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSyncStatus not implemented")
+}
+func (UnimplementedSPVServer) mustEmbedUnimplementedSPVServer() {}
+func (UnimplementedSPVServer) testEmbeddedByValue()             {}
+
+// UnsafeSPVServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SPVServer will
+// result in compilation errors.
+type UnsafeSPVServer interface {
+	mustEmbedUnimplementedSPVServer()
+}
+
+func RegisterSPVServer(s grpc.ServiceRegistrar, srv SPVServer) {
+	// If the following call pancis, it indicates UnimplementedSPVServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SPV_ServiceDesc, srv)
+}
+
+func _SPV_RegisterListener_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterListenerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SPVServer).RegisterListener(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SPV_RegisterListener_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SPVServer).RegisterListener(ctx, req.(*RegisterListenerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SPV_UnregisterListener_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnregisterListenerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SPVServer).UnregisterListener(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SPV_UnregisterListener_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SPVServer).UnregisterListener(ctx, req.(*UnregisterListenerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SPV_Notify_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SPVServer).Notify(&grpc.GenericServerStream[NotifyRequest, Notification]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SPV_NotifyServer = grpc.BidiStreamingServer[NotifyRequest, Notification]
+
+func _SPV_VerifyTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SPVServer).VerifyTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SPV_VerifyTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SPVServer).VerifyTransaction(ctx, req.(*VerifyTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SPV_SendTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SPVServer).SendTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SPV_SendTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SPVServer).SendTransaction(ctx, req.(*SendTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SPV_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SPVServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SPV_GetTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SPVServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SPV_GetHeader_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHeaderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SPVServer).GetHeader(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SPV_GetHeader_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SPVServer).GetHeader(ctx, req.(*GetHeaderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SPV_GetBestHeader_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SPVServer).GetBestHeader(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SPV_GetBestHeader_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SPVServer).GetBestHeader(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SPV_GetSyncStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SPVServer).GetSyncStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SPV_GetSyncStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SPVServer).GetSyncStatus(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SPV_ServiceDesc is the grpc.ServiceDesc for SPV service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SPV_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "spvrpc.SPV",
+	HandlerType: (*SPVServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterListener",
+			Handler:    _SPV_RegisterListener_Handler,
+		},
+		{
+			MethodName: "UnregisterListener",
+			Handler:    _SPV_UnregisterListener_Handler,
+		},
+		{
+			MethodName: "VerifyTransaction",
+			Handler:    _SPV_VerifyTransaction_Handler,
+		},
+		{
+			MethodName: "SendTransaction",
+			Handler:    _SPV_SendTransaction_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _SPV_GetTransaction_Handler,
+		},
+		{
+			MethodName: "GetHeader",
+			Handler:    _SPV_GetHeader_Handler,
+		},
+		{
+			MethodName: "GetBestHeader",
+			Handler:    _SPV_GetBestHeader_Handler,
+		},
+		{
+			MethodName: "GetSyncStatus",
+			Handler:    _SPV_GetSyncStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Notify",
+			Handler:       _SPV_Notify_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "spv.proto",
+}
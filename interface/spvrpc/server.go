@@ -0,0 +1,319 @@
+package spvrpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/interface"
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA.SPV/interface/spvrpc/pb"
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// notifyBuffer is the buffer size of notifications waiting to be sent by a
+// Notify stream.
+const notifyBuffer = 64
+
+// Ensure Server implement pb.SPVServer interface.
+var _ pb.SPVServer = (*Server)(nil)
+
+// Server is the gRPC server of a SPV service.
+type Server struct {
+	pb.UnimplementedSPVServer
+	service _interface.SPVService
+
+	mtx       sync.Mutex
+	listeners map[common.Uint256]*listener
+}
+
+// NewServer creates a gRPC server of the SPV service.
+func NewServer(service _interface.SPVService) *Server {
+	return &Server{
+		service:   service,
+		listeners: make(map[common.Uint256]*listener),
+	}
+}
+
+// Register registers the server to the gRPC server.
+func (s *Server) Register(server *grpc.Server) {
+	pb.RegisterSPVServer(server, s)
+}
+
+// Serve creates a gRPC server with the options and serves the SPV service on
+// the listener until it returns an error.
+func (s *Server) Serve(lis net.Listener, opts ...grpc.ServerOption) error {
+	server := grpc.NewServer(opts...)
+	s.Register(server)
+	return server.Serve(lis)
+}
+
+func (s *Server) RegisterListener(ctx context.Context,
+	req *pb.RegisterListenerRequest) (*pb.RegisterListenerResponse, error) {
+
+	l := &listener{
+		address: req.Address,
+		txType:  types.TxType(req.Type),
+		flags:   req.Flags,
+	}
+	if len(req.Types) > 0 || req.AllTypes || req.Confirmations > 0 ||
+		len(req.AssetId) > 0 || req.MinAmount > 0 {
+		l.opts = &_interface.ListenerOptions{
+			AllTypes:      req.AllTypes,
+			Confirmations: req.Confirmations,
+			MinAmount:     common.Fixed64(req.MinAmount),
+		}
+		for _, txType := range req.Types {
+			l.opts.Types = append(l.opts.Types, types.TxType(txType))
+		}
+		if len(req.AssetId) > 0 {
+			assetID, err := common.Uint256FromBytes(req.AssetId)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument,
+					"invalid asset id, %s", err)
+			}
+			l.opts.AssetID = assetID
+		}
+	}
+
+	// Registering a listener again returns the registered one, so clients
+	// can register their listeners every time they connect.
+	notifyId := _interface.NotifyId(l)
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if _, ok := s.listeners[notifyId]; ok {
+		return &pb.RegisterListenerResponse{NotifyId: notifyId[:]}, nil
+	}
+
+	if err := s.service.RegisterTransactionListener(l); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	s.listeners[notifyId] = l
+	return &pb.RegisterListenerResponse{NotifyId: notifyId[:]}, nil
+}
+
+func (s *Server) UnregisterListener(ctx context.Context,
+	req *pb.UnregisterListenerRequest) (*pb.Empty, error) {
+
+	notifyId, err := common.Uint256FromBytes(req.NotifyId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"invalid notify id, %s", err)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	l, ok := s.listeners[*notifyId]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound,
+			"listener %s not registered", notifyId)
+	}
+	if err := s.service.UnregisterTransactionListener(l); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	delete(s.listeners, *notifyId)
+	return &pb.Empty{}, nil
+}
+
+func (s *Server) Notify(stream pb.SPV_NotifyServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	notifyId, err := common.Uint256FromBytes(req.NotifyId)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument,
+			"invalid notify id, %s", err)
+	}
+	s.mtx.Lock()
+	l, ok := s.listeners[*notifyId]
+	s.mtx.Unlock()
+	if !ok {
+		return status.Errorf(codes.NotFound,
+			"listener %s not registered", notifyId)
+	}
+
+	notifications := make(chan *pb.Notification, notifyBuffer)
+	if !l.attach(notifications) {
+		return status.Errorf(codes.FailedPrecondition,
+			"listener %s is attached to another stream", notifyId)
+	}
+	defer l.detach()
+
+	// Submit the receipts of acknowledged transactions.
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				errs <- err
+				return
+			}
+			txId, err := common.Uint256FromBytes(req.AckTxId)
+			if err != nil {
+				errs <- status.Errorf(codes.InvalidArgument,
+					"invalid ack transaction id, %s", err)
+				return
+			}
+			// A transaction acknowledged again is not in the notify queue
+			// any more, the duplicate ack is ignored.
+			err = s.service.SubmitTransactionReceipt(*notifyId, *txId)
+			if err != nil && err != kvdb.ErrNotFound {
+				errs <- status.Error(codes.Internal, err.Error())
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case n := <-notifications:
+			if err := stream.Send(n); err != nil {
+				return err
+			}
+
+		case err := <-errs:
+			if err == io.EOF {
+				return nil
+			}
+			return err
+
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func (s *Server) VerifyTransaction(ctx context.Context,
+	req *pb.VerifyTransactionRequest) (*pb.Empty, error) {
+
+	var proof bloom.MerkleProof
+	if err := proof.Deserialize(bytes.NewReader(req.Proof)); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"invalid merkle proof, %s", err)
+	}
+	var tx types.Transaction
+	if err := tx.Deserialize(bytes.NewReader(req.Tx)); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"invalid transaction, %s", err)
+	}
+	if err := s.service.VerifyTransaction(proof, tx); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *Server) SendTransaction(ctx context.Context,
+	req *pb.SendTransactionRequest) (*pb.SendTransactionResponse, error) {
+
+	var tx types.Transaction
+	if err := tx.Deserialize(bytes.NewReader(req.Tx)); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"invalid transaction, %s", err)
+	}
+	if err := s.service.SendTransaction(tx); err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	txId := tx.Hash()
+	return &pb.SendTransactionResponse{TxId: txId[:]}, nil
+}
+
+func (s *Server) GetTransaction(ctx context.Context,
+	req *pb.GetTransactionRequest) (*pb.GetTransactionResponse, error) {
+
+	txId, err := common.Uint256FromBytes(req.TxId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument,
+			"invalid transaction id, %s", err)
+	}
+	tx, err := s.service.GetTransaction(txId)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound,
+			"transaction %s not found", txId)
+	}
+	buf := new(bytes.Buffer)
+	if err := tx.Serialize(buf); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.GetTransactionResponse{Tx: buf.Bytes()}, nil
+}
+
+func (s *Server) GetHeader(ctx context.Context,
+	req *pb.GetHeaderRequest) (*pb.Header, error) {
+
+	var header *util.Header
+	if len(req.Hash) > 0 {
+		hash, err := common.Uint256FromBytes(req.Hash)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument,
+				"invalid header hash, %s", err)
+		}
+		header, err = s.service.HeaderStore().Get(hash)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound,
+				"header %s not found", hash)
+		}
+	} else {
+		var err error
+		header, err = s.service.HeaderStore().GetByHeight(req.Height)
+		if err != nil {
+			return nil, status.Errorf(codes.NotFound,
+				"header on height %d not found", req.Height)
+		}
+	}
+	return toHeader(header)
+}
+
+func (s *Server) GetBestHeader(ctx context.Context,
+	req *pb.Empty) (*pb.Header, error) {
+
+	header, err := s.service.HeaderStore().GetBest()
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return toHeader(header)
+}
+
+func (s *Server) GetSyncStatus(ctx context.Context,
+	req *pb.Empty) (*pb.SyncStatus, error) {
+
+	header, err := s.service.HeaderStore().GetBest()
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	hash := header.Hash()
+	return &pb.SyncStatus{
+		BestHeight: header.Height,
+		BestHash:   hash[:],
+		Current:    s.service.IsCurrent(),
+	}, nil
+}
+
+func toHeader(header *util.Header) (*pb.Header, error) {
+	buf := new(bytes.Buffer)
+	if err := header.BlockHeader.Serialize(buf); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	hash := header.Hash()
+	previous := header.Previous()
+	h := &pb.Header{
+		Hash:     hash[:],
+		Height:   header.Height,
+		Previous: previous[:],
+		Header:   buf.Bytes(),
+	}
+	if ih, ok := header.BlockHeader.(*iutil.Header); ok {
+		h.Timestamp = ih.Timestamp
+	}
+	return h, nil
+}
@@ -0,0 +1,178 @@
+package spvrpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/interface"
+	"github.com/elastos/Elastos.ELA.SPV/interface/spvrpc/pb"
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// service is a SPVService queues the notified transactions, other methods
+// are not implemented.
+type service struct {
+	_interface.SPVService
+
+	mtx        sync.Mutex
+	registered int
+	queued     map[common.Uint256]bool
+	receipts   chan common.Uint256
+}
+
+func (s *service) RegisterTransactionListener(_interface.TransactionListener) error {
+	s.mtx.Lock()
+	s.registered++
+	s.mtx.Unlock()
+	return nil
+}
+
+func (s *service) SubmitTransactionReceipt(notifyId, txId common.Uint256) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if !s.queued[txId] {
+		return kvdb.ErrNotFound
+	}
+	delete(s.queued, txId)
+	s.receipts <- txId
+	return nil
+}
+
+// waitAttached waits until a Notify stream is attached to the listener.
+func waitAttached(t *testing.T, l *listener, attached bool) {
+	for i := 0; i < 100; i++ {
+		l.mtx.Lock()
+		ok := (l.stream != nil) == attached
+		l.mtx.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("listener attached %v timeout", !attached)
+}
+
+func TestServerNotify(t *testing.T) {
+	svc := &service{
+		queued:   make(map[common.Uint256]bool),
+		receipts: make(chan common.Uint256, 1),
+	}
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	s := NewServer(svc)
+	s.Register(server)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer conn.Close()
+	client := NewClient(conn)
+
+	req := &pb.RegisterListenerRequest{
+		Address: "EQ4QhsYRwuBbNBXc8BPW972xA9ANByKt6U",
+		Type:    uint32(types.TransferAsset),
+	}
+	notifyId, err := client.RegisterListener(context.Background(), req)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	s.mtx.Lock()
+	l := s.listeners[notifyId]
+	s.mtx.Unlock()
+	if !assert.NotNil(t, l) {
+		t.FailNow()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.Notify(ctx, notifyId)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	waitAttached(t, l, true)
+
+	tx := types.Transaction{TxType: types.TransferAsset,
+		Payload: &payload.TransferAsset{}}
+	txId := tx.Hash()
+	svc.mtx.Lock()
+	svc.queued[txId] = true
+	svc.mtx.Unlock()
+	l.Notify(notifyId, bloom.MerkleProof{Height: 100, Transactions: 1}, tx)
+	n, err := stream.Recv()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, pb.Notification_TRANSACTION, n.Type)
+	assert.Equal(t, notifyId, n.NotifyId)
+	assert.Equal(t, txId, n.TxId)
+	assert.Equal(t, uint32(100), n.Height)
+	assert.Equal(t, txId, n.Tx.Hash())
+
+	assert.NoError(t, stream.Ack(txId))
+	assert.Equal(t, txId, <-svc.receipts)
+
+	// A duplicate ack does not close the stream, the next ack is still
+	// submitted.
+	assert.NoError(t, stream.Ack(txId))
+	tx2 := types.Transaction{TxType: types.TransferAsset,
+		Payload: &payload.TransferAsset{}, LockTime: 1}
+	svc.mtx.Lock()
+	svc.queued[tx2.Hash()] = true
+	svc.mtx.Unlock()
+	assert.NoError(t, stream.Ack(tx2.Hash()))
+	assert.Equal(t, tx2.Hash(), <-svc.receipts)
+	l.Revoked(notifyId, txId, 100)
+	n, err = stream.Recv()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, pb.Notification_REVOKED, n.Type)
+	assert.Equal(t, txId, n.TxId)
+
+	// The listener is returned without registering to the service again
+	// after the client reconnected.
+	assert.NoError(t, stream.Close())
+	cancel()
+	waitAttached(t, l, false)
+	id, err := client.RegisterListener(context.Background(), req)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, notifyId, id)
+	svc.mtx.Lock()
+	assert.Equal(t, 1, svc.registered)
+	svc.mtx.Unlock()
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	stream, err = client.Notify(ctx, notifyId)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	waitAttached(t, l, true)
+	l.Dropped(notifyId, txId, _interface.DropExpired)
+	n, err = stream.Recv()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, pb.Notification_DROPPED, n.Type)
+	assert.Equal(t, _interface.DropExpired, n.Reason)
+}
@@ -7,16 +7,22 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/elastos/Elastos.ELA.SPV/bloom"
 	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/fprate"
 	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
 	"github.com/elastos/Elastos.ELA.SPV/interface/store"
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
 	"github.com/elastos/Elastos.ELA.SPV/sdk"
 	"github.com/elastos/Elastos.ELA.SPV/util"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types"
 	"github.com/elastos/Elastos.ELA/elanet/filter"
 	"github.com/elastos/Elastos.ELA/elanet/pact"
@@ -29,14 +35,50 @@ const (
 	// notifyTimeout is the duration to timeout a notify to the listener, and
 	// resend the notify to the listener.
 	notifyTimeout = 10 * time.Second // 10 second
+
+	// maxRetryDelay is the default max delay to resend a notify.
+	maxRetryDelay = 10 * time.Minute
+
+	// deliveryInterval is the interval the delivery worker checks the queued
+	// notifications to resend.
+	deliveryInterval = time.Second
+
+	// listenerGCInterval is the interval to update the last seen time of
+	// registered listeners and remove the listeners not seen.
+	listenerGCInterval = time.Minute
+
+	// revocationDepth is the number of recent blocks whose notified
+	// transactions are recorded to be revoked on chain reorganization.
+	revocationDepth = 720
 )
 
 type spvservice struct {
 	sdk.IService
-	headers   store.HeaderStore
-	db        store.DataStore
-	rollback  func(height uint32)
-	listeners map[common.Uint256]TransactionListener
+	headers  store.HeaderStore
+	compact  *database.HeaderFile
+	db       store.DataStore
+	rollback func(height uint32)
+	reorg    func(reorg *util.Reorg)
+	started  int32
+
+	listenerMtx sync.RWMutex
+	listeners   map[common.Uint256]TransactionListener
+	listenerTTL time.Duration
+	lastGC      time.Time
+
+	retryBase   time.Duration
+	retryMax    time.Duration
+	maxAttempts uint32
+	wake        chan struct{}
+	quit        chan struct{}
+	stopOnce    sync.Once
+	revokeMtx   sync.Mutex
+
+	// pending is the unconfirmed transactions notified to listeners, it is
+	// not persisted and lost on restart.
+	pendingMtx         sync.Mutex
+	pending            map[common.Uint256]*unconfirmedTx
+	unconfirmedTimeout time.Duration
 }
 
 // NewSPVService creates a new SPV service instance.
@@ -53,24 +95,65 @@ func NewSPVService(cfg *Config) (*spvservice, error) {
 		}
 	}
 
-	headerStore, err := store.NewHeaderStore(dataDir, newBlockHeader)
+	headerStore, err := store.NewHeaderStore(dataDir, cfg.DBBackend, newBlockHeader)
 	if err != nil {
 		return nil, err
 	}
 
-	dataStore, err := store.NewDataStore(dataDir)
+	dataStore, err := store.NewDataStore(dataDir, cfg.DBBackend)
 	if err != nil {
 		return nil, err
 	}
 
 	service := &spvservice{
-		headers:   headerStore,
-		db:        dataStore,
-		rollback:  cfg.OnRollback,
-		listeners: make(map[common.Uint256]TransactionListener),
+		headers:     headerStore,
+		db:          dataStore,
+		rollback:    cfg.OnRollback,
+		reorg:       cfg.OnReorganize,
+		listeners:   make(map[common.Uint256]TransactionListener),
+		listenerTTL: cfg.ListenerTTL,
+		retryBase:   notifyTimeout,
+		retryMax:    maxRetryDelay,
+		maxAttempts: cfg.MaxAttempts,
+		wake:        make(chan struct{}, 1),
+		quit:        make(chan struct{}),
+
+		pending:            make(map[common.Uint256]*unconfirmedTx),
+		unconfirmedTimeout: defaultUnconfirmedTimeout,
+	}
+	if cfg.RetryBaseDelay > 0 {
+		service.retryBase = cfg.RetryBaseDelay
+	}
+	if cfg.RetryMaxDelay > 0 {
+		service.retryMax = cfg.RetryMaxDelay
+	}
+	if cfg.UnconfirmedTimeout > 0 {
+		service.unconfirmedTimeout = cfg.UnconfirmedTimeout
+	}
+
+	journal, err := database.NewJournal(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &database.ChainOptions{
+		PruneProofs:        cfg.PruneProofs,
+		KeepHeaders:        cfg.KeepHeaders,
+		CheckpointInterval: cfg.CheckpointInterval,
+	}
+	if cfg.CompactHeaders {
+		opts.HeaderFile, err = database.OpenHeaderFile(
+			filepath.Join(dataDir, "headers.dat"))
+		if err != nil {
+			return nil, err
+		}
+		service.compact = opts.HeaderFile
 	}
 
-	chainStore := database.NewChainDB(headerStore, service)
+	chainStore, err := database.NewChainDB(headerStore, service, journal, opts)
+	if err != nil {
+		return nil, err
+	}
 
 	serviceCfg := &sdk.Config{
 		DataDir:        dataDir,
@@ -85,6 +168,7 @@ func NewSPVService(cfg *Config) (*spvservice, error) {
 		NewTransaction: newTransaction,
 		NewBlockHeader: newBlockHeader,
 		GetTxFilter:    service.GetFilter,
+		GetTxFilters:   service.GetFilters,
 		StateNotifier:  service,
 	}
 
@@ -102,16 +186,223 @@ func (s *spvservice) RegisterTransactionListener(listener TransactionListener) e
 		return fmt.Errorf("address %s is not a valied address", listener.Address())
 	}
 	key := getListenerKey(listener)
+	s.listenerMtx.Lock()
 	if _, ok := s.listeners[key]; ok {
+		s.listenerMtx.Unlock()
 		return fmt.Errorf("listener with address: %s type: %s flags: %d already registered",
 			listener.Address(), listener.Type().Name(), listener.Flags())
 	}
 	s.listeners[key] = listener
-	return s.db.Addrs().Put(address)
+	s.listenerMtx.Unlock()
+
+	// Persist the registration, keep the first registered time of a
+	// listener restored from last run.
+	now := time.Now()
+	info := &store.ListenerInfo{
+		NotifyId:   key,
+		Address:    listener.Address(),
+		Type:       uint8(listener.Type()),
+		Flags:      listener.Flags(),
+		Registered: now,
+		LastSeen:   now,
+	}
+	if old, err := s.db.Listeners().Get(&key); err == nil && old.Address != "" {
+		info.Registered = old.Registered
+	}
+	if err := s.db.Listeners().Put(info); err != nil {
+		return err
+	}
+
+	isNew := !s.db.Addrs().GetFilter().ContainAddr(*address)
+	if err := s.db.Addrs().Put(address); err != nil {
+		return err
+	}
+
+	// Add the new address to the filter of connected peers.
+	if isNew && atomic.LoadInt32(&s.started) == 1 {
+		s.IService.AddFilterElement(address.Bytes())
+	}
+	return nil
+}
+
+func (s *spvservice) UnregisterTransactionListener(listener TransactionListener) error {
+	key := getListenerKey(listener)
+	s.listenerMtx.Lock()
+	if _, ok := s.listeners[key]; !ok {
+		s.listenerMtx.Unlock()
+		return fmt.Errorf("listener with address: %s type: %s flags: %d not registered",
+			listener.Address(), listener.Type().Name(), listener.Flags())
+	}
+	delete(s.listeners, key)
+	s.listenerMtx.Unlock()
+
+	// Purge the registration, queued notifications and revocations of the
+	// listener.
+	if err := s.db.Listeners().Del(&key); err != nil {
+		return err
+	}
+	if err := s.db.Notified().DelRevokedByNotifyId(&key); err != nil {
+		return err
+	}
+	return s.db.Que().DelByNotifyId(&key)
+}
+
+func (s *spvservice) ListTransactionListeners() ([]*ListenerInfo, error) {
+	infos, err := s.db.Listeners().GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	s.listenerMtx.RLock()
+	defer s.listenerMtx.RUnlock()
+	listeners := make([]*ListenerInfo, 0, len(infos))
+	for _, info := range infos {
+		_, active := s.listeners[info.NotifyId]
+		listeners = append(listeners, &ListenerInfo{
+			NotifyId:   info.NotifyId,
+			Address:    info.Address,
+			Type:       types.TxType(info.Type),
+			Flags:      info.Flags,
+			Registered: info.Registered,
+			LastSeen:   info.LastSeen,
+			Active:     active,
+		})
+	}
+	return listeners, nil
+}
+
+// restoreListeners restores the listeners registered in last run, their
+// addresses are kept in the filter and the last seen time is reset, so they
+// have ListenerTTL to be registered again after the service was down.
+func (s *spvservice) restoreListeners() {
+	infos, err := s.db.Listeners().GetAll()
+	if err != nil {
+		log.Errorf("query listeners failed, %s", err)
+		return
+	}
+	now := time.Now()
+	for _, info := range infos {
+		if info.Address != "" {
+			address, err := common.Uint168FromAddress(info.Address)
+			if err == nil {
+				s.db.Addrs().Put(address)
+			}
+		}
+		info.LastSeen = now
+		s.db.Listeners().Put(info)
+	}
+	s.lastGC = now
+	if len(infos) > 0 {
+		log.Infof("Restored %d listeners from last run", len(infos))
+	}
+}
+
+// gcListeners updates the last seen time of registered listeners, and
+// removes the listeners not seen for ListenerTTL with their queued
+// notifications.  It is called by the delivery worker.
+func (s *spvservice) gcListeners() {
+	now := time.Now()
+	if now.Before(s.lastGC.Add(listenerGCInterval)) {
+		return
+	}
+	s.lastGC = now
+
+	items, err := s.db.Que().GetAll()
+	if err != nil {
+		log.Errorf("query queued notifications failed, %s", err)
+		return
+	}
+	infos, err := s.db.Listeners().GetAll()
+	if err != nil {
+		log.Errorf("query listeners failed, %s", err)
+		return
+	}
+	known := make(map[common.Uint256]struct{})
+	for _, info := range infos {
+		known[info.NotifyId] = struct{}{}
+
+		// Hold the lock until the listener is removed, so a listener
+		// registered again meanwhile is not removed.
+		s.listenerMtx.Lock()
+		_, active := s.listeners[info.NotifyId]
+		if active {
+			info.LastSeen = now
+			s.db.Listeners().Put(info)
+			s.listenerMtx.Unlock()
+			continue
+		}
+
+		if s.listenerTTL > 0 && now.Sub(info.LastSeen) > s.listenerTTL {
+			log.Infof("Remove listener %s of address %s not seen since %s",
+				info.NotifyId, info.Address, info.LastSeen)
+			s.db.Listeners().Del(&info.NotifyId)
+			s.db.Notified().DelRevokedByNotifyId(&info.NotifyId)
+			s.db.Que().DelByNotifyId(&info.NotifyId)
+		}
+		s.listenerMtx.Unlock()
+	}
+
+	// Track the queued notifications of listeners registered before
+	// listeners were persisted, so they can be removed when not seen.
+	for _, item := range items {
+		if _, ok := known[item.NotifyId]; ok {
+			continue
+		}
+		known[item.NotifyId] = struct{}{}
+		s.db.Listeners().Put(&store.ListenerInfo{
+			NotifyId:   item.NotifyId,
+			Registered: now,
+			LastSeen:   now,
+		})
+	}
 }
 
 func (s *spvservice) SubmitTransactionReceipt(notifyId, txHash common.Uint256) error {
-	return s.db.Que().Del(&notifyId, &txHash)
+	err := s.db.Que().Del(&notifyId, &txHash)
+	if err == kvdb.ErrNotFound && s.isUnconfirmed(notifyId, txHash) {
+		return nil
+	}
+	return err
+}
+
+func (s *spvservice) GetDeadLetters() ([]*DeadLetter, error) {
+	letters, err := s.db.DeadLetters().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	dls := make([]*DeadLetter, 0, len(letters))
+	for _, l := range letters {
+		dls = append(dls, &DeadLetter{
+			NotifyId:   l.NotifyId,
+			TxId:       l.TxId,
+			Height:     l.Height,
+			Attempts:   l.Attempts,
+			LastNotify: l.LastNotify,
+			Time:       l.Time,
+		})
+	}
+	return dls, nil
+}
+
+func (s *spvservice) ReplayDeadLetter(notifyId, txId common.Uint256) error {
+	letter, err := s.db.DeadLetters().Get(&notifyId, &txId)
+	if err != nil {
+		return fmt.Errorf("dead letter of listener %s transaction %s not found",
+			notifyId, txId)
+	}
+	err = s.db.Que().Put(&store.QueItem{
+		NotifyId: letter.NotifyId,
+		TxId:     letter.TxId,
+		Height:   letter.Height,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.db.DeadLetters().Del(&notifyId, &txId); err != nil {
+		return err
+	}
+	s.wakeDelivery()
+	return nil
 }
 
 func (s *spvservice) VerifyTransaction(proof bloom.MerkleProof, tx types.Transaction) error {
@@ -151,6 +442,114 @@ func (s *spvservice) VerifyTransaction(proof bloom.MerkleProof, tx types.Transac
 	return nil
 }
 
+func (s *spvservice) VerifyDeposit(deposit *CrossChainDeposit,
+	confirmations uint32) error {
+
+	// Check the transaction is included in a block on the main chain.
+	if err := s.VerifyTransaction(deposit.Proof, deposit.Tx); err != nil {
+		return err
+	}
+	header, err := s.headers.GetByHeight(deposit.Proof.Height)
+	if err != nil {
+		return fmt.Errorf("can not get block on height %d from main chain",
+			deposit.Proof.Height)
+	}
+	if header.Hash() != deposit.Proof.BlockHash {
+		return fmt.Errorf("block %s is not on the main chain",
+			deposit.Proof.BlockHash)
+	}
+
+	best, err := s.headers.GetBest()
+	if err != nil {
+		return err
+	}
+	var have uint32
+	if best.Height >= deposit.Proof.Height {
+		have = best.Height - deposit.Proof.Height
+	}
+	if have < confirmations {
+		return fmt.Errorf("deposit has %d confirmations, require %d",
+			have, confirmations)
+	}
+
+	// Check the deposits are decoded from the transaction.
+	parsed, err := ParseCrossChainDeposit(&deposit.Tx, deposit.GenesisAddress)
+	if err != nil {
+		return err
+	}
+	if len(parsed.Outputs) != len(deposit.Outputs) {
+		return errors.New("deposit outputs not match transaction")
+	}
+	for i, output := range parsed.Outputs {
+		if *output != *deposit.Outputs[i] {
+			return fmt.Errorf("deposit output %d not match transaction",
+				output.Index)
+		}
+	}
+	return nil
+}
+
+func (s *spvservice) GetProofBundle(txId *common.Uint256,
+	checkpointHeight uint32) (*bloom.ProofBundle, error) {
+
+	utx, err := s.db.Txs().Get(txId)
+	if err != nil {
+		return nil, err
+	}
+	if utx.Height < checkpointHeight {
+		return nil, fmt.Errorf("transaction height %d is before checkpoint"+
+			" height %d", utx.Height, checkpointHeight)
+	}
+
+	var tx types.Transaction
+	err = tx.Deserialize(bytes.NewReader(utx.RawData))
+	if err != nil {
+		return nil, err
+	}
+
+	// Include headers after the block as confirmations, within the limit
+	// of a proof bundle.
+	best, err := s.headers.GetBest()
+	if err != nil {
+		return nil, err
+	}
+	if checkpointHeight > best.Height {
+		return nil, fmt.Errorf("checkpoint height %d is after best height"+
+			" %d", checkpointHeight, best.Height)
+	}
+	// Old headers may be pruned, the proof of work of them is not kept.
+	if _, err := s.headers.GetByHeight(checkpointHeight); err != nil {
+		return nil, fmt.Errorf("checkpoint header on height %d not"+
+			" available, it may be pruned: %v", checkpointHeight, err)
+	}
+	to := best.Height
+	if to-checkpointHeight >= bloom.MaxProofBundleHeaders {
+		to = checkpointHeight + bloom.MaxProofBundleHeaders - 1
+	}
+	if to < utx.Height {
+		return nil, errors.New("too many headers from checkpoint to" +
+			" the transaction")
+	}
+	headers, err := s.headers.GetRange(checkpointHeight, to)
+	if err != nil {
+		return nil, err
+	}
+
+	header := headers[utx.Height-checkpointHeight]
+	merkleBlock := msg.MerkleBlock{
+		Header:       header.BlockHeader,
+		Transactions: header.NumTxs,
+		Hashes:       header.Hashes,
+		Flags:        header.Flags,
+	}
+	blockHeaders := make([]util.BlockHeader, 0, len(headers))
+	for _, header := range headers {
+		blockHeaders = append(blockHeaders, header.BlockHeader)
+	}
+	return bloom.CreateProofBundle(checkpointHeight, utx.Height,
+		blockHeaders, iutil.NewTx(&tx), merkleBlock)
+}
+
 func (s *spvservice) SendTransaction(tx types.Transaction) error {
 	return s.IService.SendTransaction(iutil.NewTx(&tx))
 }
@@ -174,10 +573,122 @@ func (s *spvservice) GetTransactionIds(height uint32) ([]*common.Uint256, error)
 	return s.db.Txs().GetIds(height)
 }
 
+func (s *spvservice) GetTransactionsByAddress(address string, offset,
+	limit uint32, order Order) ([]*AddressTx, error) {
+	addr, err := common.Uint168FromAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("address %s is not a valied address", address)
+	}
+
+	utxs, err := s.db.Txs().GetByAddress(addr, offset, limit, order == Descending)
+	if err != nil {
+		return nil, err
+	}
+
+	var bestHeight uint32
+	if best, err := s.headers.GetBest(); err == nil {
+		bestHeight = best.Height
+	}
+
+	txs := make([]*AddressTx, 0, len(utxs))
+	for _, utx := range utxs {
+		var tx types.Transaction
+		err = tx.Deserialize(bytes.NewReader(utx.RawData))
+		if err != nil {
+			return nil, err
+		}
+
+		var confirmations uint32
+		if bestHeight >= utx.Height {
+			confirmations = bestHeight - utx.Height
+		}
+		txs = append(txs, &AddressTx{
+			Tx:            &tx,
+			Height:        utx.Height,
+			Confirmations: confirmations,
+		})
+	}
+	return txs, nil
+}
+
+func (s *spvservice) GetBalance(address string,
+	assetId common.Uint256) (*Balance, error) {
+	utxos, err := s.ListUnspent(address)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := new(Balance)
+	for _, utxo := range utxos {
+		if !utxo.AssetID.IsEqual(assetId) {
+			continue
+		}
+		switch utxo.State {
+		case UTXOConfirmed:
+			balance.Confirmed += utxo.Value
+		case UTXOImmature:
+			balance.Immature += utxo.Value
+		case UTXOLocked:
+			balance.Locked += utxo.Value
+		}
+	}
+	return balance, nil
+}
+
+func (s *spvservice) ListUnspent(address string) ([]*UTXO, error) {
+	addr, err := common.Uint168FromAddress(address)
+	if err != nil {
+		return nil, fmt.Errorf("address %s is not a valied address", address)
+	}
+
+	sutxos, err := s.db.UTXOs().GetByAddress(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	var bestHeight uint32
+	if best, err := s.headers.GetBest(); err == nil {
+		bestHeight = best.Height
+	}
+
+	utxos := make([]*UTXO, 0, len(sutxos))
+	for _, sutxo := range sutxos {
+		if sutxo.IsSpent() {
+			continue
+		}
+
+		var confirmations uint32
+		if bestHeight >= sutxo.Height {
+			confirmations = bestHeight - sutxo.Height
+		}
+		utxo := &UTXO{
+			Op:            sutxo.Op,
+			AssetID:       sutxo.AssetID,
+			Value:         sutxo.Value,
+			OutputLock:    sutxo.OutputLock,
+			Height:        sutxo.Height,
+			Confirmations: confirmations,
+			State:         UTXOConfirmed,
+		}
+		switch {
+		case sutxo.Coinbase && confirmations < CoinbaseMaturity:
+			utxo.State = UTXOImmature
+		case sutxo.OutputLock > bestHeight:
+			utxo.State = UTXOLocked
+		}
+		utxos = append(utxos, utxo)
+	}
+	return utxos, nil
+}
+
 func (s *spvservice) HeaderStore() database.Headers {
 	return s.headers
 }
 
+func (s *spvservice) CompactHeaders() *database.HeaderFile {
+	return s.compact
+}
+
 func (s *spvservice) GetFilter() *msg.TxFilterLoad {
 	addrs := s.db.Addrs().GetAll()
 	f := bloom.NewFilter(uint32(len(addrs)), math.MaxUint32, 0)
@@ -187,6 +698,24 @@ func (s *spvservice) GetFilter() *msg.TxFilterLoad {
 	return f.ToTxFilterMsg(filter.FTBloom)
 }
 
+// GetFilters returns the filters of registered addresses, the addresses are
+// partitioned into multiple filters when they can not be held by one filter.
+func (s *spvservice) GetFilters() []*msg.TxFilterLoad {
+	addrs := s.db.Addrs().GetAll()
+	elements := make([][]byte, 0, len(addrs))
+	for _, address := range addrs {
+		elements = append(elements, address.Bytes())
+	}
+
+	fs := bloom.NewFilters(elements, math.MaxUint32,
+		fprate.ReducedFalsePositiveRate)
+	filters := make([]*msg.TxFilterLoad, 0, len(fs))
+	for _, f := range fs {
+		filters = append(filters, f.ToTxFilterMsg(filter.FTBloom))
+	}
+	return filters
+}
+
 func (s *spvservice) putTx(batch store.DataBatch, utx util.Transaction,
 	height uint32) (bool, error) {
 
@@ -213,17 +742,25 @@ func (s *spvservice) putTx(batch store.DataBatch, utx util.Transaction,
 		return true, nil
 	}
 
+	outAddrs := make([]common.Uint168, 0, len(ops))
 	for op, addr := range ops {
 		if err := batch.Ops().Put(op, addr); err != nil {
 			return false, err
 		}
+		outAddrs = append(outAddrs, addr)
+	}
+	err := batch.UTXOs().PutTx(tx.Transaction, height, outAddrs)
+	if err != nil {
+		return false, err
 	}
 
+	s.listenerMtx.RLock()
+	defer s.listenerMtx.RUnlock()
 	for _, listener := range s.listeners {
 		hash, _ := common.Uint168FromAddress(listener.Address())
 		if _, ok := hits[*hash]; ok {
-			// skip transactions that not match the require type
-			if listener.Type() != tx.TxType {
+			// skip transactions that not match the listener
+			if !matchListener(listener, hash, tx.Transaction) {
 				continue
 			}
 
@@ -236,7 +773,16 @@ func (s *spvservice) putTx(batch store.DataBatch, utx util.Transaction,
 		}
 	}
 
-	return false, batch.Txs().Put(util.NewTx(utx, height))
+	stx := util.NewTx(utx, height)
+	if err := batch.Txs().Put(stx); err != nil {
+		return false, err
+	}
+
+	addrs := make([]common.Uint168, 0, len(hits))
+	for addr := range hits {
+		addrs = append(addrs, addr)
+	}
+	return false, batch.Txs().PutAddrIndex(stx, addrs)
 }
 
 // PutTxs persists the main chain transactions into database and can be
@@ -322,7 +868,8 @@ func (s *spvservice) GetForkTxs(hash *common.Uint256) ([]util.Transaction, error
 
 // DelTxs remove all transactions in main chain within the given height.
 func (s *spvservice) DelTxs(height uint32) error {
-	// Delete transactions, outpoints and queued items.
+	// Delete transactions, outpoints and queued items, and queue the
+	// revocations of the notified transactions.
 	batch := s.db.Batch()
 	defer batch.Rollback()
 	if err := batch.DelAll(height); err != nil {
@@ -332,6 +879,9 @@ func (s *spvservice) DelTxs(height uint32) error {
 		return err
 	}
 
+	// Revoke the notified transactions from their listeners.
+	s.revoke()
+
 	// Invoke main chain rollback.
 	if s.rollback != nil {
 		s.rollback(height)
@@ -339,9 +889,6 @@ func (s *spvservice) DelTxs(height uint32) error {
 	return nil
 }
 
-// TransactionAnnounce will be invoked when received a new announced transaction.
-func (s *spvservice) TransactionAnnounce(tx util.Transaction) {}
-
 // TransactionAccepted will be invoked after a transaction sent by
 // SendTransaction() method has been accepted.  Notice: this method needs at
 // lest two connected peers to work.
@@ -358,14 +905,146 @@ func (s *spvservice) TransactionConfirmed(tx *util.Tx) {}
 // BlockCommitted will be invoked when a block and transactions within it are
 // successfully committed into database.
 func (s *spvservice) BlockCommitted(block *util.Block) {
+	// Notifications deep enough will not be revoked.
+	if block.Height > revocationDepth {
+		s.db.Notified().DelBefore(block.Height - revocationDepth)
+	}
+
+	// Resolve the unconfirmed transactions by the new block.
+	txs := make([]*types.Transaction, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		txs = append(txs, tx.(*iutil.Tx).Transaction)
+	}
+	s.resolveUnconfirmed(txs, time.Now())
+
+	// Tell subscriptions the new confirmations.
+	s.listenerMtx.RLock()
+	var bls []blockListener
+	for _, listener := range s.listeners {
+		if l, ok := listener.(blockListener); ok {
+			bls = append(bls, l)
+		}
+	}
+	s.listenerMtx.RUnlock()
+	for _, l := range bls {
+		l.blockCommitted(block.Height)
+	}
+
+	// Deliver notifications confirmed by the new block.
+	s.wakeDelivery()
+}
+
+// wakeDelivery signals the delivery worker to check the queued notifications.
+func (s *spvservice) wakeDelivery() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliveryHandler is the worker to send queued notifications to listeners,
+// notifications are resend in exponential backoff until receipted or moved
+// to dead letters after maxAttempts.  It must be run as a goroutine.
+func (s *spvservice) deliveryHandler() {
+	ticker := time.NewTicker(deliveryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		case <-s.quit:
+			return
+		}
+		s.gcListeners()
+
+		// Revocations are sent before the transactions notified again.
+		s.revoke()
+		s.deliver()
+	}
+}
+
+// revoke sends the queued revocations to the registered listeners, the
+// revocations of listeners not registered are kept until they are registered
+// again or removed.
+func (s *spvservice) revoke() {
+	s.revokeMtx.Lock()
+	defer s.revokeMtx.Unlock()
+
+	items, err := s.db.Notified().GetRevoked()
+	if err != nil {
+		log.Errorf("query revocations failed, %s", err)
+		return
+	}
+	for _, item := range items {
+		s.listenerMtx.RLock()
+		listener, ok := s.listeners[item.NotifyId]
+		s.listenerMtx.RUnlock()
+		if !ok {
+			continue
+		}
+		if l, ok := listener.(RevocationListener); ok {
+			l.Revoked(item.NotifyId, item.TxId, item.Height)
+		}
+		s.db.Notified().DelRevoked(&item.NotifyId, &item.TxId)
+	}
+}
+
+// retryDelay returns the delay to resend a notification sent attempts times.
+func (s *spvservice) retryDelay(attempts uint32) time.Duration {
+	if attempts == 0 {
+		return 0
+	}
+	delay := s.retryBase
+	for i := uint32(1); i < attempts && delay < s.retryMax; i++ {
+		delay *= 2
+	}
+	if delay > s.retryMax {
+		delay = s.retryMax
+	}
+	return delay
+}
+
+func (s *spvservice) deliver() {
+	best, err := s.headers.GetBest()
+	if err != nil {
+		return
+	}
+
 	// Look up for queued transactions
 	items, err := s.db.Que().GetAll()
 	if err != nil {
 		return
 	}
+
 	for _, item := range items {
-		// Check if the notify should be resend due to timeout.
-		if time.Now().Before(item.LastNotify.Add(notifyTimeout)) {
+		// Check if the notify should be resend by the retry policy.
+		if time.Now().Before(item.LastNotify.Add(s.retryDelay(item.Attempts))) {
+			continue
+		}
+
+		// Give up the notify after max attempts.
+		if s.maxAttempts > 0 && item.Attempts >= s.maxAttempts {
+			log.Warnf("Notify transaction %s to listener %s failed after"+
+				" %d attempts", item.TxId, item.NotifyId, item.Attempts)
+			err := s.db.DeadLetters().Put(&store.DeadLetter{
+				QueItem: *item,
+				Time:    time.Now(),
+			})
+			if err != nil {
+				log.Errorf("put dead letter failed, %s", err)
+				continue
+			}
+			s.db.Que().Del(&item.NotifyId, &item.TxId)
+			continue
+		}
+
+		// Skip the items waiting for confirmations before reading them.
+		s.listenerMtx.RLock()
+		listener, ok := s.listeners[item.NotifyId]
+		s.listenerMtx.RUnlock()
+		if ok && listener.Flags()&FlagNotifyConfirmed == FlagNotifyConfirmed &&
+			item.Height+minConfirmations(listener) > best.Height {
 			continue
 		}
 
@@ -398,15 +1077,50 @@ func (s *spvservice) BlockCommitted(block *util.Block) {
 		}
 
 		// Notify listeners
-		listener, ok := s.notifyTransaction(item.NotifyId, proof, tx, block.Height-item.Height)
+		var confirmations uint32
+		if best.Height > item.Height {
+			confirmations = best.Height - item.Height
+		}
+		listener, ok = s.notifyTransaction(item.NotifyId, proof, tx, confirmations)
 		if ok {
+			// The item may be receipted or rolled back since the queue was
+			// read, it must not be queued again.
+			item.Attempts++
 			item.LastNotify = time.Now()
-			s.db.Que().Put(item)
-			listener.Notify(item.NotifyId, proof, tx)
+			if err := s.db.Que().Update(item); err != nil {
+				continue
+			}
+			s.db.Notified().Put(item)
+			s.notify(listener, item.NotifyId, proof, tx)
 		}
 	}
 }
 
+// ChainReorganized will be invoked when the main chain has been switched to
+// a new best chain.
+func (s *spvservice) ChainReorganized(reorg *util.Reorg) {
+	log.Infof("Chain reorganized at height %d, detached %d blocks,"+
+		" attached %d blocks", reorg.CommonAncestor.Height,
+		len(reorg.Detached), len(reorg.Attached))
+
+	// Invoke main chain reorganize.
+	if s.reorg != nil {
+		s.reorg(reorg)
+	}
+}
+
+func (s *spvservice) Start() {
+	s.restoreListeners()
+	atomic.StoreInt32(&s.started, 1)
+	s.IService.Start()
+	go s.deliveryHandler()
+}
+
+func (s *spvservice) Stop() {
+	s.stopOnce.Do(func() { close(s.quit) })
+	s.IService.Stop()
+}
+
 func (s *spvservice) ClearData() error {
 	if err := s.headers.Clear(); err != nil {
 		log.Warnf("Clear header store error %s", err.Error())
@@ -432,8 +1146,9 @@ func (s *spvservice) queueMessageByListener(
 		return
 	}
 
-	// skip transactions that not match the require type
-	if listener.Type() != tx.TxType {
+	// skip transactions that not match the listener
+	hash, _ := common.Uint168FromAddress(listener.Address())
+	if !matchListener(listener, hash, tx) {
 		return
 	}
 
@@ -449,7 +1164,9 @@ func (s *spvservice) notifyTransaction(notifyId common.Uint256,
 	proof bloom.MerkleProof, tx types.Transaction,
 	confirmations uint32) (TransactionListener, bool) {
 
+	s.listenerMtx.RLock()
 	listener, ok := s.listeners[notifyId]
+	s.listenerMtx.RUnlock()
 	if !ok {
 		return nil, false
 	}
@@ -462,7 +1179,7 @@ func (s *spvservice) notifyTransaction(notifyId common.Uint256,
 		listener.Flags()&FlagNotifyInSyncing != FlagNotifyInSyncing {
 
 		if listener.Flags()&FlagNotifyConfirmed == FlagNotifyConfirmed {
-			if confirmations >= getConfirmations(tx) {
+			if confirmations >= getConfirmations(listener, tx) {
 				s.db.Que().Del(&notifyId, &txId)
 			}
 		} else {
@@ -473,29 +1190,129 @@ func (s *spvservice) notifyTransaction(notifyId common.Uint256,
 
 	// Notify listener
 	if listener.Flags()&FlagNotifyConfirmed == FlagNotifyConfirmed {
-		if confirmations >= getConfirmations(tx) {
+		if confirmations >= getConfirmations(listener, tx) {
 			return listener, true
 		}
 	} else {
-		listener.Notify(notifyId, proof, tx)
 		return listener, true
 	}
 
 	return nil, false
 }
 
+// NotifyId returns the notify id of the listener's notifications.
+func NotifyId(listener TransactionListener) common.Uint256 {
+	return getListenerKey(listener)
+}
+
 func getListenerKey(listener TransactionListener) common.Uint256 {
 	buf := new(bytes.Buffer)
 	addr, _ := common.Uint168FromAddress(listener.Address())
 	common.WriteElements(buf, addr[:], listener.Type(), listener.Flags())
+
+	// The key of a listener without options is not changed, so it's queued
+	// notifications are kept.
+	if l, ok := listener.(OptionsListener); ok {
+		if opts := l.Options(); opts != nil {
+			opts.serialize(buf)
+		}
+	}
 	return sha256.Sum256(buf.Bytes())
 }
 
-func getConfirmations(tx types.Transaction) uint32 {
-	// TODO user can set confirmations attribute in transaction,
-	// if the confirmation attribute is set, use it instead of default value
+// matchListener returns if the transaction hit the address matches the
+// transaction types and predicates of the listener.
+func matchListener(listener TransactionListener, addr *common.Uint168,
+	tx *types.Transaction) bool {
+
+	l, ok := listener.(OptionsListener)
+	if !ok || l.Options() == nil {
+		return listener.Type() == tx.TxType
+	}
+	opts := l.Options()
+
+	if !opts.AllTypes {
+		match := false
+		for _, txType := range opts.types(listener) {
+			if txType == tx.TxType {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	if opts.AssetID == nil && opts.MinAmount == 0 {
+		return true
+	}
+
+	// Amounts of different assets can not be summed, MinAmount applies to
+	// the ELA asset if AssetID is not set.
+	assetID := config.ELAAssetID
+	if opts.AssetID != nil {
+		assetID = *opts.AssetID
+	}
+	var amount common.Fixed64
+	paid := false
+	for _, output := range tx.Outputs {
+		if !output.ProgramHash.IsEqual(*addr) {
+			continue
+		}
+		if output.AssetID != assetID {
+			continue
+		}
+		amount += output.Value
+		paid = true
+	}
+	return paid && amount >= opts.MinAmount
+}
+
+// notify calls the listener with the transaction, cross chain listeners are
+// called with the decoded deposit instead.
+func (s *spvservice) notify(listener TransactionListener,
+	notifyId common.Uint256, proof bloom.MerkleProof, tx types.Transaction) {
+
+	l, ok := listener.(CrossChainListener)
+	if !ok || tx.TxType != types.TransferCrossChainAsset {
+		listener.Notify(notifyId, proof, tx)
+		return
+	}
+
+	deposit, err := ParseCrossChainDeposit(&tx, listener.Address())
+	if err != nil {
+		// Not a deposit to the sidechain, remove it from the queue.
+		txId := tx.Hash()
+		log.Warnf("Parse cross chain deposit %s failed, %s", txId, err)
+		s.db.Que().Del(&notifyId, &txId)
+		return
+	}
+	deposit.Proof = proof
+	l.NotifyDeposit(notifyId, deposit)
+}
+
+// minConfirmations returns the least confirmations the listener requires of
+// any transaction type.
+func minConfirmations(listener TransactionListener) uint32 {
+	if l, ok := listener.(OptionsListener); ok {
+		if opts := l.Options(); opts != nil && opts.Confirmations > 0 {
+			return opts.Confirmations
+		}
+	}
+	return DefaultConfirmations
+}
+
+// getConfirmations returns the confirmations required to notify the
+// transaction to the listener with FlagNotifyConfirmed.
+func getConfirmations(listener TransactionListener, tx types.Transaction) uint32 {
+	if l, ok := listener.(OptionsListener); ok {
+		if opts := l.Options(); opts != nil && opts.Confirmations > 0 {
+			return opts.Confirmations
+		}
+	}
 	if tx.TxType == types.CoinBase {
-		return 100
+		return CoinbaseMaturity
 	}
 	return DefaultConfirmations
 }
@@ -0,0 +1,316 @@
+package _interface
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA.SPV/interface/store"
+	"github.com/elastos/Elastos.ELA.SPV/sdk"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+type optionsListener struct {
+	address string
+	txType  types.TxType
+	opts    *ListenerOptions
+}
+
+func (l *optionsListener) Address() string {
+	return l.address
+}
+
+func (l *optionsListener) Type() types.TxType {
+	return l.txType
+}
+
+func (l *optionsListener) Flags() uint64 {
+	return FlagNotifyConfirmed
+}
+
+func (l *optionsListener) Notify(common.Uint256, bloom.MerkleProof, types.Transaction) {}
+
+func (l *optionsListener) Options() *ListenerOptions {
+	return l.opts
+}
+
+func TestMatchListener(t *testing.T) {
+	var addr common.Uint168
+	rand.Read(addr[:])
+	addr[0] = 0x21
+	address, err := addr.ToAddress()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var asset, other common.Uint256
+	rand.Read(asset[:])
+	rand.Read(other[:])
+
+	tx := &types.Transaction{
+		TxType: types.TransferAsset,
+		Outputs: []*types.Output{
+			{ProgramHash: addr, AssetID: asset, Value: 100},
+			{ProgramHash: addr, AssetID: other, Value: 1000},
+			{ProgramHash: addr, AssetID: config.ELAAssetID, Value: 50},
+		},
+	}
+
+	// Listeners without options match the single type.
+	l := &optionsListener{address: address, txType: types.TransferAsset}
+	assert.True(t, matchListener(l, &addr, tx))
+	assert.Equal(t, uint32(DefaultConfirmations), getConfirmations(l, *tx))
+	key := getListenerKey(l)
+	l.txType = types.CoinBase
+	assert.False(t, matchListener(l, &addr, tx))
+
+	// Multiple types and wildcard.
+	l.opts = &ListenerOptions{
+		Types: []types.TxType{types.CoinBase, types.TransferAsset},
+	}
+	assert.True(t, matchListener(l, &addr, tx))
+	assert.NotEqual(t, key, getListenerKey(l))
+	l.opts = &ListenerOptions{AllTypes: true, Confirmations: 20}
+	assert.True(t, matchListener(l, &addr, tx))
+	assert.Equal(t, uint32(20), getConfirmations(l, *tx))
+
+	// Asset and amount predicates.
+	l.opts = &ListenerOptions{AllTypes: true, AssetID: &asset, MinAmount: 100}
+	assert.True(t, matchListener(l, &addr, tx))
+	l.opts.MinAmount = 101
+	assert.False(t, matchListener(l, &addr, tx))
+
+	// Amounts of other assets are not summed without an asset, the amount
+	// of ELA is required.
+	l.opts.AssetID = nil
+	assert.False(t, matchListener(l, &addr, tx))
+	l.opts.MinAmount = 50
+	assert.True(t, matchListener(l, &addr, tx))
+	l.opts.MinAmount = 0
+	assert.True(t, matchListener(l, &addr, tx))
+}
+
+func TestRetryDelay(t *testing.T) {
+	s := &spvservice{retryBase: time.Second, retryMax: 10 * time.Second}
+	assert.Equal(t, time.Duration(0), s.retryDelay(0))
+	assert.Equal(t, time.Second, s.retryDelay(1))
+	assert.Equal(t, 2*time.Second, s.retryDelay(2))
+	assert.Equal(t, 8*time.Second, s.retryDelay(4))
+	assert.Equal(t, 10*time.Second, s.retryDelay(5))
+	assert.Equal(t, 10*time.Second, s.retryDelay(100))
+}
+
+type currentService struct {
+	sdk.IService
+}
+
+func (s *currentService) IsCurrent() bool {
+	return true
+}
+
+type revocationListener struct {
+	optionsListener
+	notified []common.Uint256
+	revoked  []common.Uint256
+}
+
+func (l *revocationListener) Flags() uint64 {
+	return 0
+}
+
+func (l *revocationListener) Notify(_ common.Uint256, _ bloom.MerkleProof,
+	tx types.Transaction) {
+	l.notified = append(l.notified, tx.Hash())
+}
+
+func (l *revocationListener) Revoked(_ common.Uint256, txId common.Uint256,
+	_ uint32) {
+	l.revoked = append(l.revoked, txId)
+}
+
+func TestRevokeAndNotifyAgain(t *testing.T) {
+	headers, err := store.NewMemHeaderStore(newBlockHeader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	db, err := store.NewMemDataStore()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+	s := &spvservice{
+		IService:  &currentService{},
+		headers:   headers,
+		db:        db,
+		listeners: make(map[common.Uint256]TransactionListener),
+		retryBase: time.Minute,
+		retryMax:  time.Minute,
+	}
+
+	var addr common.Uint168
+	rand.Read(addr[:])
+	addr[0] = 0x21
+	address, err := addr.ToAddress()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	l := &revocationListener{optionsListener: optionsListener{
+		address: address, txType: types.TransferAsset,
+	}}
+	notifyId := getListenerKey(l)
+	s.listeners[notifyId] = l
+	if !assert.NoError(t, db.Addrs().Put(&addr)) {
+		t.FailNow()
+	}
+
+	tx := &types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Outputs: []*types.Output{{ProgramHash: addr, Value: 1}},
+	}
+	txId := tx.Hash()
+	putBlock := func(height uint32) {
+		header := &util.Header{
+			BlockHeader: iutil.NewHeader(&types.Header{Height: height}),
+			Height:      height,
+			TotalWork:   new(big.Int),
+		}
+		if !assert.NoError(t, headers.Put(header, true)) {
+			t.FailNow()
+		}
+		_, err := s.PutTxs([]util.Transaction{iutil.NewTx(tx)}, height)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		s.deliver()
+	}
+
+	putBlock(100)
+	assert.Equal(t, []common.Uint256{txId}, l.notified)
+	if !assert.NoError(t, s.SubmitTransactionReceipt(notifyId, txId)) {
+		t.FailNow()
+	}
+
+	// The revocation is kept while the listener is not registered.
+	delete(s.listeners, notifyId)
+	if !assert.NoError(t, s.DelTxs(100)) {
+		t.FailNow()
+	}
+	revoked, err := db.Notified().GetRevoked()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Equal(t, 1, len(revoked)) {
+		t.FailNow()
+	}
+	assert.Equal(t, store.QueItem{
+		NotifyId: notifyId, TxId: txId, Height: 100,
+	}, *revoked[0])
+	notified, err := db.Notified().GetAll(100)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 0, len(notified))
+	assert.Equal(t, 0, len(l.revoked))
+
+	// The revocation is sent after the listener registered again, and the
+	// transaction is notified again when packed in the new chain.
+	s.listeners[notifyId] = l
+	s.revoke()
+	assert.Equal(t, []common.Uint256{txId}, l.revoked)
+	revoked, err = db.Notified().GetRevoked()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 0, len(revoked))
+
+	putBlock(101)
+	assert.Equal(t, []common.Uint256{txId, txId}, l.notified)
+	s.revoke()
+	assert.Equal(t, []common.Uint256{txId}, l.revoked)
+}
+
+func TestRestoreAndGCListeners(t *testing.T) {
+	db, err := store.NewMemDataStore()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+	s := &spvservice{
+		db:          db,
+		listeners:   make(map[common.Uint256]TransactionListener),
+		listenerTTL: time.Hour,
+	}
+
+	var addr common.Uint168
+	rand.Read(addr[:])
+	addr[0] = 0x21
+	address, err := addr.ToAddress()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	active := &optionsListener{address: address, txType: types.TransferAsset}
+	inactive := &optionsListener{address: address, txType: types.CoinBase}
+	activeId, inactiveId := getListenerKey(active), getListenerKey(inactive)
+	lastSeen := time.Now().Add(-2 * time.Hour)
+	for _, id := range []common.Uint256{activeId, inactiveId} {
+		err := db.Listeners().Put(&store.ListenerInfo{
+			NotifyId: id, Address: address, LastSeen: lastSeen,
+		})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		var txId common.Uint256
+		rand.Read(txId[:])
+		err = db.Que().Put(&store.QueItem{NotifyId: id, TxId: txId, Height: 1})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+
+	// Restored listeners are not removed before ListenerTTL.
+	s.restoreListeners()
+	assert.True(t, db.Addrs().GetFilter().ContainAddr(addr))
+	s.lastGC = time.Time{}
+	s.gcListeners()
+	infos, err := db.Listeners().GetAll()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 2, len(infos))
+
+	// Listeners not registered again are removed with their notifications.
+	s.listeners[activeId] = active
+	for _, id := range []common.Uint256{activeId, inactiveId} {
+		err := db.Listeners().Put(&store.ListenerInfo{
+			NotifyId: id, Address: address, LastSeen: lastSeen,
+		})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	s.lastGC = time.Time{}
+	s.gcListeners()
+	info, err := db.Listeners().Get(&activeId)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, info.LastSeen.After(lastSeen))
+	_, err = db.Listeners().Get(&inactiveId)
+	assert.Error(t, err)
+	items, err := db.Que().GetAll()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if assert.Equal(t, 1, len(items)) {
+		assert.Equal(t, activeId, items[0].NotifyId)
+	}
+}
@@ -3,11 +3,10 @@ package store
 import (
 	"sync"
 
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
 	"github.com/elastos/Elastos.ELA.SPV/sdk"
 
 	"github.com/elastos/Elastos.ELA/common"
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 var (
@@ -19,11 +18,11 @@ var _ Addrs = (*addrs)(nil)
 
 type addrs struct {
 	sync.RWMutex
-	db     *leveldb.DB
+	db     kvdb.DB
 	filter *sdk.AddrFilter
 }
 
-func NewAddrs(db *leveldb.DB) (*addrs, error) {
+func NewAddrs(db kvdb.DB) (*addrs, error) {
 	store := addrs{db: db}
 
 	addrs, err := store.getAll()
@@ -50,7 +49,7 @@ func (a *addrs) Put(addr *common.Uint168) error {
 	}
 
 	a.filter.AddAddr(addr)
-	return a.db.Put(toKey(BKTAddrs, addr[:]...), addr[:], nil)
+	return a.db.Put(toKey(BKTAddrs, addr[:]...), addr[:])
 }
 
 func (a *addrs) GetAll() []*common.Uint168 {
@@ -60,7 +59,7 @@ func (a *addrs) GetAll() []*common.Uint168 {
 }
 
 func (a *addrs) getAll() (addrs []*common.Uint168, err error) {
-	it := a.db.NewIterator(util.BytesPrefix(BKTAddrs), nil)
+	it := a.db.NewIterator(BKTAddrs)
 	defer it.Release()
 	for it.Next() {
 		addr, err := common.Uint168FromBytes(it.Value())
@@ -77,13 +76,13 @@ func (a *addrs) Clear() error {
 	a.Lock()
 	defer a.Unlock()
 
-	it := a.db.NewIterator(util.BytesPrefix(BKTAddrs), nil)
-	defer it.Release()
-	batch := new(leveldb.Batch)
+	it := a.db.NewIterator(BKTAddrs)
+	batch := a.db.NewBatch()
 	for it.Next() {
 		batch.Delete(it.Key())
 	}
-	return a.db.Write(batch, nil)
+	it.Release()
+	return a.db.Write(batch)
 }
 
 func (a *addrs) Close() error {
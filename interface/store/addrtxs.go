@@ -0,0 +1,159 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+)
+
+var (
+	// BKTAddrTxs indexes transactions by address, the key is address +
+	// height + transaction id, so the transactions of an address are sorted
+	// by height.
+	BKTAddrTxs = []byte("X")
+
+	// BKTTxAddrs records the addresses indexed for a transaction, which are
+	// used to delete the index when the transaction is deleted.
+	BKTTxAddrs = []byte("Y")
+)
+
+func addrTxKey(addr *common.Uint168, height uint32, txId *common.Uint256) []byte {
+	key := toKey(BKTAddrTxs, addr[:]...)
+	var h [4]byte
+	binary.BigEndian.PutUint32(h[:], height)
+	key = append(key, h[:]...)
+	return append(key, txId[:]...)
+}
+
+// putAddrIndex adds the index of the transaction for the given addresses.
+func putAddrIndex(batch kvdb.Batch, tx *util.Tx, addrs []common.Uint168) {
+	if len(addrs) == 0 {
+		return
+	}
+
+	data := make([]byte, 0, len(addrs)*len(common.Uint168{}))
+	for i := range addrs {
+		batch.Put(addrTxKey(&addrs[i], tx.Height, &tx.Hash), nil)
+		data = append(data, addrs[i][:]...)
+	}
+	batch.Put(toKey(BKTTxAddrs, tx.Hash[:]...), data)
+}
+
+// delAddrIndex deletes the address index of the transaction on the given
+// height.
+func delAddrIndex(db kvdb.DB, batch kvdb.Batch, txId *common.Uint256,
+	height uint32) {
+
+	data, err := db.Get(toKey(BKTTxAddrs, txId[:]...))
+	if err != nil {
+		return
+	}
+
+	size := len(common.Uint168{})
+	for i := 0; i+size <= len(data); i += size {
+		var addr common.Uint168
+		copy(addr[:], data[i:i+size])
+		batch.Delete(addrTxKey(&addr, height, txId))
+	}
+	batch.Delete(toKey(BKTTxAddrs, txId[:]...))
+}
+
+// getAddrTxIds returns the transaction ids of the address, sorted by height in
+// ascending order, or descending order if desc is true.  Limit 0 returns all
+// transactions from offset.
+func getAddrTxIds(db kvdb.DB, addr *common.Uint168, offset, limit uint32,
+	desc bool) ([]*common.Uint256, error) {
+
+	prefix := toKey(BKTAddrTxs, addr[:]...)
+	it := db.NewIterator(prefix)
+	defer it.Release()
+
+	// The iterator only moves forward, collect all ids to page in
+	// descending order.
+	var txIds []*common.Uint256
+	for it.Next() {
+		var txId common.Uint256
+		copy(txId[:], it.Key()[len(prefix)+4:])
+		txIds = append(txIds, &txId)
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+
+	if desc {
+		for i, j := 0, len(txIds)-1; i < j; i, j = i+1, j-1 {
+			txIds[i], txIds[j] = txIds[j], txIds[i]
+		}
+	}
+
+	if offset >= uint32(len(txIds)) {
+		return nil, nil
+	}
+	txIds = txIds[offset:]
+	if limit > 0 && limit < uint32(len(txIds)) {
+		txIds = txIds[:limit]
+	}
+	return txIds, nil
+}
+
+// buildAddrIndex indexes the stored transactions by the addresses of their
+// outputs and the outputs they spent.
+func buildAddrIndex(db kvdb.DB) error {
+	addrs, err := NewAddrs(db)
+	if err != nil {
+		return err
+	}
+	filter := addrs.GetFilter()
+
+	batch := db.NewBatch()
+	it := db.NewIterator(BKTTxs)
+	for it.Next() {
+		var utx util.Tx
+		if err := utx.Deserialize(bytes.NewReader(it.Value())); err != nil {
+			it.Release()
+			return err
+		}
+
+		var tx types.Transaction
+		if err := tx.Deserialize(bytes.NewReader(utx.RawData)); err != nil {
+			it.Release()
+			return err
+		}
+
+		hits := make(map[common.Uint168]struct{})
+		for _, output := range tx.Outputs {
+			if filter.ContainAddr(output.ProgramHash) {
+				hits[output.ProgramHash] = struct{}{}
+			}
+		}
+		for _, input := range tx.Inputs {
+			op := util.NewOutPoint(input.Previous.TxID, input.Previous.Index)
+			data, err := db.Get(toKey(BKTOps, op.Bytes()...))
+			if err != nil {
+				continue
+			}
+			addr, err := common.Uint168FromBytes(data)
+			if err != nil {
+				continue
+			}
+			hits[*addr] = struct{}{}
+		}
+
+		index := make([]common.Uint168, 0, len(hits))
+		for addr := range hits {
+			index = append(index, addr)
+		}
+		putAddrIndex(batch, &utx, index)
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	return db.Write(batch)
+}
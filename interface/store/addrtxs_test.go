@@ -0,0 +1,91 @@
+package store
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxs_GetByAddress(t *testing.T) {
+	db, err := NewMemDataStore()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+
+	var addr1, addr2 common.Uint168
+	rand.Read(addr1[:])
+	rand.Read(addr2[:])
+
+	// DelAll decodes the raw transaction to delete it's outputs.
+	buf := new(bytes.Buffer)
+	raw := types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+	}
+	if !assert.NoError(t, raw.Serialize(buf)) {
+		t.FailNow()
+	}
+
+	// Transactions on height 1 to 10, addr1 in all of them and addr2 in
+	// the even heights.
+	var txs []*util.Tx
+	batch := db.Batch()
+	for height := uint32(1); height <= 10; height++ {
+		tx := &util.Tx{Height: height, RawData: buf.Bytes()}
+		rand.Read(tx.Hash[:])
+		txs = append(txs, tx)
+
+		addrs := []common.Uint168{addr1}
+		if height%2 == 0 {
+			addrs = append(addrs, addr2)
+		}
+		if !assert.NoError(t, batch.Txs().Put(tx)) ||
+			!assert.NoError(t, batch.Txs().PutAddrIndex(tx, addrs)) {
+			t.FailNow()
+		}
+	}
+	if !assert.NoError(t, batch.Commit()) {
+		t.FailNow()
+	}
+
+	result, err := db.Txs().GetByAddress(&addr1, 2, 3, false)
+	if !assert.NoError(t, err) || !assert.Equal(t, 3, len(result)) {
+		t.FailNow()
+	}
+	for i, tx := range result {
+		assert.Equal(t, txs[i+2].Hash, tx.Hash)
+	}
+
+	result, err = db.Txs().GetByAddress(&addr2, 0, 0, true)
+	if !assert.NoError(t, err) || !assert.Equal(t, 5, len(result)) {
+		t.FailNow()
+	}
+	assert.Equal(t, uint32(10), result[0].Height)
+	assert.Equal(t, uint32(2), result[4].Height)
+
+	result, err = db.Txs().GetByAddress(&addr1, 10, 0, false)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 0, len(result))
+
+	// Rollback height 10 removes the index.
+	batch = db.Batch()
+	if !assert.NoError(t, batch.DelAll(10)) ||
+		!assert.NoError(t, batch.Commit()) {
+		t.FailNow()
+	}
+	result, err = db.Txs().GetByAddress(&addr2, 0, 0, true)
+	if !assert.NoError(t, err) || !assert.Equal(t, 4, len(result)) {
+		t.FailNow()
+	}
+	assert.Equal(t, uint32(8), result[0].Height)
+}
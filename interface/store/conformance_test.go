@@ -0,0 +1,236 @@
+package store
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/database/dbtest"
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+// headerStores creates an empty header store of every backend.
+var headerStores = []struct {
+	name string
+	new  func(t *testing.T) (*headers, error)
+}{
+	{"LevelDB", func(t *testing.T) (*headers, error) {
+		return NewHeaderStore(t.TempDir(), kvdb.LevelDB, newBlockHeader)
+	}},
+	{"BoltDB", func(t *testing.T) (*headers, error) {
+		return NewHeaderStore(t.TempDir(), kvdb.BoltDB, newBlockHeader)
+	}},
+	{"Mem", func(t *testing.T) (*headers, error) {
+		return NewMemHeaderStore(newBlockHeader)
+	}},
+}
+
+func TestHeaderStoreConformance(t *testing.T) {
+	for _, store := range headerStores {
+		store := store
+		t.Run(store.name, func(t *testing.T) {
+			dbtest.TestHeaders(t, func(t *testing.T) (database.Headers, error) {
+				return store.new(t)
+			})
+		})
+	}
+}
+
+func TestChainStoreConformance(t *testing.T) {
+	for _, store := range headerStores {
+		store := store
+		t.Run(store.name, func(t *testing.T) {
+			dbtest.TestChainStore(t, func(t *testing.T) (database.ChainStore, error) {
+				h, err := store.new(t)
+				if err != nil {
+					return nil, err
+				}
+				return database.NewChainDB(h, database.NewMemTxsDB(),
+					database.NewMemJournal(), nil)
+			})
+		})
+	}
+}
+
+// newConformanceTx creates a transaction paying to addr on the given height.
+func newConformanceTx(t *testing.T, addr common.Uint168,
+	height uint32) (*types.Transaction, *util.Tx) {
+	var nonce [8]byte
+	rand.Read(nonce[:])
+	attr := types.NewAttribute(types.Nonce, nonce[:])
+	tx := &types.Transaction{
+		TxType:     types.TransferAsset,
+		Payload:    &payload.TransferAsset{},
+		Attributes: []*types.Attribute{&attr},
+		Outputs:    []*types.Output{{ProgramHash: addr, Value: 100}},
+	}
+	buf := new(bytes.Buffer)
+	if !assert.NoError(t, tx.Serialize(buf)) {
+		t.FailNow()
+	}
+	return tx, &util.Tx{Hash: tx.Hash(), Height: height, RawData: buf.Bytes()}
+}
+
+// putConformanceTx puts the transaction and its outputs in the batch.
+func putConformanceTx(t *testing.T, batch DataBatch, addr common.Uint168,
+	tx *types.Transaction, utx *util.Tx) {
+	op := util.NewOutPoint(utx.Hash, 0)
+	if !assert.NoError(t, batch.Txs().Put(utx)) ||
+		!assert.NoError(t, batch.Ops().Put(op, addr)) ||
+		!assert.NoError(t, batch.UTXOs().PutTx(tx, utx.Height,
+			[]common.Uint168{addr})) {
+		t.FailNow()
+	}
+}
+
+var dataStoreTests = []struct {
+	name string
+	test func(t *testing.T, db DataStore)
+}{
+	{"Addrs", func(t *testing.T, db DataStore) {
+		var addr common.Uint168
+		rand.Read(addr[:])
+		if !assert.NoError(t, db.Addrs().Put(&addr)) ||
+			!assert.NoError(t, db.Addrs().Put(&addr)) {
+			t.FailNow()
+		}
+		assert.Equal(t, []*common.Uint168{&addr}, db.Addrs().GetAll())
+		assert.True(t, db.Addrs().GetFilter().ContainAddr(addr))
+	}},
+	{"BatchCommit", func(t *testing.T, db DataStore) {
+		var addr common.Uint168
+		rand.Read(addr[:])
+		tx, utx := newConformanceTx(t, addr, 1)
+		batch := db.Batch()
+		putConformanceTx(t, batch, addr, tx, utx)
+
+		// Nothing is written before commit.
+		_, err := db.Txs().Get(&utx.Hash)
+		assert.Error(t, err)
+		if !assert.NoError(t, batch.Commit()) {
+			t.FailNow()
+		}
+
+		stored, err := db.Txs().Get(&utx.Hash)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, utx.RawData, stored.RawData)
+		assert.Equal(t, utx.Height, stored.Height)
+		txIds, err := db.Txs().GetIds(1)
+		assert.NoError(t, err)
+		assert.Equal(t, []*common.Uint256{&utx.Hash}, txIds)
+
+		op := util.NewOutPoint(utx.Hash, 0)
+		if assert.NotNil(t, db.Ops().HaveOp(op)) {
+			assert.Equal(t, addr, *db.Ops().HaveOp(op))
+		}
+		utxo, err := db.UTXOs().Get(op)
+		if assert.NoError(t, err) {
+			assert.Equal(t, common.Fixed64(100), utxo.Value)
+		}
+	}},
+	{"BatchRollback", func(t *testing.T, db DataStore) {
+		var addr common.Uint168
+		rand.Read(addr[:])
+		tx, utx := newConformanceTx(t, addr, 1)
+		batch := db.Batch()
+		putConformanceTx(t, batch, addr, tx, utx)
+		if !assert.NoError(t, batch.Rollback()) ||
+			!assert.NoError(t, batch.Commit()) {
+			t.FailNow()
+		}
+
+		_, err := db.Txs().Get(&utx.Hash)
+		assert.Error(t, err)
+		txIds, err := db.Txs().GetIds(1)
+		assert.NoError(t, err)
+		assert.Empty(t, txIds)
+		assert.Nil(t, db.Ops().HaveOp(util.NewOutPoint(utx.Hash, 0)))
+	}},
+	{"DelAll", func(t *testing.T, db DataStore) {
+		var addr common.Uint168
+		rand.Read(addr[:])
+		tx1, utx1 := newConformanceTx(t, addr, 1)
+		tx2, utx2 := newConformanceTx(t, addr, 2)
+		batch := db.Batch()
+		putConformanceTx(t, batch, addr, tx1, utx1)
+		putConformanceTx(t, batch, addr, tx2, utx2)
+		if !assert.NoError(t, batch.Commit()) {
+			t.FailNow()
+		}
+
+		batch = db.Batch()
+		if !assert.NoError(t, batch.DelAll(2)) ||
+			!assert.NoError(t, batch.Commit()) {
+			t.FailNow()
+		}
+
+		_, err := db.Txs().Get(&utx1.Hash)
+		assert.NoError(t, err)
+		_, err = db.Txs().Get(&utx2.Hash)
+		assert.Error(t, err)
+		txIds, err := db.Txs().GetIds(2)
+		assert.NoError(t, err)
+		assert.Empty(t, txIds)
+		assert.NotNil(t, db.Ops().HaveOp(util.NewOutPoint(utx1.Hash, 0)))
+		assert.Nil(t, db.Ops().HaveOp(util.NewOutPoint(utx2.Hash, 0)))
+		_, err = db.UTXOs().Get(util.NewOutPoint(utx2.Hash, 0))
+		assert.Error(t, err)
+	}},
+	{"Clear", func(t *testing.T, db DataStore) {
+		var addr common.Uint168
+		rand.Read(addr[:])
+		tx, utx := newConformanceTx(t, addr, 1)
+		batch := db.Batch()
+		putConformanceTx(t, batch, addr, tx, utx)
+		if !assert.NoError(t, batch.Commit()) ||
+			!assert.NoError(t, db.Clear()) {
+			t.FailNow()
+		}
+
+		_, err := db.Txs().Get(&utx.Hash)
+		assert.Error(t, err)
+		txIds, err := db.Txs().GetIds(1)
+		assert.NoError(t, err)
+		assert.Empty(t, txIds)
+		assert.Nil(t, db.Ops().HaveOp(util.NewOutPoint(utx.Hash, 0)))
+	}},
+}
+
+func TestDataStoreConformance(t *testing.T) {
+	stores := []struct {
+		name string
+		new  func(t *testing.T) (*dataStore, error)
+	}{
+		{"LevelDB", func(t *testing.T) (*dataStore, error) {
+			return NewDataStore(t.TempDir(), kvdb.LevelDB)
+		}},
+		{"BoltDB", func(t *testing.T) (*dataStore, error) {
+			return NewDataStore(t.TempDir(), kvdb.BoltDB)
+		}},
+		{"Mem", func(t *testing.T) (*dataStore, error) {
+			return NewMemDataStore()
+		}},
+	}
+	for _, store := range stores {
+		for _, test := range dataStoreTests {
+			store, test := store, test
+			t.Run(store.name+"/"+test.name, func(t *testing.T) {
+				db, err := store.new(t)
+				if !assert.NoError(t, err) {
+					t.FailNow()
+				}
+				defer db.Close()
+				test.test(t, db)
+			})
+		}
+	}
+}
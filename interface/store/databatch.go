@@ -5,10 +5,10 @@ import (
 	"encoding/binary"
 	"sync"
 
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
 	"github.com/elastos/Elastos.ELA.SPV/util"
 
 	"github.com/elastos/Elastos.ELA/core/types"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 // Ensure dataBatch implement DataBatch interface.
@@ -16,18 +16,42 @@ var _ DataBatch = (*dataBatch)(nil)
 
 type dataBatch struct {
 	mutex sync.Mutex
-	*leveldb.DB
-	*leveldb.Batch
+	kvdb.DB
+	kvdb.Batch
+	txs   *txsBatch
+	utxos *utxosBatch
 }
 
 func (b *dataBatch) Txs() TxsBatch {
-	return &txsBatch{DB: b.DB, Batch: b.Batch}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	// Transactions batch is kept to write the height index on commit.
+	if b.txs == nil {
+		b.txs = &txsBatch{DB: b.DB, Batch: b.Batch}
+	}
+	return b.txs
 }
 
 func (b *dataBatch) Ops() OpsBatch {
 	return &opsBatch{DB: b.DB, Batch: b.Batch}
 }
 
+func (b *dataBatch) UTXOs() UTXOsBatch {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.getUTXOs()
+}
+
+// getUTXOs returns the UTXOs batch kept in this batch, so transactions can
+// spend the outputs put in this batch.
+func (b *dataBatch) getUTXOs() *utxosBatch {
+	if b.utxos == nil {
+		b.utxos = &utxosBatch{DB: b.DB, Batch: b.Batch}
+	}
+	return b.utxos
+}
+
 func (b *dataBatch) Que() QueBatch {
 	return &queBatch{DB: b.DB, Batch: b.Batch}
 }
@@ -39,10 +63,10 @@ func (b *dataBatch) DelAll(height uint32) error {
 
 	var key [4]byte
 	binary.BigEndian.PutUint32(key[:], height)
-	data, _ := b.DB.Get(toKey(BKTHeightTxs, key[:]...), nil)
+	data, _ := b.DB.Get(toKey(BKTHeightTxs, key[:]...))
 	for _, txId := range getTxIds(data) {
 		var utx util.Tx
-		data, err := b.DB.Get(toKey(BKTTxs, txId.Bytes()...), nil)
+		data, err := b.DB.Get(toKey(BKTTxs, txId.Bytes()...))
 		if err != nil {
 			return err
 		}
@@ -56,24 +80,37 @@ func (b *dataBatch) DelAll(height uint32) error {
 			return err
 		}
 
+		// Delete the outputs and restore the outputs spent by transaction.
+		if err := b.getUTXOs().DelTx(&tx); err != nil {
+			return err
+		}
+
 		for index := range tx.Outputs {
 			outpoint := types.NewOutPoint(utx.Hash, uint16(index))
 			b.Batch.Delete(toKey(BKTOps, outpoint.Bytes()...))
 		}
 
 		b.Batch.Delete(toKey(BKTTxs, txId.Bytes()...))
+		delAddrIndex(b.DB, b.Batch, txId, height)
 	}
 
 	b.Batch.Delete(toKey(BKTHeightTxs, key[:]...))
 
+	if err := revokeAll(b.DB, b.Batch, height); err != nil {
+		return err
+	}
 	return b.Que().DelAll(height)
 }
 
 func (b *dataBatch) Commit() error {
-	return b.DB.Write(b.Batch, nil)
+	if b.txs != nil {
+		b.txs.index()
+	}
+	return b.DB.Write(b.Batch)
 }
 
 func (b *dataBatch) Rollback() error {
+	b.txs, b.utxos = nil, nil
 	b.Batch.Reset()
 	return nil
 }
@@ -3,11 +3,13 @@ package store
 import (
 	"testing"
 
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+
 	"github.com/stretchr/testify/assert"
 )
 
 func TestDataBatch_DelAll(t *testing.T) {
-	db, err := NewDataStore("test")
+	db, err := NewDataStore("test", kvdb.LevelDB)
 	if !assert.NoError(t, err) {
 		t.FailNow()
 	}
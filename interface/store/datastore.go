@@ -1,10 +1,12 @@
 package store
 
 import (
+	"bytes"
 	"path/filepath"
 	"sync"
 
-	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
 )
 
 // Ensure dataStore implement DataStore interface.
@@ -12,19 +14,43 @@ var _ DataStore = (*dataStore)(nil)
 
 type dataStore struct {
 	sync.RWMutex
-	db    *leveldb.DB
+	db    kvdb.DB
 	addrs *addrs
 	txs   *txs
 	ops   *ops
+	utxos *utxos
 	que   *que
+	lsns  *listeners
+	ntfd  *notified
+	dead  *deadLetters
 }
 
-func NewDataStore(dataDir string) (*dataStore, error) {
-	db, err := leveldb.OpenFile(filepath.Join(dataDir, "store"), nil)
+// NewDataStore opens or creates a DataStore in dataDir, using the given
+// key-value database backend.
+func NewDataStore(dataDir string, backend kvdb.Backend) (*dataStore, error) {
+	db, err := kvdb.Open(backend, filepath.Join(dataDir, "store"))
 	if err != nil {
 		return nil, err
 	}
+
+	return newDataStore(db)
+}
+
+// NewMemDataStore creates a DataStore that keeps all data in memory, all data
+// will be lost after the process exits.
+func NewMemDataStore() (*dataStore, error) {
+	db, err := kvdb.NewMemDB()
+	if err != nil {
+		return nil, err
+	}
+
+	return newDataStore(db)
+}
+
+func newDataStore(db kvdb.DB) (*dataStore, error) {
+	err := database.Upgrade("data", &version{db: db}, dataMigrations(db))
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
 
@@ -38,7 +64,11 @@ func NewDataStore(dataDir string) (*dataStore, error) {
 		addrs: addrs,
 		txs:   NewTxs(db),
 		ops:   NewOps(db),
+		utxos: NewUTXOs(db),
 		que:   NewQue(db),
+		lsns:  NewListeners(db),
+		ntfd:  NewNotified(db),
+		dead:  NewDeadLetters(db),
 	}, nil
 }
 
@@ -54,14 +84,30 @@ func (d *dataStore) Ops() Ops {
 	return d.ops
 }
 
+func (d *dataStore) UTXOs() UTXOs {
+	return d.utxos
+}
+
 func (d *dataStore) Que() Que {
 	return d.que
 }
 
+func (d *dataStore) Listeners() Listeners {
+	return d.lsns
+}
+
+func (d *dataStore) Notified() Notified {
+	return d.ntfd
+}
+
+func (d *dataStore) DeadLetters() DeadLetters {
+	return d.dead
+}
+
 func (d *dataStore) Batch() DataBatch {
 	return &dataBatch{
 		DB:    d.db,
-		Batch: new(leveldb.Batch),
+		Batch: d.db.NewBatch(),
 	}
 }
 
@@ -71,14 +117,18 @@ func (d *dataStore) Clear() error {
 
 	d.que.Clear()
 
-	it := d.db.NewIterator(nil, nil)
-	batch := new(leveldb.Batch)
+	it := d.db.NewIterator(nil)
+	batch := d.db.NewBatch()
 	for it.Next() {
+		// Keep the data format version.
+		if bytes.Equal(it.Key(), BKTVersion) {
+			continue
+		}
 		batch.Delete(it.Key())
 	}
 	it.Release()
 
-	return d.db.Write(batch, nil)
+	return d.db.Write(batch)
 }
 
 // Close db
@@ -89,6 +139,10 @@ func (d *dataStore) Close() error {
 	d.addrs.Close()
 	d.txs.Close()
 	d.ops.Close()
+	d.utxos.Close()
 	d.que.Close()
+	d.lsns.Close()
+	d.ntfd.Close()
+	d.dead.Close()
 	return d.db.Close()
 }
@@ -0,0 +1,112 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+var (
+	// BKTDeadLetters stores the queue items given up after the max delivery
+	// attempts, the key is notify id + transaction id.
+	BKTDeadLetters = []byte("Z")
+)
+
+// Ensure deadLetters implement DeadLetters interface.
+var _ DeadLetters = (*deadLetters)(nil)
+
+type deadLetters struct {
+	sync.RWMutex
+	db kvdb.DB
+}
+
+func NewDeadLetters(db kvdb.DB) *deadLetters {
+	return &deadLetters{db: db}
+}
+
+func deadLetterKey(notifyId, txId *common.Uint256) []byte {
+	key := toKey(BKTDeadLetters, notifyId[:]...)
+	return append(key, txId[:]...)
+}
+
+func decodeDeadLetter(key, value []byte) *DeadLetter {
+	var letter DeadLetter
+	key = subKey(BKTDeadLetters, key)
+	copy(letter.NotifyId[:], key[:32])
+	copy(letter.TxId[:], key[32:])
+	var lastNotify, deadTime int64
+	buf := bytes.NewReader(value)
+	binary.Read(buf, binary.BigEndian, &letter.Height)
+	binary.Read(buf, binary.BigEndian, &letter.Attempts)
+	binary.Read(buf, binary.BigEndian, &lastNotify)
+	binary.Read(buf, binary.BigEndian, &deadTime)
+	letter.LastNotify = time.Unix(lastNotify, 0)
+	letter.Time = time.Unix(deadTime, 0)
+	return &letter
+}
+
+func (d *deadLetters) Put(letter *DeadLetter) error {
+	d.Lock()
+	defer d.Unlock()
+
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, letter.Height)
+	binary.Write(buf, binary.BigEndian, letter.Attempts)
+	binary.Write(buf, binary.BigEndian, letter.LastNotify.Unix())
+	binary.Write(buf, binary.BigEndian, letter.Time.Unix())
+	return d.db.Put(deadLetterKey(&letter.NotifyId, &letter.TxId), buf.Bytes())
+}
+
+func (d *deadLetters) Get(notifyId, txId *common.Uint256) (*DeadLetter, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	key := deadLetterKey(notifyId, txId)
+	value, err := d.db.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDeadLetter(key, value), nil
+}
+
+func (d *deadLetters) GetAll() ([]*DeadLetter, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	it := d.db.NewIterator(BKTDeadLetters)
+	defer it.Release()
+	var letters []*DeadLetter
+	for it.Next() {
+		letters = append(letters, decodeDeadLetter(it.Key(), it.Value()))
+	}
+	return letters, it.Error()
+}
+
+func (d *deadLetters) Del(notifyId, txId *common.Uint256) error {
+	d.Lock()
+	defer d.Unlock()
+	return d.db.Delete(deadLetterKey(notifyId, txId))
+}
+
+func (d *deadLetters) Clear() error {
+	d.Lock()
+	defer d.Unlock()
+
+	it := d.db.NewIterator(BKTDeadLetters)
+	batch := d.db.NewBatch()
+	for it.Next() {
+		batch.Delete(it.Key())
+	}
+	it.Release()
+	return d.db.Write(batch)
+}
+
+func (d *deadLetters) Close() error {
+	d.Lock()
+	return nil
+}
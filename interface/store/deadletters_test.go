@@ -0,0 +1,63 @@
+package store
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLetters(t *testing.T) {
+	db, err := NewMemDataStore()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+
+	now := time.Unix(time.Now().Unix(), 0)
+	letters := make(map[common.Uint256]*DeadLetter)
+	for i := 0; i < 10; i++ {
+		letter := &DeadLetter{
+			QueItem: QueItem{
+				Height:     uint32(i),
+				LastNotify: now.Add(-time.Minute),
+				Attempts:   uint32(i + 1),
+			},
+			Time: now,
+		}
+		rand.Read(letter.NotifyId[:])
+		rand.Read(letter.TxId[:])
+		if !assert.NoError(t, db.DeadLetters().Put(letter)) {
+			t.FailNow()
+		}
+		letters[letter.TxId] = letter
+	}
+
+	all, err := db.DeadLetters().GetAll()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Equal(t, len(letters), len(all)) {
+		t.FailNow()
+	}
+	for _, letter := range all {
+		assert.Equal(t, letters[letter.TxId], letter)
+	}
+
+	for _, letter := range letters {
+		got, err := db.DeadLetters().Get(&letter.NotifyId, &letter.TxId)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, letter, got)
+
+		err = db.DeadLetters().Del(&letter.NotifyId, &letter.TxId)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		_, err = db.DeadLetters().Get(&letter.NotifyId, &letter.TxId)
+		assert.Error(t, err)
+	}
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
@@ -8,11 +9,12 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
 	"github.com/elastos/Elastos.ELA.SPV/util"
 
 	"github.com/cevaris/ordered_map"
 	"github.com/elastos/Elastos.ELA/common"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 var (
@@ -26,17 +28,42 @@ var _ HeaderStore = (*headers)(nil)
 
 type headers struct {
 	*sync.RWMutex
-	db        *leveldb.DB
+	db        kvdb.DB
 	cache     *cache
 	newHeader func() util.BlockHeader
 }
 
-func NewHeaderStore(dataDir string, newHeader func() util.BlockHeader) (*headers, error) {
-	db, err := leveldb.OpenFile(filepath.Join(dataDir, "header"), nil)
+// NewHeaderStore opens or creates a HeaderStore in dataDir, using the given
+// key-value database backend.
+func NewHeaderStore(dataDir string, backend kvdb.Backend,
+	newHeader func() util.BlockHeader) (*headers, error) {
+	db, err := kvdb.Open(backend, filepath.Join(dataDir, "header"))
 	if err != nil {
 		return nil, err
 	}
 
+	return newHeaderStore(db, newHeader)
+}
+
+// NewMemHeaderStore creates a HeaderStore that keeps all headers in memory,
+// all data will be lost after the process exits.
+func NewMemHeaderStore(newHeader func() util.BlockHeader) (*headers, error) {
+	db, err := kvdb.NewMemDB()
+	if err != nil {
+		return nil, err
+	}
+
+	return newHeaderStore(db, newHeader)
+}
+
+func newHeaderStore(db kvdb.DB, newHeader func() util.BlockHeader) (*headers, error) {
+	err := database.Upgrade("headers", &version{db: db},
+		headersMigrations(db, newHeader))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	headers := &headers{
 		RWMutex:   new(sync.RWMutex),
 		db:        db,
@@ -72,10 +99,6 @@ func (h *headers) Put(header *util.Header, newTip bool) error {
 	h.Lock()
 	defer h.Unlock()
 
-	h.cache.set(header)
-	if newTip {
-		h.cache.tip = header
-	}
 	key := toKey(BKTHeaders, header.Hash().Bytes()...)
 
 	bytes, err := header.Serialize()
@@ -83,22 +106,82 @@ func (h *headers) Put(header *util.Header, newTip bool) error {
 		return err
 	}
 
-	err = h.db.Put(key, bytes, nil)
-	if err != nil {
+	// Write header, chain tip and height index in one batch, so they are
+	// updated atomically.  A new tip is synced, so it is on disk before the
+	// block commit is removed from journal.
+	batch := h.db.NewBatch()
+	batch.Put(key, bytes)
+	write := h.db.Write
+	if newTip {
+		batch.Put(BKTChainTip, bytes)
+		h.index(batch, header)
+		write = h.db.WriteSync
+	}
+	if err := write(batch); err != nil {
 		return err
 	}
 
+	h.cache.set(header)
 	if newTip {
-		err = h.db.Put(BKTChainTip, bytes, nil)
+		h.cache.tip = header
+	}
+	return nil
+}
+
+// index updates the height index to the main chain ending with tip.  Heights
+// above tip are removed, and heights of the previous main chain are replaced
+// back to the common ancestor, or a missing header.
+func (h *headers) index(batch kvdb.Batch, tip *util.Header) {
+	if h.cache.tip != nil {
+		for height := h.cache.tip.Height; height > tip.Height; height-- {
+			batch.Delete(indexKey(height))
+		}
+	}
+
+	batch.Put(indexKey(tip.Height), tip.Hash().Bytes())
+	for header := tip; header.Height > 0; {
+		prev := header.Previous()
+		hash, err := h.db.Get(indexKey(header.Height - 1))
+		if err == nil && bytes.Equal(hash, prev.Bytes()) {
+			break
+		}
+
+		header, err = h.get(&prev)
 		if err != nil {
-			return err
+			break
 		}
+		batch.Put(indexKey(header.Height), prev.Bytes())
+	}
+}
+
+// rebuildIndex deletes the height index and indexes the main chain from the
+// chain tip to genesis again.
+func rebuildIndex(db kvdb.DB, newHeader func() util.BlockHeader) error {
+	batch := db.NewBatch()
+	it := db.NewIterator(BKTIndexes)
+	for it.Next() {
+		batch.Delete(it.Key())
+	}
+	it.Release()
+	if err := db.Write(batch); err != nil {
+		return err
+	}
+
+	h := &headers{
+		RWMutex:   new(sync.RWMutex),
+		db:        db,
+		cache:     newCache(100),
+		newHeader: newHeader,
+	}
+	tip, err := h.getHeader(BKTChainTip)
+	if err != nil {
+		// Nothing to index in an empty database.
+		return nil
 	}
 
-	var height [4]byte
-	binary.LittleEndian.PutUint32(height[:], header.Height)
-	index := toKey(BKTIndexes, height[:]...)
-	return h.db.Put(index, header.Hash().Bytes(), nil)
+	batch = db.NewBatch()
+	h.index(batch, tip)
+	return db.Write(batch)
 }
 
 func (h *headers) GetPrevious(header *util.Header) (*util.Header, error) {
@@ -110,12 +193,7 @@ func (h *headers) Get(hash *common.Uint256) (header *util.Header, err error) {
 	h.RLock()
 	defer h.RUnlock()
 
-	header, err = h.cache.get(hash)
-	if err == nil {
-		return header, nil
-	}
-
-	return h.getHeader(toKey(BKTHeaders, hash.Bytes()...))
+	return h.get(hash)
 }
 
 func (h *headers) GetBest() (header *util.Header, err error) {
@@ -129,36 +207,57 @@ func (h *headers) GetBest() (header *util.Header, err error) {
 	return h.getHeader(BKTChainTip)
 }
 
+func (h *headers) Del(hash *common.Uint256) error {
+	h.Lock()
+	defer h.Unlock()
+
+	h.cache.del(hash)
+	return h.db.Delete(toKey(BKTHeaders, hash.Bytes()...))
+}
+
 func (h *headers) GetByHeight(height uint32) (header *util.Header, err error) {
 	h.RLock()
 	defer h.RUnlock()
 
-	var key [4]byte
-	binary.LittleEndian.PutUint32(key[:], height)
-	hashBytes, err := h.db.Get(toKey(BKTIndexes, key[:]...), nil)
+	hashBytes, err := h.db.Get(indexKey(height))
 	if err != nil {
 		return nil, err
 	}
 
-	header, err = h.getHeader(toKey(BKTHeaders, hashBytes...))
+	hash, err := common.Uint256FromBytes(hashBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	return header, err
+	return h.get(hash)
+}
+
+func (h *headers) GetRange(from, to uint32) ([]*util.Header, error) {
+	return database.GetHeadersRange(h, from, to)
+}
+
+func (h *headers) Iterate(from uint32, fn func(header *util.Header) bool) error {
+	return database.IterateHeaders(h, from, fn)
 }
 
 func (h *headers) Clear() error {
 	h.Lock()
 	defer h.Unlock()
 
-	batch := new(leveldb.Batch)
-	inter := h.db.NewIterator(nil, nil)
+	// Reset cache, or the cached chain tip will be returned.
+	h.cache = newCache(h.cache.size)
+
+	batch := h.db.NewBatch()
+	inter := h.db.NewIterator(nil)
 	for inter.Next() {
+		// Keep the data format version.
+		if bytes.Equal(inter.Key(), BKTVersion) {
+			continue
+		}
 		batch.Delete(inter.Key())
 	}
 	inter.Release()
-	return h.db.Write(batch, nil)
+	return h.db.Write(batch)
 }
 
 // Close db
@@ -168,8 +267,17 @@ func (h *headers) Close() error {
 	return h.db.Close()
 }
 
+func (h *headers) get(hash *common.Uint256) (*util.Header, error) {
+	header, err := h.cache.get(hash)
+	if err == nil {
+		return header, nil
+	}
+
+	return h.getHeader(toKey(BKTHeaders, hash.Bytes()...))
+}
+
 func (h *headers) getHeader(key []byte) (*util.Header, error) {
-	data, err := h.db.Get(key, nil)
+	data, err := h.db.Get(key)
 	if err != nil {
 		return nil, fmt.Errorf("header %s does not exist in database",
 			hex.EncodeToString(key))
@@ -213,6 +321,10 @@ func (cache *cache) set(header *util.Header) {
 	cache.headers.Set(header.Hash().String(), header)
 }
 
+func (cache *cache) del(hash *common.Uint256) {
+	cache.headers.Delete(hash.String())
+}
+
 func (cache *cache) get(hash *common.Uint256) (*util.Header, error) {
 	sh, ok := cache.headers.Get(hash.String())
 	if !ok {
@@ -221,6 +333,12 @@ func (cache *cache) get(hash *common.Uint256) (*util.Header, error) {
 	return sh.(*util.Header), nil
 }
 
+func indexKey(height uint32) []byte {
+	var key [4]byte
+	binary.LittleEndian.PutUint32(key[:], height)
+	return toKey(BKTIndexes, key[:]...)
+}
+
 func toKey(bucket []byte, index ...byte) []byte {
 	return append(bucket, index...)
 }
@@ -0,0 +1,123 @@
+package store
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBlockHeader() util.BlockHeader {
+	return iutil.NewHeader(&types.Header{})
+}
+
+func newTestHeader(prev *util.Header, nonce uint32) *util.Header {
+	header := &types.Header{Nonce: nonce}
+	if prev == nil {
+		return &util.Header{BlockHeader: iutil.NewHeader(header),
+			TotalWork: new(big.Int)}
+	}
+	header.Previous = prev.Hash()
+	header.Height = prev.Height + 1
+	return &util.Header{
+		BlockHeader: iutil.NewHeader(header),
+		Height:      prev.Height + 1,
+		TotalWork:   new(big.Int).Add(prev.TotalWork, big.NewInt(1)),
+	}
+}
+
+func TestHeaders_GetByHeight(t *testing.T) {
+	db, err := NewMemHeaderStore(newBlockHeader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+
+	// Main chain 0 <- 1 <- 2 <- 3 <- 4
+	main := []*util.Header{newTestHeader(nil, 0)}
+	for i := 1; i < 5; i++ {
+		main = append(main, newTestHeader(main[i-1], 0))
+	}
+	for _, header := range main {
+		if !assert.NoError(t, db.Put(header, true)) {
+			t.FailNow()
+		}
+	}
+
+	// Fork headers must not change the height index.
+	fork := []*util.Header{main[1]}
+	for i := 1; i < 3; i++ {
+		fork = append(fork, newTestHeader(fork[i-1], 1))
+		if !assert.NoError(t, db.Put(fork[i], false)) {
+			t.FailNow()
+		}
+	}
+
+	headers, err := db.GetRange(0, 4)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for i, header := range headers {
+		assert.Equal(t, main[i].Hash(), header.Hash())
+	}
+
+	// Switch to the shorter fork chain 1 <- 2' <- 3'.
+	if !assert.NoError(t, db.Put(fork[2], true)) {
+		t.FailNow()
+	}
+
+	var hashes []string
+	err = db.Iterate(0, func(header *util.Header) bool {
+		hashes = append(hashes, header.Hash().String())
+		return true
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{main[0].Hash().String(), main[1].Hash().String(),
+		fork[1].Hash().String(), fork[2].Hash().String()}, hashes)
+
+	_, err = db.GetByHeight(4)
+	assert.Error(t, err)
+
+	// Rebuild the height index from chain tip.
+	if !assert.NoError(t, rebuildIndex(db.db, newBlockHeader)) {
+		t.FailNow()
+	}
+	header, err := db.GetByHeight(2)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, fork[1].Hash(), header.Hash())
+}
+
+// BenchmarkCommitBlock measures committing empty blocks on the chain tip,
+// which syncs the new tip to disk once per block.
+func BenchmarkCommitBlock(b *testing.B) {
+	h, err := NewHeaderStore(b.TempDir(), kvdb.LevelDB, newBlockHeader)
+	if err != nil {
+		b.Fatal(err)
+	}
+	db, err := database.NewChainDB(h, database.NewMemTxsDB(),
+		database.NewMemJournal(), nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer db.Close()
+
+	prev := newTestHeader(nil, 0)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		block := &util.Block{Header: *prev}
+		if _, err := db.CommitBlock(block, true); err != nil {
+			b.Fatal(err)
+		}
+		prev = newTestHeader(prev, 0)
+	}
+}
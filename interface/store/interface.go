@@ -8,11 +8,11 @@ import (
 	"github.com/elastos/Elastos.ELA.SPV/util"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
 )
 
 type HeaderStore interface {
 	database.Headers
-	GetByHeight(height uint32) (header *util.Header, err error)
 }
 
 type DataStore interface {
@@ -20,7 +20,11 @@ type DataStore interface {
 	Addrs() Addrs
 	Txs() Txs
 	Ops() Ops
+	UTXOs() UTXOs
 	Que() Que
+	Listeners() Listeners
+	Notified() Notified
+	DeadLetters() DeadLetters
 	Batch() DataBatch
 }
 
@@ -28,9 +32,11 @@ type DataBatch interface {
 	batch
 	Txs() TxsBatch
 	Ops() OpsBatch
+	UTXOs() UTXOsBatch
 	Que() QueBatch
 	// Delete all transactions, ops, queued items on
-	// the given height.
+	// the given height, and queue the revocations of
+	// the transactions notified on the height.
 	DelAll(height uint32) error
 }
 
@@ -52,6 +58,10 @@ type Txs interface {
 	Get(txId *common.Uint256) (*util.Tx, error)
 	GetAll() ([]*util.Tx, error)
 	GetIds(height uint32) ([]*common.Uint256, error)
+	// GetByAddress returns the transactions of the address sorted by height,
+	// in descending order if desc is true, limit 0 returns all transactions
+	// from offset.
+	GetByAddress(addr *common.Uint168, offset, limit uint32, desc bool) ([]*util.Tx, error)
 	PutForkTxs(txs []*util.Tx, hash *common.Uint256) error
 	GetForkTxs(hash *common.Uint256) ([]*util.Tx, error)
 	Del(txId *common.Uint256) error
@@ -61,6 +71,8 @@ type Txs interface {
 type TxsBatch interface {
 	batch
 	Put(tx *util.Tx) error
+	// PutAddrIndex indexes the transaction by the given addresses.
+	PutAddrIndex(tx *util.Tx, addrs []common.Uint168) error
 	Del(txId *common.Uint256) error
 	DelAll(height uint32) error
 }
@@ -79,18 +91,105 @@ type OpsBatch interface {
 	Del(*util.OutPoint) error
 }
 
+type UTXOs interface {
+	database.DB
+
+	// Get returns the UTXO of the given outpoint.
+	Get(op *util.OutPoint) (*UTXO, error)
+
+	// GetByAddress returns all UTXOs of the address, including spent ones.
+	GetByAddress(addr *common.Uint168) ([]*UTXO, error)
+}
+
+type UTXOsBatch interface {
+	batch
+
+	// PutTx puts the outputs of the transaction paid to the given addresses
+	// and marks the outputs spent by the transaction.
+	PutTx(tx *types.Transaction, height uint32, addrs []common.Uint168) error
+
+	// DelTx deletes the outputs created by the transaction and marks the
+	// outputs spent by the transaction unspent.
+	DelTx(tx *types.Transaction) error
+}
+
+type Listeners interface {
+	database.DB
+
+	// Put saves the listener registration.
+	Put(info *ListenerInfo) error
+
+	// Get returns the listener registration of the notify id.
+	Get(notifyId *common.Uint256) (*ListenerInfo, error)
+
+	// GetAll returns all listener registrations.
+	GetAll() ([]*ListenerInfo, error)
+
+	// Del deletes the listener registration of the notify id.
+	Del(notifyId *common.Uint256) error
+}
+
+type Notified interface {
+	database.DB
+
+	// Put records the transaction notified to the listener.
+	Put(item *QueItem) error
+
+	// GetAll returns the notifications on the given height.
+	GetAll(height uint32) ([]*QueItem, error)
+
+	// DelAll deletes the notifications on the given height.
+	DelAll(height uint32) error
+
+	// DelBefore deletes the notifications below the given height.
+	DelBefore(height uint32) error
+
+	// GetRevoked returns the revocations of notified transactions rolled
+	// back, they are queued by DataBatch.DelAll.
+	GetRevoked() ([]*QueItem, error)
+
+	// DelRevoked deletes the revocation sent to the listener.
+	DelRevoked(notifyId, txId *common.Uint256) error
+
+	// DelRevokedByNotifyId deletes all revocations of the listener.
+	DelRevokedByNotifyId(notifyId *common.Uint256) error
+}
+
+type DeadLetters interface {
+	database.DB
+
+	// Put saves the dead letter.
+	Put(letter *DeadLetter) error
+
+	// Get returns the dead letter of the notify id and transaction id.
+	Get(notifyId, txId *common.Uint256) (*DeadLetter, error)
+
+	// GetAll returns all dead letters.
+	GetAll() ([]*DeadLetter, error)
+
+	// Del deletes the dead letter of the notify id and transaction id.
+	Del(notifyId, txId *common.Uint256) error
+}
+
 type Que interface {
 	database.DB
 
 	// Put a queue item to database
 	Put(item *QueItem) error
 
+	// Update the notify state of a queue item, it fails if the item has been
+	// deleted or queued again on another height.
+	Update(item *QueItem) error
+
 	// Get all items in queue
 	GetAll() ([]*QueItem, error)
 
 	// Delete confirmed item in queue
 	Del(notifyId, txHash *common.Uint256) error
 
+	// Delete all items of the given notify id in queue
+	DelByNotifyId(notifyId *common.Uint256) error
+
 	// Batch returns a queue batch instance.
 	Batch() QueBatch
 }
@@ -113,4 +212,15 @@ type QueItem struct {
 	TxId       common.Uint256
 	Height     uint32
 	LastNotify time.Time
+
+	// Attempts is the number of times the item has been notified.
+	Attempts uint32
+}
+
+// DeadLetter is a queue item given up after the max delivery attempts.
+type DeadLetter struct {
+	QueItem
+
+	// Time is the time the item was moved to dead letters.
+	Time time.Time
 }
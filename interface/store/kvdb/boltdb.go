@@ -0,0 +1,191 @@
+package kvdb
+
+import (
+	"bytes"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucket is the only bucket all key-value pairs are stored in.
+var bucket = []byte("kv")
+
+// Ensure boltDB implement DB interface.
+var _ DB = (*boltDB)(nil)
+
+type boltDB struct {
+	db *bolt.DB
+}
+
+// OpenBoltDB opens or creates a bbolt database file on path.
+func OpenBoltDB(path string) (DB, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &boltDB{db: db}, nil
+}
+
+func (d *boltDB) Get(key []byte) (value []byte, err error) {
+	err = d.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucket).Get(key)
+		if v == nil {
+			return ErrNotFound
+		}
+		// Values are only valid during the transaction.
+		value = append([]byte{}, v...)
+		return nil
+	})
+	return value, err
+}
+
+func (d *boltDB) Put(key, value []byte) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Put(key, value)
+	})
+}
+
+func (d *boltDB) Delete(key []byte) error {
+	return d.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucket).Delete(key)
+	})
+}
+
+// NewIterator iterates the matched pairs in a read transaction, which is kept
+// open until the iterator is released.
+func (d *boltDB) NewIterator(prefix []byte) Iterator {
+	tx, err := d.db.Begin(false)
+	if err != nil {
+		return &boltIterator{err: err}
+	}
+	return &boltIterator{
+		tx:     tx,
+		cursor: tx.Bucket(bucket).Cursor(),
+		prefix: prefix,
+	}
+}
+
+func (d *boltDB) NewBatch() Batch {
+	return new(boltBatch)
+}
+
+func (d *boltDB) Write(batch Batch) error {
+	ops := batch.(*boltBatch).ops
+	if len(ops) == 0 {
+		return nil
+	}
+	return d.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucket)
+		for _, op := range ops {
+			var err error
+			if op.del {
+				err = b.Delete(op.key)
+			} else {
+				err = b.Put(op.key, op.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// WriteSync is the same as Write, bbolt syncs every commit to disk.
+func (d *boltDB) WriteSync(batch Batch) error {
+	return d.Write(batch)
+}
+
+func (d *boltDB) Close() error {
+	return d.db.Close()
+}
+
+type batchOp struct {
+	key   []byte
+	value []byte
+	del   bool
+}
+
+// boltBatch records operations and apply them in one transaction by Write.
+type boltBatch struct {
+	ops []batchOp
+}
+
+func (b *boltBatch) Put(key, value []byte) {
+	b.ops = append(b.ops, batchOp{
+		key:   append([]byte{}, key...),
+		value: append([]byte{}, value...),
+	})
+}
+
+func (b *boltBatch) Delete(key []byte) {
+	b.ops = append(b.ops, batchOp{key: append([]byte{}, key...), del: true})
+}
+
+func (b *boltBatch) Reset() {
+	b.ops = b.ops[:0]
+}
+
+func (b *boltBatch) Len() int {
+	return len(b.ops)
+}
+
+// boltIterator iterates key-value pairs with a cursor of a read transaction.
+type boltIterator struct {
+	tx      *bolt.Tx
+	cursor  *bolt.Cursor
+	prefix  []byte
+	started bool
+	key     []byte
+	value   []byte
+	err     error
+}
+
+func (it *boltIterator) Next() bool {
+	if it.cursor == nil {
+		return false
+	}
+	if it.started {
+		it.key, it.value = it.cursor.Next()
+	} else {
+		it.key, it.value = it.cursor.Seek(it.prefix)
+		it.started = true
+	}
+	if it.key == nil || !bytes.HasPrefix(it.key, it.prefix) {
+		// Stop at the end, so Next will not move on after the prefix.
+		it.cursor, it.key, it.value = nil, nil, nil
+		return false
+	}
+	return true
+}
+
+func (it *boltIterator) Key() []byte {
+	return it.key
+}
+
+func (it *boltIterator) Value() []byte {
+	return it.value
+}
+
+// Release rolls back the read transaction, the keys and values returned are
+// invalid after releasing.
+func (it *boltIterator) Release() {
+	if it.tx != nil {
+		it.tx.Rollback()
+	}
+	it.tx, it.cursor, it.key, it.value = nil, nil, nil, nil
+}
+
+func (it *boltIterator) Error() error {
+	return it.err
+}
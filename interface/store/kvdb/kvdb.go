@@ -0,0 +1,97 @@
+// Package kvdb abstracts the key-value database used by interface/store, so
+// the storage engine can be chosen by the user.
+package kvdb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNotFound is returned by Get when the key does not exist in database.
+var ErrNotFound = errors.New("kvdb: not found")
+
+// Backend is the name of a key-value database engine.
+type Backend string
+
+const (
+	// LevelDB stores data with goleveldb, it is the default backend.
+	LevelDB Backend = "leveldb"
+
+	// BoltDB stores data with bbolt in a single file, it has no background
+	// compaction, which makes it a good choice for embedded devices.
+	BoltDB Backend = "bolt"
+)
+
+// DB is the key-value database interface.
+type DB interface {
+	// Get returns the value of the given key, or ErrNotFound if the key
+	// does not exist.
+	Get(key []byte) ([]byte, error)
+
+	// Put sets the value of the given key.
+	Put(key, value []byte) error
+
+	// Delete removes the given key, it is not an error if the key does not
+	// exist.
+	Delete(key []byte) error
+
+	// NewIterator returns an iterator of all keys start with the given
+	// prefix in ascending order, a nil prefix iterates the whole database.
+	NewIterator(prefix []byte) Iterator
+
+	// NewBatch returns an empty batch to be written by Write.
+	NewBatch() Batch
+
+	// Write applies all operations in the batch atomically.
+	Write(batch Batch) error
+
+	// WriteSync applies the batch like Write and syncs it to disk before
+	// returning, so it is not lost on a power failure.
+	WriteSync(batch Batch) error
+
+	// Close the database.
+	Close() error
+}
+
+// Iterator iterates key/value pairs of a database.
+type Iterator interface {
+	// Next moves to the next pair, returns false if there is no more pair.
+	Next() bool
+
+	// Key returns the key of current pair, the returned slice must not be
+	// modified and may be changed by the next call to Next.
+	Key() []byte
+
+	// Value returns the value of current pair, the returned slice must not
+	// be modified and may be changed by the next call to Next.
+	Value() []byte
+
+	// Release releases the resources held by iterator.  Release the
+	// iterator before writing to the database, a bolt iterator holds a read
+	// transaction which blocks the write from growing the database file.
+	Release()
+
+	// Error returns the error occurred while iterating.
+	Error() error
+}
+
+// Batch collects put and delete operations to be written atomically.
+type Batch interface {
+	Put(key, value []byte)
+	Delete(key []byte)
+	Reset()
+	Len() int
+}
+
+// Open opens or creates a database of the given backend on path, an empty
+// backend opens the default LevelDB.
+func Open(backend Backend, path string) (DB, error) {
+	switch backend {
+	case "", LevelDB:
+		return OpenLevelDB(path)
+	case BoltDB:
+		return OpenBoltDB(path + ".db")
+	default:
+		return nil, fmt.Errorf("unknown database backend %q", backend)
+	}
+}
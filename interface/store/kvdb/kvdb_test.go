@@ -0,0 +1,94 @@
+package kvdb
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackends(t *testing.T) {
+	for _, backend := range []Backend{LevelDB, BoltDB} {
+		db, err := Open(backend, "test")
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		testDB(t, db)
+		db.Close()
+		os.RemoveAll("test")
+		os.RemoveAll("test.db")
+	}
+
+	db, err := NewMemDB()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	testDB(t, db)
+	db.Close()
+
+	_, err = Open("unknown", "test")
+	assert.Error(t, err)
+}
+
+func testDB(t *testing.T, db DB) {
+	_, err := db.Get([]byte("a1"))
+	if !assert.Equal(t, ErrNotFound, err) {
+		t.FailNow()
+	}
+
+	for _, key := range []string{"a1", "a2", "b1", "a3"} {
+		err := db.Put([]byte(key), []byte(key))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+
+	value, err := db.Get([]byte("a2"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []byte("a2"), value)
+
+	var keys []string
+	it := db.NewIterator([]byte("a"))
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+		assert.Equal(t, it.Key(), it.Value())
+	}
+	// The iterator stays at the end and does not move to "b1".
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Key())
+	it.Release()
+	if !assert.NoError(t, it.Error()) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{"a1", "a2", "a3"}, keys)
+
+	batch := db.NewBatch()
+	it = db.NewIterator(nil)
+	for it.Next() {
+		batch.Delete(it.Key())
+	}
+	it.Release()
+	batch.Put([]byte("c1"), []byte{})
+	if !assert.Equal(t, 5, batch.Len()) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, db.Write(batch)) {
+		t.FailNow()
+	}
+
+	keys = nil
+	it = db.NewIterator(nil)
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+	it.Release()
+	assert.Equal(t, []string{"c1"}, keys)
+
+	if !assert.NoError(t, db.Delete([]byte("c1"))) {
+		t.FailNow()
+	}
+	_, err = db.Get([]byte("c1"))
+	assert.Equal(t, ErrNotFound, err)
+}
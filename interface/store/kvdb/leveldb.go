@@ -0,0 +1,73 @@
+package kvdb
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Ensure levelDB implement DB interface.
+var _ DB = (*levelDB)(nil)
+
+type levelDB struct {
+	db *leveldb.DB
+}
+
+// OpenLevelDB opens or creates a LevelDB database in the path directory.
+func OpenLevelDB(path string) (DB, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &levelDB{db: db}, nil
+}
+
+// NewMemDB creates a database that keeps all data in memory, all data will be
+// lost after the process exits.
+func NewMemDB() (DB, error) {
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &levelDB{db: db}, nil
+}
+
+func (d *levelDB) Get(key []byte) ([]byte, error) {
+	value, err := d.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+func (d *levelDB) Put(key, value []byte) error {
+	return d.db.Put(key, value, nil)
+}
+
+func (d *levelDB) Delete(key []byte) error {
+	return d.db.Delete(key, nil)
+}
+
+func (d *levelDB) NewIterator(prefix []byte) Iterator {
+	if prefix == nil {
+		return d.db.NewIterator(nil, nil)
+	}
+	return d.db.NewIterator(util.BytesPrefix(prefix), nil)
+}
+
+func (d *levelDB) NewBatch() Batch {
+	return new(leveldb.Batch)
+}
+
+func (d *levelDB) Write(batch Batch) error {
+	return d.db.Write(batch.(*leveldb.Batch), nil)
+}
+
+func (d *levelDB) WriteSync(batch Batch) error {
+	return d.db.Write(batch.(*leveldb.Batch), &opt.WriteOptions{Sync: true})
+}
+
+func (d *levelDB) Close() error {
+	return d.db.Close()
+}
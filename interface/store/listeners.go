@@ -0,0 +1,142 @@
+package store
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+var (
+	// BKTListeners stores the registered listeners by notify id.
+	BKTListeners = []byte("L")
+)
+
+// ListenerInfo is the persisted registration of a transaction listener.
+type ListenerInfo struct {
+	// NotifyId is the key of the listener, it is not serialized.
+	NotifyId common.Uint256
+
+	// Address is the address the listener interested, empty if the
+	// listener was registered before listeners were persisted.
+	Address string
+
+	// Type is the transaction type the listener interested.
+	Type uint8
+
+	// Flags are the notification flags of the listener.
+	Flags uint64
+
+	// Registered is the time the listener was first registered.
+	Registered time.Time
+
+	// LastSeen is the last time the listener was registered in the service.
+	LastSeen time.Time
+}
+
+func (l *ListenerInfo) Serialize(w io.Writer) error {
+	if err := common.WriteVarString(w, l.Address); err != nil {
+		return err
+	}
+	return common.WriteElements(w, l.Type, l.Flags,
+		l.Registered.Unix(), l.LastSeen.Unix())
+}
+
+func (l *ListenerInfo) Deserialize(r io.Reader) (err error) {
+	l.Address, err = common.ReadVarString(r)
+	if err != nil {
+		return err
+	}
+	var registered, lastSeen int64
+	err = common.ReadElements(r, &l.Type, &l.Flags, &registered, &lastSeen)
+	if err != nil {
+		return err
+	}
+	l.Registered = time.Unix(registered, 0)
+	l.LastSeen = time.Unix(lastSeen, 0)
+	return nil
+}
+
+// Ensure listeners implement Listeners interface.
+var _ Listeners = (*listeners)(nil)
+
+type listeners struct {
+	sync.RWMutex
+	db kvdb.DB
+}
+
+func NewListeners(db kvdb.DB) *listeners {
+	return &listeners{db: db}
+}
+
+func (l *listeners) Put(info *ListenerInfo) error {
+	l.Lock()
+	defer l.Unlock()
+
+	buf := new(bytes.Buffer)
+	if err := info.Serialize(buf); err != nil {
+		return err
+	}
+	return l.db.Put(toKey(BKTListeners, info.NotifyId[:]...), buf.Bytes())
+}
+
+func (l *listeners) Get(notifyId *common.Uint256) (*ListenerInfo, error) {
+	l.RLock()
+	defer l.RUnlock()
+
+	data, err := l.db.Get(toKey(BKTListeners, notifyId[:]...))
+	if err != nil {
+		return nil, err
+	}
+	info := ListenerInfo{NotifyId: *notifyId}
+	if err := info.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (l *listeners) GetAll() ([]*ListenerInfo, error) {
+	l.RLock()
+	defer l.RUnlock()
+
+	it := l.db.NewIterator(BKTListeners)
+	defer it.Release()
+	var infos []*ListenerInfo
+	for it.Next() {
+		var info ListenerInfo
+		copy(info.NotifyId[:], subKey(BKTListeners, it.Key()))
+		if err := info.Deserialize(bytes.NewReader(it.Value())); err != nil {
+			return nil, err
+		}
+		infos = append(infos, &info)
+	}
+	return infos, it.Error()
+}
+
+func (l *listeners) Del(notifyId *common.Uint256) error {
+	l.Lock()
+	defer l.Unlock()
+	return l.db.Delete(toKey(BKTListeners, notifyId[:]...))
+}
+
+func (l *listeners) Clear() error {
+	l.Lock()
+	defer l.Unlock()
+
+	it := l.db.NewIterator(BKTListeners)
+	batch := l.db.NewBatch()
+	for it.Next() {
+		batch.Delete(it.Key())
+	}
+	it.Release()
+	return l.db.Write(batch)
+}
+
+func (l *listeners) Close() error {
+	l.Lock()
+	return nil
+}
@@ -0,0 +1,66 @@
+package store
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListeners(t *testing.T) {
+	db, err := NewMemDataStore()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+
+	now := time.Unix(time.Now().Unix(), 0)
+	infos := make(map[common.Uint256]*ListenerInfo)
+	for i := 0; i < 10; i++ {
+		info := &ListenerInfo{
+			Address:    "EQSpUzE4XYJhBSx5j7Tf2cteaKdFdixfVB",
+			Type:       uint8(i),
+			Flags:      uint64(i),
+			Registered: now.Add(-time.Hour),
+			LastSeen:   now,
+		}
+		rand.Read(info.NotifyId[:])
+		if !assert.NoError(t, db.Listeners().Put(info)) {
+			t.FailNow()
+		}
+		infos[info.NotifyId] = info
+	}
+
+	all, err := db.Listeners().GetAll()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Equal(t, len(infos), len(all)) {
+		t.FailNow()
+	}
+	for _, info := range all {
+		assert.Equal(t, infos[info.NotifyId], info)
+	}
+
+	for id := range infos {
+		info, err := db.Listeners().Get(&id)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, infos[id], info)
+
+		if !assert.NoError(t, db.Listeners().Del(&id)) {
+			t.FailNow()
+		}
+		_, err = db.Listeners().Get(&id)
+		assert.Error(t, err)
+	}
+
+	all, err = db.Listeners().GetAll()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 0, len(all))
+}
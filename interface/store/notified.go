@@ -0,0 +1,172 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+var (
+	// BKTNotified records the transactions notified to listeners, the key is
+	// height + notify id + transaction id, so the notifications on a height
+	// can be revoked when the height is rolled back.
+	BKTNotified = []byte("R")
+
+	// BKTRevoked queues the revocations of notified transactions rolled
+	// back, the key is notify id + transaction id and the value is the
+	// height, so they are sent when the listener is registered.
+	BKTRevoked = []byte("K")
+)
+
+// Ensure notified implement Notified interface.
+var _ Notified = (*notified)(nil)
+
+type notified struct {
+	sync.RWMutex
+	db kvdb.DB
+}
+
+func NewNotified(db kvdb.DB) *notified {
+	return &notified{db: db}
+}
+
+func notifiedKey(height uint32) []byte {
+	var h [4]byte
+	binary.BigEndian.PutUint32(h[:], height)
+	return toKey(BKTNotified, h[:]...)
+}
+
+func (n *notified) Put(item *QueItem) error {
+	n.Lock()
+	defer n.Unlock()
+
+	key := notifiedKey(item.Height)
+	key = append(key, item.NotifyId[:]...)
+	key = append(key, item.TxId[:]...)
+	return n.db.Put(key, empty)
+}
+
+func (n *notified) GetAll(height uint32) ([]*QueItem, error) {
+	n.RLock()
+	defer n.RUnlock()
+
+	prefix := notifiedKey(height)
+	it := n.db.NewIterator(prefix)
+	defer it.Release()
+	var items []*QueItem
+	for it.Next() {
+		value := subKey(prefix, it.Key())
+		item := QueItem{Height: height}
+		copy(item.NotifyId[:], value[:32])
+		copy(item.TxId[:], value[32:])
+		items = append(items, &item)
+	}
+	return items, it.Error()
+}
+
+func (n *notified) DelAll(height uint32) error {
+	n.Lock()
+	defer n.Unlock()
+
+	it := n.db.NewIterator(notifiedKey(height))
+	batch := n.db.NewBatch()
+	for it.Next() {
+		batch.Delete(it.Key())
+	}
+	it.Release()
+	return n.db.Write(batch)
+}
+
+func (n *notified) DelBefore(height uint32) error {
+	n.Lock()
+	defer n.Unlock()
+
+	end := notifiedKey(height)
+	it := n.db.NewIterator(BKTNotified)
+	batch := n.db.NewBatch()
+	for it.Next() {
+		if bytes.Compare(it.Key()[:len(end)], end) < 0 {
+			batch.Delete(it.Key())
+		}
+	}
+	it.Release()
+	return n.db.Write(batch)
+}
+
+func (n *notified) GetRevoked() ([]*QueItem, error) {
+	n.RLock()
+	defer n.RUnlock()
+
+	it := n.db.NewIterator(BKTRevoked)
+	defer it.Release()
+	var items []*QueItem
+	for it.Next() {
+		value := subKey(BKTRevoked, it.Key())
+		var item QueItem
+		copy(item.NotifyId[:], value[:32])
+		copy(item.TxId[:], value[32:])
+		item.Height = binary.BigEndian.Uint32(it.Value())
+		items = append(items, &item)
+	}
+	return items, it.Error()
+}
+
+func (n *notified) DelRevoked(notifyId, txId *common.Uint256) error {
+	n.Lock()
+	defer n.Unlock()
+
+	return n.db.Delete(toKey(BKTRevoked, append(notifyId[:], txId[:]...)...))
+}
+
+func (n *notified) DelRevokedByNotifyId(notifyId *common.Uint256) error {
+	n.Lock()
+	defer n.Unlock()
+
+	it := n.db.NewIterator(toKey(BKTRevoked, notifyId[:]...))
+	batch := n.db.NewBatch()
+	for it.Next() {
+		batch.Delete(it.Key())
+	}
+	it.Release()
+	return n.db.Write(batch)
+}
+
+// revokeAll moves the notifications on the height to the revocations in the
+// batch, so they are revoked with the rollback of the height.
+func revokeAll(db kvdb.DB, batch kvdb.Batch, height uint32) error {
+	var h [4]byte
+	binary.BigEndian.PutUint32(h[:], height)
+	prefix := notifiedKey(height)
+	it := db.NewIterator(prefix)
+	defer it.Release()
+	for it.Next() {
+		value := subKey(prefix, it.Key())
+		batch.Put(toKey(BKTRevoked, value...), h[:])
+		batch.Delete(it.Key())
+	}
+	return it.Error()
+}
+
+func (n *notified) Clear() error {
+	n.Lock()
+	defer n.Unlock()
+
+	batch := n.db.NewBatch()
+	for _, bucket := range [][]byte{BKTNotified, BKTRevoked} {
+		it := n.db.NewIterator(bucket)
+		for it.Next() {
+			batch.Delete(it.Key())
+		}
+		it.Release()
+	}
+	return n.db.Write(batch)
+}
+
+func (n *notified) Close() error {
+	n.Lock()
+	return nil
+}
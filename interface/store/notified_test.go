@@ -0,0 +1,107 @@
+package store
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotified(t *testing.T) {
+	db, err := NewMemDataStore()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+
+	items := make(map[uint32][]*QueItem)
+	for height := uint32(1); height <= 10; height++ {
+		for i := 0; i < 3; i++ {
+			item := &QueItem{Height: height}
+			rand.Read(item.NotifyId[:])
+			rand.Read(item.TxId[:])
+			if !assert.NoError(t, db.Notified().Put(item)) {
+				t.FailNow()
+			}
+			items[height] = append(items[height], item)
+		}
+	}
+
+	for height := uint32(1); height <= 10; height++ {
+		got, err := db.Notified().GetAll(height)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.ElementsMatch(t, items[height], got)
+	}
+
+	if !assert.NoError(t, db.Notified().DelAll(10)) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, db.Notified().DelBefore(5)) {
+		t.FailNow()
+	}
+	for height := uint32(1); height <= 10; height++ {
+		got, err := db.Notified().GetAll(height)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		if height < 5 || height == 10 {
+			assert.Equal(t, 0, len(got))
+		} else {
+			assert.Equal(t, 3, len(got))
+		}
+	}
+}
+
+func TestRevoked(t *testing.T) {
+	db, err := NewMemDataStore()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+
+	var items []*QueItem
+	for i := 0; i < 3; i++ {
+		item := &QueItem{Height: 10}
+		rand.Read(item.NotifyId[:])
+		rand.Read(item.TxId[:])
+		if !assert.NoError(t, db.Notified().Put(item)) {
+			t.FailNow()
+		}
+		items = append(items, item)
+	}
+
+	// The notifications are moved to revocations with the rollback.
+	batch := db.Batch()
+	if !assert.NoError(t, batch.DelAll(10)) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, batch.Commit()) {
+		t.FailNow()
+	}
+	got, err := db.Notified().GetAll(10)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 0, len(got))
+	revoked, err := db.Notified().GetRevoked()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.ElementsMatch(t, items, revoked)
+
+	err = db.Notified().DelRevoked(&items[0].NotifyId, &items[0].TxId)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	err = db.Notified().DelRevokedByNotifyId(&items[1].NotifyId)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	revoked, err = db.Notified().GetRevoked()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []*QueItem{items[2]}, revoked)
+}
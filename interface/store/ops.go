@@ -3,11 +3,10 @@ package store
 import (
 	"sync"
 
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
 	"github.com/elastos/Elastos.ELA.SPV/util"
 
 	"github.com/elastos/Elastos.ELA/common"
-	"github.com/syndtr/goleveldb/leveldb"
-	dbutil "github.com/syndtr/goleveldb/leveldb/util"
 )
 
 var (
@@ -19,24 +18,24 @@ var _ Ops = (*ops)(nil)
 
 type ops struct {
 	sync.RWMutex
-	db *leveldb.DB
+	db kvdb.DB
 }
 
-func NewOps(db *leveldb.DB) *ops {
+func NewOps(db kvdb.DB) *ops {
 	return &ops{db: db}
 }
 
 func (o *ops) Put(op *util.OutPoint, addr common.Uint168) error {
 	o.Lock()
 	defer o.Unlock()
-	return o.db.Put(toKey(BKTOps, op.Bytes()...), addr.Bytes(), nil)
+	return o.db.Put(toKey(BKTOps, op.Bytes()...), addr.Bytes())
 }
 
 func (o *ops) HaveOp(op *util.OutPoint) (addr *common.Uint168) {
 	o.RLock()
 	defer o.RUnlock()
 
-	addrBytes, err := o.db.Get(toKey(BKTOps, op.Bytes()...), nil)
+	addrBytes, err := o.db.Get(toKey(BKTOps, op.Bytes()...))
 	if err != nil {
 		return nil
 	}
@@ -50,7 +49,7 @@ func (o *ops) GetAll() (ops []*util.OutPoint, err error) {
 	o.RLock()
 	defer o.RUnlock()
 
-	it := o.db.NewIterator(dbutil.BytesPrefix(BKTOps), nil)
+	it := o.db.NewIterator(BKTOps)
 	defer it.Release()
 	for it.Next() {
 		op, err := util.OutPointFromBytes(subKey(BKTOps, it.Key()))
@@ -64,19 +63,19 @@ func (o *ops) GetAll() (ops []*util.OutPoint, err error) {
 }
 
 func (o *ops) Batch() OpsBatch {
-	return &opsBatch{DB: o.db, Batch: new(leveldb.Batch)}
+	return &opsBatch{DB: o.db, Batch: o.db.NewBatch()}
 }
 
 func (o *ops) Clear() error {
 	o.Lock()
 	defer o.Unlock()
-	it := o.db.NewIterator(dbutil.BytesPrefix(BKTOps), nil)
-	defer it.Release()
-	batch := new(leveldb.Batch)
+	it := o.db.NewIterator(BKTOps)
+	batch := o.db.NewBatch()
 	for it.Next() {
 		batch.Delete(it.Key())
 	}
-	return o.db.Write(batch, nil)
+	it.Release()
+	return o.db.Write(batch)
 }
 
 func (o *ops) Close() error {
@@ -3,10 +3,10 @@ package store
 import (
 	"sync"
 
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
 	"github.com/elastos/Elastos.ELA.SPV/util"
 
 	"github.com/elastos/Elastos.ELA/common"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 // Ensure opsBatch implement OpsBatch interface.
@@ -14,8 +14,8 @@ var _ OpsBatch = (*opsBatch)(nil)
 
 type opsBatch struct {
 	sync.Mutex
-	*leveldb.DB
-	*leveldb.Batch
+	kvdb.DB
+	kvdb.Batch
 }
 
 func (b *opsBatch) Put(op *util.OutPoint, addr common.Uint168) error {
@@ -38,5 +38,5 @@ func (b *opsBatch) Rollback() error {
 }
 
 func (b *opsBatch) Commit() error {
-	return b.DB.Write(b.Batch, nil)
+	return b.DB.Write(b.Batch)
 }
@@ -6,9 +6,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+
 	"github.com/elastos/Elastos.ELA/common"
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 var (
@@ -22,10 +22,10 @@ var _ Que = (*que)(nil)
 
 type que struct {
 	sync.RWMutex
-	db *leveldb.DB
+	db kvdb.DB
 }
 
-func NewQue(db *leveldb.DB) *que {
+func NewQue(db kvdb.DB) *que {
 	return &que{db: db}
 }
 
@@ -34,14 +34,40 @@ func (q *que) Put(item *QueItem) error {
 	q.Lock()
 	defer q.Unlock()
 
-	batch := new(leveldb.Batch)
+	return q.put(item)
+}
+
+// Update the notify state of a queue item, kvdb.ErrNotFound is returned if
+// the item has been deleted or queued again on another height.
+func (q *que) Update(item *QueItem) error {
+	q.Lock()
+	defer q.Unlock()
+
+	value := append(item.NotifyId[:], item.TxId[:]...)
+	data, err := q.db.Get(toKey(BKTQue, value...))
+	if err != nil {
+		return err
+	}
+	if binary.BigEndian.Uint32(data[:4]) != item.Height {
+		return kvdb.ErrNotFound
+	}
+	return q.put(item)
+}
+
+func (q *que) put(item *QueItem) error {
+	batch := q.db.NewBatch()
 	buf := new(bytes.Buffer)
 	binary.Write(buf, binary.BigEndian, item.Height)
 	value := append(item.NotifyId[:], item.TxId[:]...)
 	batch.Put(toKey(BKTQueIdx, append(buf.Bytes(), value...)...), empty)
 	binary.Write(buf, binary.BigEndian, item.LastNotify.Unix())
+	// Attempts is appended only if notified, to keep the value of items
+	// never notified unchanged.
+	if item.Attempts > 0 {
+		binary.Write(buf, binary.BigEndian, item.Attempts)
+	}
 	batch.Put(toKey(BKTQue, value...), buf.Bytes())
-	return q.db.Write(batch, nil)
+	return q.db.Write(batch)
 }
 
 // Get all items in queue
@@ -49,7 +75,7 @@ func (q *que) GetAll() (items []*QueItem, err error) {
 	q.RLock()
 	defer q.RUnlock()
 
-	it := q.db.NewIterator(util.BytesPrefix(BKTQue), nil)
+	it := q.db.NewIterator(BKTQue)
 	defer it.Release()
 	for it.Next() {
 		var item QueItem
@@ -60,6 +86,7 @@ func (q *que) GetAll() (items []*QueItem, err error) {
 		buf := bytes.NewReader(it.Value())
 		binary.Read(buf, binary.BigEndian, &item.Height)
 		binary.Read(buf, binary.BigEndian, &lastNotify)
+		binary.Read(buf, binary.BigEndian, &item.Attempts)
 		item.LastNotify = time.Unix(lastNotify, 0)
 		items = append(items, &item)
 	}
@@ -72,38 +99,62 @@ func (q *que) Del(notifyId, txHash *common.Uint256) error {
 	defer q.Unlock()
 
 	value := append(notifyId[:], txHash[:]...)
-	height, err := q.db.Get(toKey(BKTQue, value...), nil)
+	data, err := q.db.Get(toKey(BKTQue, value...))
 	if err != nil {
 		return err
 	}
-	batch := new(leveldb.Batch)
+	// The value starts with the height, followed by the last notify time.
+	height := data[:4]
+	batch := q.db.NewBatch()
 	batch.Delete(toKey(BKTQue, value...))
-	batch.Delete(toKey(BKTQueIdx, append(height[:], value...)...))
-	return q.db.Write(batch, nil)
+	batch.Delete(toKey(BKTQueIdx, append(height, value...)...))
+	return q.db.Write(batch)
+}
+
+// Delete all items of the given notify id in queue
+func (q *que) DelByNotifyId(notifyId *common.Uint256) error {
+	q.Lock()
+	defer q.Unlock()
+
+	batch := q.db.NewBatch()
+	it := q.db.NewIterator(toKey(BKTQue, notifyId[:]...))
+	for it.Next() {
+		value := subKey(BKTQue, it.Key())
+		height := it.Value()[:4]
+		idx := make([]byte, 0, len(BKTQueIdx)+len(height)+len(value))
+		idx = append(append(append(idx, BKTQueIdx...), height...), value...)
+		batch.Delete(it.Key())
+		batch.Delete(idx)
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return q.db.Write(batch)
 }
 
 func (q *que) Batch() QueBatch {
-	return &queBatch{DB: q.db, Batch: new(leveldb.Batch)}
+	return &queBatch{DB: q.db, Batch: q.db.NewBatch()}
 }
 
 func (q *que) Clear() error {
 	q.Lock()
 	defer q.Unlock()
 
-	batch := new(leveldb.Batch)
-	it := q.db.NewIterator(util.BytesPrefix(BKTQue), nil)
+	batch := q.db.NewBatch()
+	it := q.db.NewIterator(BKTQue)
 	for it.Next() {
 		batch.Delete(it.Key())
 	}
 	it.Release()
 
-	it = q.db.NewIterator(util.BytesPrefix(BKTQueIdx), nil)
+	it = q.db.NewIterator(BKTQueIdx)
 	for it.Next() {
 		batch.Delete(it.Key())
 	}
 	it.Release()
 
-	return q.db.Write(batch, nil)
+	return q.db.Write(batch)
 }
 
 func (q *que) Close() error {
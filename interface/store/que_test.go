@@ -6,14 +6,15 @@ import (
 	"testing"
 	"time"
 
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+
 	"github.com/elastos/Elastos.ELA/common"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 func TestQue(t *testing.T) {
-	db, err := leveldb.OpenFile("test", nil)
+	db, err := kvdb.OpenLevelDB("test")
 	if !assert.NoError(t, err) {
 		t.FailNow()
 	}
@@ -68,7 +69,7 @@ func TestQue(t *testing.T) {
 	binary.BigEndian.PutUint64(data1[4:], uint64(defaultTime.Add(time.Second).Unix()))
 	for i, notifyID := range notifyIDs {
 		value := append(notifyID[:], txHashes[i][:]...)
-		data, err := que.db.Get(toKey(BKTQue, value...), nil)
+		data, err := que.db.Get(toKey(BKTQue, value...))
 		if !assert.NoError(t, err) {
 			t.FailNow()
 		}
@@ -94,7 +95,7 @@ func TestQue(t *testing.T) {
 
 	for i, notifyID := range notifyIDs {
 		value := append(notifyID[:], txHashes[i][:]...)
-		_, err := que.db.Get(toKey(BKTQue, value...), nil)
+		_, err := que.db.Get(toKey(BKTQue, value...))
 		if i < times/2 {
 			if !assert.Error(t, err) {
 				t.FailNow()
@@ -168,7 +169,7 @@ func TestQue(t *testing.T) {
 
 	for i, notifyID := range notifyIDs {
 		value := append(notifyID[:], txHashes[i][:]...)
-		_, err := que.db.Get(toKey(BKTQue, value...), nil)
+		_, err := que.db.Get(toKey(BKTQue, value...))
 		if i < times/2 {
 			if !assert.Error(t, err) {
 				t.FailNow()
@@ -197,4 +198,88 @@ func TestQue(t *testing.T) {
 	if !assert.Equal(t, 0, len(items)) {
 		t.FailNow()
 	}
+
+	// Delete items of a notify id, the height index is deleted too.
+	for i := range txHashes {
+		que.Put(&QueItem{NotifyId: notifyIDs[i%2], TxId: txHashes[i],
+			Height: uint32(i)})
+	}
+	err = que.DelByNotifyId(&notifyIDs[0])
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	items, err = que.GetAll()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Equal(t, times/2, len(items)) {
+		t.FailNow()
+	}
+	for _, item := range items {
+		assert.Equal(t, notifyIDs[1], item.NotifyId)
+	}
+	it := que.db.NewIterator(BKTQueIdx)
+	indexes := 0
+	for it.Next() {
+		indexes++
+	}
+	it.Release()
+	assert.Equal(t, times/2, indexes)
+}
+
+func TestQueUpdate(t *testing.T) {
+	db, err := kvdb.NewMemDB()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	que := NewQue(db)
+
+	item := QueItem{Height: 100}
+	rand.Read(item.NotifyId[:])
+	rand.Read(item.TxId[:])
+
+	// Items not queued are not updated.
+	item.Attempts = 1
+	assert.Equal(t, kvdb.ErrNotFound, que.Update(&item))
+	items, err := que.GetAll()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 0, len(items))
+
+	item.Attempts = 0
+	if !assert.NoError(t, que.Put(&item)) {
+		t.FailNow()
+	}
+	updated := item
+	updated.Attempts = 1
+	updated.LastNotify = time.Unix(1000, 0)
+	if !assert.NoError(t, que.Update(&updated)) {
+		t.FailNow()
+	}
+	items, err = que.GetAll()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Equal(t, 1, len(items)) {
+		t.FailNow()
+	}
+	assert.Equal(t, updated, *items[0])
+
+	// Items queued again on another height are not updated.
+	stale := updated
+	stale.Height = 99
+	stale.Attempts = 2
+	assert.Equal(t, kvdb.ErrNotFound, que.Update(&stale))
+
+	// Receipted items are not queued again.
+	if !assert.NoError(t, que.Del(&item.NotifyId, &item.TxId)) {
+		t.FailNow()
+	}
+	assert.Equal(t, kvdb.ErrNotFound, que.Update(&updated))
+	items, err = que.GetAll()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 0, len(items))
 }
@@ -4,10 +4,9 @@ import (
 	"encoding/binary"
 	"sync"
 
-	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
 
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/elastos/Elastos.ELA/common"
 )
 
 // Ensure queBatch implement QueBatch interface.
@@ -15,8 +14,8 @@ var _ QueBatch = (*queBatch)(nil)
 
 type queBatch struct {
 	sync.Mutex
-	*leveldb.DB
-	*leveldb.Batch
+	kvdb.DB
+	kvdb.Batch
 }
 
 // Put a queue item to database
@@ -38,12 +37,15 @@ func (b *queBatch) Del(notifyId, txHash *common.Uint256) error {
 	defer b.Unlock()
 
 	value := append(notifyId[:], txHash[:]...)
-	height, err := b.DB.Get(toKey(BKTQue, value...), nil)
+	data, err := b.DB.Get(toKey(BKTQue, value...))
 	if err != nil {
 		return err
 	}
+	// The value starts with the height, followed by the last notify time
+	// if it was notified.
+	height := data[:4]
 	b.Batch.Delete(toKey(BKTQue, value...))
-	b.Batch.Delete(toKey(BKTQueIdx, append(height[:], value...)...))
+	b.Batch.Delete(toKey(BKTQueIdx, append(height, value...)...))
 	return nil
 }
 
@@ -55,7 +57,7 @@ func (b *queBatch) DelAll(height uint32) error {
 	var key [4]byte
 	binary.BigEndian.PutUint32(key[:], height)
 	prefix := toKey(BKTQueIdx, key[:]...)
-	it := b.DB.NewIterator(util.BytesPrefix(prefix), nil)
+	it := b.DB.NewIterator(prefix)
 	for it.Next() {
 		value := subKey(prefix, it.Key())
 		b.Batch.Delete(toKey(BKTQue, value...))
@@ -75,5 +77,5 @@ func (b *queBatch) Rollback() error {
 func (b *queBatch) Commit() error {
 	b.Lock()
 	defer b.Unlock()
-	return b.Write(b.Batch, nil)
+	return b.Write(b.Batch)
 }
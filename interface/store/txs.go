@@ -5,11 +5,10 @@ import (
 	"encoding/binary"
 	"sync"
 
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
 	"github.com/elastos/Elastos.ELA.SPV/util"
 
 	"github.com/elastos/Elastos.ELA/common"
-	"github.com/syndtr/goleveldb/leveldb"
-	dbutil "github.com/syndtr/goleveldb/leveldb/util"
 )
 
 var (
@@ -23,10 +22,10 @@ var _ Txs = (*txs)(nil)
 
 type txs struct {
 	sync.RWMutex
-	db *leveldb.DB
+	db kvdb.DB
 }
 
-func NewTxs(db *leveldb.DB) *txs {
+func NewTxs(db kvdb.DB) *txs {
 	return &txs{db: db}
 }
 
@@ -39,15 +38,15 @@ func (t *txs) Put(txn *util.Tx) error {
 		return err
 	}
 
-	batch := new(leveldb.Batch)
+	batch := t.db.NewBatch()
 	batch.Put(toKey(BKTTxs, txn.Hash.Bytes()...), buf.Bytes())
 
 	var key [4]byte
 	binary.BigEndian.PutUint32(key[:], txn.Height)
-	data, _ := t.db.Get(toKey(BKTHeightTxs, key[:]...), nil)
+	data, _ := t.db.Get(toKey(BKTHeightTxs, key[:]...))
 	batch.Put(toKey(BKTHeightTxs, key[:]...), putTxId(data, &txn.Hash))
 
-	return t.db.Write(batch, nil)
+	return t.db.Write(batch)
 }
 
 func putTxId(data []byte, txId *common.Uint256) []byte {
@@ -59,6 +58,14 @@ func putTxId(data []byte, txId *common.Uint256) []byte {
 		count = binary.BigEndian.Uint16(data[0:2])
 	}
 
+	// Skip if transaction id already exist, so transactions can be put
+	// repeatedly when recovering an interrupted block commit.
+	for i := uint16(0); i < count; i++ {
+		if bytes.Equal(data[2+i*32:2+(i+1)*32], txId[:]) {
+			return data
+		}
+	}
+
 	data = append(data, txId[:]...)
 	binary.BigEndian.PutUint16(data[0:2], count+1)
 	return data
@@ -68,7 +75,7 @@ func (t *txs) Get(hash *common.Uint256) (txn *util.Tx, err error) {
 	t.RLock()
 	defer t.RUnlock()
 
-	data, err := t.db.Get(toKey(BKTTxs, hash.Bytes()...), nil)
+	data, err := t.db.Get(toKey(BKTTxs, hash.Bytes()...))
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +94,7 @@ func (t *txs) GetIds(height uint32) (txIds []*common.Uint256, err error) {
 
 	var key [4]byte
 	binary.BigEndian.PutUint32(key[:], height)
-	data, _ := t.db.Get(toKey(BKTHeightTxs, key[:]...), nil)
+	data, _ := t.db.Get(toKey(BKTHeightTxs, key[:]...))
 	return getTxIds(data), nil
 }
 
@@ -110,6 +117,31 @@ func getTxIds(data []byte) (txIds []*common.Uint256) {
 	return txIds
 }
 
+func (t *txs) GetByAddress(addr *common.Uint168, offset, limit uint32,
+	desc bool) ([]*util.Tx, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	txIds, err := getAddrTxIds(t.db, addr, offset, limit, desc)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*util.Tx, 0, len(txIds))
+	for _, txId := range txIds {
+		data, err := t.db.Get(toKey(BKTTxs, txId.Bytes()...))
+		if err != nil {
+			return nil, err
+		}
+		var tx util.Tx
+		if err := tx.Deserialize(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+		txs = append(txs, &tx)
+	}
+	return txs, nil
+}
+
 func (t *txs) PutForkTxs(txs []*util.Tx, hash *common.Uint256) error {
 	t.Lock()
 	defer t.Unlock()
@@ -123,14 +155,14 @@ func (t *txs) PutForkTxs(txs []*util.Tx, hash *common.Uint256) error {
 			return err
 		}
 	}
-	return t.db.Put(toKey(BKTForkTxs, hash.Bytes()...), buf.Bytes(), nil)
+	return t.db.Put(toKey(BKTForkTxs, hash.Bytes()...), buf.Bytes())
 }
 
 func (t *txs) GetForkTxs(hash *common.Uint256) ([]*util.Tx, error) {
 	t.RLock()
 	defer t.RUnlock()
 
-	data, err := t.db.Get(toKey(BKTForkTxs, hash.Bytes()...), nil)
+	data, err := t.db.Get(toKey(BKTForkTxs, hash.Bytes()...))
 	if err != nil {
 		return nil, err
 	}
@@ -155,7 +187,7 @@ func (t *txs) GetAll() (txs []*util.Tx, err error) {
 	t.RLock()
 	defer t.RUnlock()
 
-	it := t.db.NewIterator(dbutil.BytesPrefix(BKTTxs), nil)
+	it := t.db.NewIterator(BKTTxs)
 	defer it.Release()
 	for it.Next() {
 		var txn util.Tx
@@ -173,7 +205,7 @@ func (t *txs) Del(txId *common.Uint256) error {
 	defer t.Unlock()
 
 	var txn util.Tx
-	data, err := t.db.Get(toKey(BKTTxs, txId.Bytes()...), nil)
+	data, err := t.db.Get(toKey(BKTTxs, txId.Bytes()...))
 	if err != nil {
 		return err
 	}
@@ -184,13 +216,14 @@ func (t *txs) Del(txId *common.Uint256) error {
 
 	var key [4]byte
 	binary.BigEndian.PutUint32(key[:], txn.Height)
-	data, _ = t.db.Get(toKey(BKTHeightTxs, key[:]...), nil)
+	data, _ = t.db.Get(toKey(BKTHeightTxs, key[:]...))
 
-	batch := new(leveldb.Batch)
+	batch := t.db.NewBatch()
 	batch.Delete(toKey(BKTTxs, txId.Bytes()...))
 	batch.Put(toKey(BKTHeightTxs, key[:]...), delTxId(data, &txn.Hash))
+	delAddrIndex(t.db, batch, txId, txn.Height)
 
-	return t.db.Write(batch, nil)
+	return t.db.Write(batch)
 }
 
 func delTxId(data []byte, hash *common.Uint256) []byte {
@@ -218,27 +251,29 @@ func delTxId(data []byte, hash *common.Uint256) []byte {
 }
 
 func (t *txs) Batch() TxsBatch {
-	return &txsBatch{DB: t.db, Batch: new(leveldb.Batch)}
+	return &txsBatch{DB: t.db, Batch: t.db.NewBatch()}
 }
 
 func (t *txs) Clear() error {
 	t.Lock()
 	defer t.Unlock()
 
-	it := t.db.NewIterator(dbutil.BytesPrefix(BKTTxs), nil)
-	batch := new(leveldb.Batch)
+	it := t.db.NewIterator(BKTTxs)
+	batch := t.db.NewBatch()
 	for it.Next() {
 		batch.Delete(it.Key())
 	}
 	it.Release()
 
-	it = t.db.NewIterator(dbutil.BytesPrefix(BKTHeightTxs), nil)
-	for it.Next() {
-		batch.Delete(it.Key())
+	for _, bucket := range [][]byte{BKTHeightTxs, BKTAddrTxs, BKTTxAddrs} {
+		it = t.db.NewIterator(bucket)
+		for it.Next() {
+			batch.Delete(it.Key())
+		}
+		it.Release()
 	}
-	it.Release()
 
-	return t.db.Write(batch, nil)
+	return t.db.Write(batch)
 }
 
 func (t *txs) Close() error {
@@ -5,10 +5,10 @@ import (
 	"encoding/binary"
 	"sync"
 
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
 	"github.com/elastos/Elastos.ELA.SPV/util"
 
 	"github.com/elastos/Elastos.ELA/common"
-	"github.com/syndtr/goleveldb/leveldb"
 )
 
 // Ensure txsBatch implement TxsBatch interface.
@@ -16,8 +16,8 @@ var _ TxsBatch = (*txsBatch)(nil)
 
 type txsBatch struct {
 	sync.Mutex
-	*leveldb.DB
-	*leveldb.Batch
+	kvdb.DB
+	kvdb.Batch
 	addTxs []*util.Tx
 	delTxs []*util.Tx
 }
@@ -36,12 +36,20 @@ func (b *txsBatch) Put(tx *util.Tx) error {
 	return nil
 }
 
+func (b *txsBatch) PutAddrIndex(tx *util.Tx, addrs []common.Uint168) error {
+	b.Lock()
+	defer b.Unlock()
+
+	putAddrIndex(b.Batch, tx, addrs)
+	return nil
+}
+
 func (b *txsBatch) Del(txId *common.Uint256) error {
 	b.Lock()
 	defer b.Unlock()
 
 	var tx util.Tx
-	data, err := b.DB.Get(toKey(BKTTxs, txId.Bytes()...), nil)
+	data, err := b.DB.Get(toKey(BKTTxs, txId.Bytes()...))
 	if err != nil {
 		return err
 	}
@@ -50,6 +58,7 @@ func (b *txsBatch) Del(txId *common.Uint256) error {
 	}
 
 	b.Batch.Delete(toKey(BKTTxs, txId.Bytes()...))
+	delAddrIndex(b.DB, b.Batch, txId, tx.Height)
 	b.delTxs = append(b.delTxs, &tx)
 	return nil
 }
@@ -60,9 +69,10 @@ func (b *txsBatch) DelAll(height uint32) error {
 
 	var key [4]byte
 	binary.BigEndian.PutUint32(key[:], height)
-	data, _ := b.DB.Get(toKey(BKTHeightTxs, key[:]...), nil)
+	data, _ := b.DB.Get(toKey(BKTHeightTxs, key[:]...))
 	for _, txID := range getTxIds(data) {
 		b.Batch.Delete(toKey(BKTTxs, txID.Bytes()...))
+		delAddrIndex(b.DB, b.Batch, txID, height)
 	}
 	b.Batch.Delete(toKey(BKTHeightTxs, key[:]...))
 
@@ -72,6 +82,7 @@ func (b *txsBatch) DelAll(height uint32) error {
 func (b *txsBatch) Rollback() error {
 	b.Lock()
 	defer b.Unlock()
+	b.addTxs, b.delTxs = nil, nil
 	b.Batch.Reset()
 	return nil
 }
@@ -80,13 +91,19 @@ func (b *txsBatch) Commit() error {
 	b.Lock()
 	defer b.Unlock()
 
+	b.index()
+	return b.DB.Write(b.Batch)
+}
+
+// index puts the height index of added and deleted transactions into batch.
+func (b *txsBatch) index() {
 	// Put height index for added transactions.
 	if len(b.addTxs) > 0 {
 		groups := groupByHeight(b.addTxs)
 		for height, txs := range groups {
 			var key [4]byte
 			binary.BigEndian.PutUint32(key[:], height)
-			data, _ := b.DB.Get(toKey(BKTHeightTxs, key[:]...), nil)
+			data, _ := b.DB.Get(toKey(BKTHeightTxs, key[:]...))
 			for _, tx := range txs {
 				data = putTxId(data, &tx.Hash)
 			}
@@ -100,26 +117,20 @@ func (b *txsBatch) Commit() error {
 		for height, txs := range groups {
 			var key [4]byte
 			binary.BigEndian.PutUint32(key[:], height)
-			data, _ := b.DB.Get(toKey(BKTHeightTxs, key[:]...), nil)
+			data, _ := b.DB.Get(toKey(BKTHeightTxs, key[:]...))
 			for _, tx := range txs {
 				data = delTxId(data, &tx.Hash)
 			}
-			b.Batch.Delete(toKey(BKTHeightTxs, key[:]...))
+			b.Batch.Put(toKey(BKTHeightTxs, key[:]...), data)
 		}
 	}
-
-	return b.DB.Write(b.Batch, nil)
+	b.addTxs, b.delTxs = nil, nil
 }
 
 func groupByHeight(txs []*util.Tx) map[uint32][]*util.Tx {
 	txGroups := make(map[uint32][]*util.Tx)
 	for _, tx := range txs {
-		group, ok := txGroups[tx.Height]
-		if !ok {
-			txGroups[tx.Height] = append(group, tx)
-		} else {
-			group = append(group, tx)
-		}
+		txGroups[tx.Height] = append(txGroups[tx.Height], tx)
 	}
 	return txGroups
 }
@@ -0,0 +1,297 @@
+package store
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+)
+
+var (
+	// BKTUTXOs stores the outputs of registered addresses, the key is
+	// address + outpoint, so the outputs of an address can be iterated.
+	BKTUTXOs = []byte("N")
+)
+
+// UTXO is an output of a registered address and it's spent state.
+type UTXO struct {
+	// Op is the outpoint of the output.
+	Op util.OutPoint
+
+	// Address is the program hash the output paid to.
+	Address common.Uint168
+
+	// AssetID is the asset of the output value.
+	AssetID common.Uint256
+
+	// Value is the amount of the output.
+	Value common.Fixed64
+
+	// OutputLock is the height before which the output can not be spent.
+	OutputLock uint32
+
+	// Height is the block height of the transaction created the output.
+	Height uint32
+
+	// Coinbase indicates the output is created by a coinbase transaction.
+	Coinbase bool
+
+	// SpendTxId is the transaction spent the output, empty if unspent.
+	SpendTxId common.Uint256
+
+	// SpendHeight is the block height of the spend transaction.
+	SpendHeight uint32
+}
+
+// IsSpent returns if the output has been spent.
+func (u *UTXO) IsSpent() bool {
+	return u.SpendTxId != common.EmptyHash
+}
+
+func (u *UTXO) Serialize(w io.Writer) error {
+	if err := u.Op.Serialize(w); err != nil {
+		return err
+	}
+	if err := u.Address.Serialize(w); err != nil {
+		return err
+	}
+	if err := u.AssetID.Serialize(w); err != nil {
+		return err
+	}
+	if err := u.Value.Serialize(w); err != nil {
+		return err
+	}
+	if err := common.WriteUint32(w, u.OutputLock); err != nil {
+		return err
+	}
+	if err := common.WriteUint32(w, u.Height); err != nil {
+		return err
+	}
+	var coinbase uint8
+	if u.Coinbase {
+		coinbase = 1
+	}
+	if err := common.WriteUint8(w, coinbase); err != nil {
+		return err
+	}
+	if err := u.SpendTxId.Serialize(w); err != nil {
+		return err
+	}
+	return common.WriteUint32(w, u.SpendHeight)
+}
+
+func (u *UTXO) Deserialize(r io.Reader) (err error) {
+	if err = u.Op.Deserialize(r); err != nil {
+		return err
+	}
+	if err = u.Address.Deserialize(r); err != nil {
+		return err
+	}
+	if err = u.AssetID.Deserialize(r); err != nil {
+		return err
+	}
+	if err = u.Value.Deserialize(r); err != nil {
+		return err
+	}
+	if u.OutputLock, err = common.ReadUint32(r); err != nil {
+		return err
+	}
+	if u.Height, err = common.ReadUint32(r); err != nil {
+		return err
+	}
+	coinbase, err := common.ReadUint8(r)
+	if err != nil {
+		return err
+	}
+	u.Coinbase = coinbase == 1
+	if err = u.SpendTxId.Deserialize(r); err != nil {
+		return err
+	}
+	u.SpendHeight, err = common.ReadUint32(r)
+	return err
+}
+
+func utxoKey(addr *common.Uint168, op *util.OutPoint) []byte {
+	return append(toKey(BKTUTXOs, addr[:]...), op.Bytes()...)
+}
+
+func putUTXO(batch kvdb.Batch, utxo *UTXO) error {
+	buf := new(bytes.Buffer)
+	if err := utxo.Serialize(buf); err != nil {
+		return err
+	}
+	batch.Put(utxoKey(&utxo.Address, &utxo.Op), buf.Bytes())
+	return nil
+}
+
+// getUTXO returns the UTXO of the given outpoint, the address of outpoint is
+// looked up from the ops.
+func getUTXO(db kvdb.DB, op *util.OutPoint) (*UTXO, error) {
+	addr, err := db.Get(toKey(BKTOps, op.Bytes()...))
+	if err != nil {
+		return nil, err
+	}
+	hash, err := common.Uint168FromBytes(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := db.Get(utxoKey(hash, op))
+	if err != nil {
+		return nil, err
+	}
+	var utxo UTXO
+	if err := utxo.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return &utxo, nil
+}
+
+// buildUTXOs creates the UTXOs of the stored transactions from the ops.  The
+// transactions are read from BKTTxs, the height index may miss transactions
+// stored before it was written by data batch, and applied by height so spends
+// are marked after the spent outputs are created.
+func buildUTXOs(db kvdb.DB) error {
+	heights := make(map[uint32]map[common.Uint256]*types.Transaction)
+	it := db.NewIterator(BKTTxs)
+	for it.Next() {
+		var utx util.Tx
+		if err := utx.Deserialize(bytes.NewReader(it.Value())); err != nil {
+			it.Release()
+			return err
+		}
+		var tx types.Transaction
+		err := tx.Deserialize(bytes.NewReader(utx.RawData))
+		if err != nil {
+			it.Release()
+			return err
+		}
+		txs, ok := heights[utx.Height]
+		if !ok {
+			txs = make(map[common.Uint256]*types.Transaction)
+			heights[utx.Height] = txs
+		}
+		txs[utx.Hash] = &tx
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	sorted := make([]uint32, 0, len(heights))
+	for height := range heights {
+		sorted = append(sorted, height)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	for _, height := range sorted {
+		batch := &utxosBatch{DB: db, Batch: db.NewBatch()}
+		for _, tx := range sortBySpends(heights[height]) {
+			// Only outputs of registered addresses have ops.
+			var addrs []common.Uint168
+			for index := range tx.Outputs {
+				op := util.NewOutPoint(tx.Hash(), uint16(index))
+				data, err := db.Get(toKey(BKTOps, op.Bytes()...))
+				if err != nil {
+					continue
+				}
+				addr, err := common.Uint168FromBytes(data)
+				if err != nil {
+					return err
+				}
+				addrs = append(addrs, *addr)
+			}
+			if err := batch.PutTx(tx, height, addrs); err != nil {
+				return err
+			}
+		}
+		if err := batch.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sortBySpends orders the transactions of a block, so a transaction is after
+// the transactions it spends.
+func sortBySpends(txs map[common.Uint256]*types.Transaction) []*types.Transaction {
+	sorted := make([]*types.Transaction, 0, len(txs))
+	visited := make(map[common.Uint256]bool)
+	var visit func(txId common.Uint256)
+	visit = func(txId common.Uint256) {
+		if visited[txId] {
+			return
+		}
+		visited[txId] = true
+		tx := txs[txId]
+		for _, input := range tx.Inputs {
+			if _, ok := txs[input.Previous.TxID]; ok {
+				visit(input.Previous.TxID)
+			}
+		}
+		sorted = append(sorted, tx)
+	}
+	for txId := range txs {
+		visit(txId)
+	}
+	return sorted
+}
+
+// Ensure utxos implement UTXOs interface.
+var _ UTXOs = (*utxos)(nil)
+
+type utxos struct {
+	sync.RWMutex
+	db kvdb.DB
+}
+
+func NewUTXOs(db kvdb.DB) *utxos {
+	return &utxos{db: db}
+}
+
+func (u *utxos) Get(op *util.OutPoint) (*UTXO, error) {
+	u.RLock()
+	defer u.RUnlock()
+	return getUTXO(u.db, op)
+}
+
+func (u *utxos) GetByAddress(addr *common.Uint168) ([]*UTXO, error) {
+	u.RLock()
+	defer u.RUnlock()
+
+	it := u.db.NewIterator(toKey(BKTUTXOs, addr[:]...))
+	defer it.Release()
+	var utxos []*UTXO
+	for it.Next() {
+		var utxo UTXO
+		if err := utxo.Deserialize(bytes.NewReader(it.Value())); err != nil {
+			return nil, err
+		}
+		utxos = append(utxos, &utxo)
+	}
+	return utxos, it.Error()
+}
+
+func (u *utxos) Clear() error {
+	u.Lock()
+	defer u.Unlock()
+
+	it := u.db.NewIterator(BKTUTXOs)
+	batch := u.db.NewBatch()
+	for it.Next() {
+		batch.Delete(it.Key())
+	}
+	it.Release()
+	return u.db.Write(batch)
+}
+
+func (u *utxos) Close() error {
+	u.Lock()
+	return nil
+}
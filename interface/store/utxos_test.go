@@ -0,0 +1,216 @@
+package store
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUTXOs(t *testing.T) {
+	db, err := NewMemDataStore()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+
+	var addr, other common.Uint168
+	rand.Read(addr[:])
+	rand.Read(other[:])
+
+	putTx := func(batch DataBatch, tx *types.Transaction, height uint32) {
+		buf := new(bytes.Buffer)
+		if !assert.NoError(t, tx.Serialize(buf)) {
+			t.FailNow()
+		}
+		utx := &util.Tx{Hash: tx.Hash(), Height: height, RawData: buf.Bytes()}
+		for index, output := range tx.Outputs {
+			if output.ProgramHash.IsEqual(addr) {
+				op := util.NewOutPoint(tx.Hash(), uint16(index))
+				batch.Ops().Put(op, addr)
+			}
+		}
+		if !assert.NoError(t, batch.Txs().Put(utx)) ||
+			!assert.NoError(t, batch.UTXOs().PutTx(tx, height,
+				[]common.Uint168{addr})) {
+			t.FailNow()
+		}
+	}
+
+	// Height 1 pays 100 and 200 to addr, the 200 output is spent by a
+	// transaction in the same block, which pays 150 back to addr.
+	tx1 := &types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Outputs: []*types.Output{
+			{ProgramHash: addr, Value: 100},
+			{ProgramHash: addr, Value: 200},
+			{ProgramHash: other, Value: 300},
+		},
+	}
+	tx2 := &types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Inputs: []*types.Input{
+			{Previous: *types.NewOutPoint(tx1.Hash(), 1)},
+		},
+		Outputs: []*types.Output{
+			{ProgramHash: other, Value: 50},
+			{ProgramHash: addr, Value: 150},
+		},
+	}
+	batch := db.Batch()
+	putTx(batch, tx1, 1)
+	putTx(batch, tx2, 1)
+	if !assert.NoError(t, batch.Commit()) {
+		t.FailNow()
+	}
+
+	utxos, err := db.UTXOs().GetByAddress(&addr)
+	if !assert.NoError(t, err) || !assert.Equal(t, 3, len(utxos)) {
+		t.FailNow()
+	}
+	var unspent common.Fixed64
+	for _, utxo := range utxos {
+		if !utxo.IsSpent() {
+			unspent += utxo.Value
+		}
+	}
+	assert.Equal(t, common.Fixed64(250), unspent)
+
+	// Height 2 spends the 150 output.
+	tx3 := &types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Inputs: []*types.Input{
+			{Previous: *types.NewOutPoint(tx2.Hash(), 1)},
+		},
+		Outputs: []*types.Output{{ProgramHash: other, Value: 150}},
+	}
+	batch = db.Batch()
+	putTx(batch, tx3, 2)
+	if !assert.NoError(t, batch.Commit()) {
+		t.FailNow()
+	}
+	utxo, err := db.UTXOs().Get(util.NewOutPoint(tx2.Hash(), 1))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, tx3.Hash(), utxo.SpendTxId)
+	assert.Equal(t, uint32(2), utxo.SpendHeight)
+
+	// Rollback height 2 marks the output unspent again.
+	batch = db.Batch()
+	if !assert.NoError(t, batch.DelAll(2)) ||
+		!assert.NoError(t, batch.Commit()) {
+		t.FailNow()
+	}
+	utxo, err = db.UTXOs().Get(util.NewOutPoint(tx2.Hash(), 1))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.False(t, utxo.IsSpent())
+
+	// Rollback height 1 deletes all outputs.
+	batch = db.Batch()
+	if !assert.NoError(t, batch.DelAll(1)) ||
+		!assert.NoError(t, batch.Commit()) {
+		t.FailNow()
+	}
+	utxos, err = db.UTXOs().GetByAddress(&addr)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 0, len(utxos))
+}
+
+func TestBuildUTXOs(t *testing.T) {
+	db, err := kvdb.NewMemDB()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+
+	var addr, other common.Uint168
+	rand.Read(addr[:])
+	rand.Read(other[:])
+
+	// Transactions stored without the height index, like data stored
+	// before the height index was written by data batch.
+	putTx := func(tx *types.Transaction, height uint32) {
+		buf := new(bytes.Buffer)
+		if !assert.NoError(t, tx.Serialize(buf)) {
+			t.FailNow()
+		}
+		utx := &util.Tx{Hash: tx.Hash(), Height: height, RawData: buf.Bytes()}
+		buf = new(bytes.Buffer)
+		if !assert.NoError(t, utx.Serialize(buf)) ||
+			!assert.NoError(t, db.Put(toKey(BKTTxs, utx.Hash.Bytes()...),
+				buf.Bytes())) {
+			t.FailNow()
+		}
+		for index, output := range tx.Outputs {
+			if output.ProgramHash.IsEqual(addr) {
+				op := util.NewOutPoint(tx.Hash(), uint16(index))
+				db.Put(toKey(BKTOps, op.Bytes()...), addr.Bytes())
+			}
+		}
+	}
+
+	// tx2 spends tx1 in the same block, tx3 spends tx2 in the next block.
+	tx1 := &types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Outputs: []*types.Output{{ProgramHash: addr, Value: 100}},
+	}
+	tx2 := &types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Inputs: []*types.Input{
+			{Previous: *types.NewOutPoint(tx1.Hash(), 0)},
+		},
+		Outputs: []*types.Output{
+			{ProgramHash: other, Value: 40},
+			{ProgramHash: addr, Value: 60},
+		},
+	}
+	tx3 := &types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Inputs: []*types.Input{
+			{Previous: *types.NewOutPoint(tx2.Hash(), 1)},
+		},
+		Outputs: []*types.Output{{ProgramHash: addr, Value: 60}},
+	}
+	putTx(tx3, 2)
+	putTx(tx2, 1)
+	putTx(tx1, 1)
+
+	if !assert.NoError(t, buildUTXOs(db)) {
+		t.FailNow()
+	}
+	utxos := NewUTXOs(db)
+	for _, c := range []struct {
+		op          *util.OutPoint
+		spendTxId   common.Uint256
+		spendHeight uint32
+	}{
+		{util.NewOutPoint(tx1.Hash(), 0), tx2.Hash(), 1},
+		{util.NewOutPoint(tx2.Hash(), 1), tx3.Hash(), 2},
+		{util.NewOutPoint(tx3.Hash(), 0), common.EmptyHash, 0},
+	} {
+		utxo, err := utxos.Get(c.op)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assert.Equal(t, c.spendTxId, utxo.SpendTxId)
+		assert.Equal(t, c.spendHeight, utxo.SpendHeight)
+	}
+}
@@ -0,0 +1,141 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+)
+
+// Ensure utxosBatch implement UTXOsBatch interface.
+var _ UTXOsBatch = (*utxosBatch)(nil)
+
+type utxosBatch struct {
+	sync.Mutex
+	kvdb.DB
+	kvdb.Batch
+
+	// pending are the UTXOs put or deleted (nil) in this batch, so
+	// transactions in the same block can spend outputs not committed yet.
+	pending map[util.OutPoint]*UTXO
+}
+
+func (b *utxosBatch) get(op *util.OutPoint) (*UTXO, error) {
+	if utxo, ok := b.pending[*op]; ok {
+		if utxo == nil {
+			return nil, kvdb.ErrNotFound
+		}
+		return utxo, nil
+	}
+	return getUTXO(b.DB, op)
+}
+
+func (b *utxosBatch) put(utxo *UTXO) error {
+	if b.pending == nil {
+		b.pending = make(map[util.OutPoint]*UTXO)
+	}
+	b.pending[utxo.Op] = utxo
+	return putUTXO(b.Batch, utxo)
+}
+
+func (b *utxosBatch) del(utxo *UTXO) {
+	if b.pending == nil {
+		b.pending = make(map[util.OutPoint]*UTXO)
+	}
+	b.pending[utxo.Op] = nil
+	b.Batch.Delete(utxoKey(&utxo.Address, &utxo.Op))
+}
+
+func (b *utxosBatch) PutTx(tx *types.Transaction, height uint32,
+	addrs []common.Uint168) error {
+	b.Lock()
+	defer b.Unlock()
+
+	txId := tx.Hash()
+	for index, output := range tx.Outputs {
+		var match bool
+		for _, addr := range addrs {
+			if addr.IsEqual(output.ProgramHash) {
+				match = true
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+
+		err := b.put(&UTXO{
+			Op:         *util.NewOutPoint(txId, uint16(index)),
+			Address:    output.ProgramHash,
+			AssetID:    output.AssetID,
+			Value:      output.Value,
+			OutputLock: output.OutputLock,
+			Height:     height,
+			Coinbase:   tx.IsCoinBaseTx(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, input := range tx.Inputs {
+		op := util.NewOutPoint(input.Previous.TxID, input.Previous.Index)
+		utxo, err := b.get(op)
+		if err != nil {
+			continue
+		}
+		utxo.SpendTxId = txId
+		utxo.SpendHeight = height
+		if err := b.put(utxo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *utxosBatch) DelTx(tx *types.Transaction) error {
+	b.Lock()
+	defer b.Unlock()
+
+	txId := tx.Hash()
+	for index := range tx.Outputs {
+		op := util.NewOutPoint(txId, uint16(index))
+		utxo, err := b.get(op)
+		if err != nil {
+			continue
+		}
+		b.del(utxo)
+	}
+
+	for _, input := range tx.Inputs {
+		op := util.NewOutPoint(input.Previous.TxID, input.Previous.Index)
+		utxo, err := b.get(op)
+		if err != nil || !utxo.SpendTxId.IsEqual(txId) {
+			continue
+		}
+		utxo.SpendTxId = common.EmptyHash
+		utxo.SpendHeight = 0
+		if err := b.put(utxo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *utxosBatch) Rollback() error {
+	b.Lock()
+	defer b.Unlock()
+	b.pending = nil
+	b.Batch.Reset()
+	return nil
+}
+
+func (b *utxosBatch) Commit() error {
+	b.Lock()
+	defer b.Unlock()
+	b.pending = nil
+	return b.DB.Write(b.Batch)
+}
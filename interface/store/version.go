@@ -0,0 +1,71 @@
+package store
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/interface/store/kvdb"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+)
+
+var (
+	BKTVersion = []byte("V")
+)
+
+// headersMigrations returns the ordered migration steps of the headers
+// database, add a step with the next version when the data format changes.
+func headersMigrations(db kvdb.DB, newHeader func() util.BlockHeader) []database.Migration {
+	return []database.Migration{
+		{Version: 1, Description: "data format before versioning"},
+		{Version: 2, Description: "rebuild height index overwritten by fork headers",
+			Upgrade: func() error {
+				return rebuildIndex(db, newHeader)
+			}},
+	}
+}
+
+// dataMigrations returns the ordered migration steps of the data store, add a
+// step with the next version when the data format changes.
+func dataMigrations(db kvdb.DB) []database.Migration {
+	return []database.Migration{
+		{Version: 1, Description: "data format before versioning"},
+		{Version: 2, Description: "build address transaction index",
+			Upgrade: func() error {
+				return buildAddrIndex(db)
+			}},
+		{Version: 3, Description: "build UTXOs of registered addresses",
+			Upgrade: func() error {
+				return buildUTXOs(db)
+			}},
+	}
+}
+
+// Ensure version implement database.Versioned interface.
+var _ database.Versioned = (*version)(nil)
+
+// version records the data format version of a key-value database, the
+// version record is kept when the database is cleared.
+type version struct {
+	db kvdb.DB
+}
+
+func (v *version) Version() (uint32, error) {
+	data, err := v.db.Get(BKTVersion)
+	if err == kvdb.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 4 {
+		return 0, fmt.Errorf("invalid version record %x", data)
+	}
+	return binary.BigEndian.Uint32(data), nil
+}
+
+func (v *version) SetVersion(version uint32) error {
+	var data [4]byte
+	binary.BigEndian.PutUint32(data[:], version)
+	return v.db.Put(BKTVersion, data[:])
+}
@@ -0,0 +1,369 @@
+package _interface
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+)
+
+// defaultSubscriptionBuffer is the default buffer size of the event channel
+// returned by Subscribe.
+const defaultSubscriptionBuffer = 64
+
+// EventType is the type of a subscription event.
+type EventType uint8
+
+const (
+	// EventTransaction is a transaction with the merkle proof, the proof is
+	// empty for an unconfirmed transaction.
+	EventTransaction EventType = iota
+
+	// EventConfirmation is a new block confirmed the notified transaction,
+	// sent until the required confirmations are reached.
+	EventConfirmation
+
+	// EventRevoked is the notified transaction has been rolled back by a
+	// chain reorganization.
+	EventRevoked
+
+	// EventDropped is the notified unconfirmed transaction has been dropped.
+	EventDropped
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventTransaction:
+		return "transaction"
+	case EventConfirmation:
+		return "confirmation"
+	case EventRevoked:
+		return "revoked"
+	case EventDropped:
+		return "dropped"
+	default:
+		return fmt.Sprintf("EventType%d", t)
+	}
+}
+
+// SubscriptionFilter is the conditions of the transactions to subscribe.
+type SubscriptionFilter struct {
+	// Address is the address to subscribe.
+	Address string
+
+	// Type is the transaction type to subscribe, Options can extend it to
+	// multiple types.
+	Type types.TxType
+
+	// Flags are the notification flags, FlagNotifyConfirmed is ignored as
+	// transactions are sent when packed and followed by confirmations.
+	Flags uint64
+
+	// Options extends the conditions of the transactions, it's Confirmations
+	// is the confirmations to send EventConfirmation until.
+	Options *ListenerOptions
+
+	// AutoAck submits the receipt of a transaction once the event is sent to
+	// the channel, otherwise Event.Ack must be called.
+	AutoAck bool
+
+	// BufferSize is the buffer size of the event channel, 64 will be used if
+	// zero.
+	BufferSize int
+}
+
+// Event is an event of a subscription.
+type Event struct {
+	// Type is the type of the event.
+	Type EventType
+
+	// NotifyId is the key of the subscription's notifications.
+	NotifyId common.Uint256
+
+	// TxId is the id of the transaction.
+	TxId common.Uint256
+
+	// Tx is the transaction, nil for EventRevoked and EventDropped.
+	Tx *types.Transaction
+
+	// Proof is the merkle proof of the transaction for EventTransaction.
+	Proof bloom.MerkleProof
+
+	// Height is the height of the block the transaction packed in, zero if
+	// the transaction is not packed.
+	Height uint32
+
+	// Confirmations is the confirmations of the transaction.
+	Confirmations uint32
+
+	// Reason is the reason the transaction was dropped for EventDropped.
+	Reason DropReason
+
+	service *spvservice
+}
+
+// Ack submits the receipt of the transaction, so the transaction will be
+// removed from the notify queue.  It has no effect with AutoAck.
+func (e *Event) Ack() error {
+	if e.Type != EventTransaction {
+		return nil
+	}
+	return e.service.SubmitTransactionReceipt(e.NotifyId, e.TxId)
+}
+
+// blockListener is a listener told when a block is committed.
+type blockListener interface {
+	blockCommitted(height uint32)
+}
+
+// watched is a notified transaction waiting for confirmations.
+type watched struct {
+	tx     *types.Transaction
+	height uint32
+	target uint32
+}
+
+// subscription is a TransactionListener sending events to a channel.  Events
+// other than transactions are queued and sent by it's own goroutine, so the
+// sync goroutine is never blocked by a slow receiver.
+type subscription struct {
+	ctx     context.Context
+	service *spvservice
+	filter  SubscriptionFilter
+	events  chan *Event
+	wake    chan struct{}
+	done    chan struct{}
+
+	mtx     sync.RWMutex
+	closed  bool
+	queue   []*Event
+	watches map[common.Uint256]*watched
+}
+
+func (s *subscription) Address() string {
+	return s.filter.Address
+}
+
+func (s *subscription) Type() types.TxType {
+	return s.filter.Type
+}
+
+func (s *subscription) Flags() uint64 {
+	return s.filter.Flags &^ FlagNotifyConfirmed
+}
+
+func (s *subscription) Options() *ListenerOptions {
+	return s.filter.Options
+}
+
+// send sends the transaction event to the channel, it returns false if the
+// subscription is closed, the channel is full or other events are waiting
+// to be sent.  Transactions not sent will be sent again if not receipted.
+func (s *subscription) send(event *Event) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	if s.closed || len(s.queue) > 0 {
+		return false
+	}
+	select {
+	case s.events <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+// enqueue queues the events to be sent by the subscription's goroutine.
+func (s *subscription) enqueue(events ...*Event) {
+	if len(events) == 0 {
+		return
+	}
+	s.mtx.Lock()
+	if s.closed {
+		s.mtx.Unlock()
+		return
+	}
+	s.queue = append(s.queue, events...)
+	s.mtx.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run sends the queued events to the channel until the context is done.  An
+// event is removed from the queue after it is sent, so transaction events
+// are not sent before it.
+func (s *subscription) run() {
+	defer close(s.done)
+	for {
+		s.mtx.RLock()
+		var event *Event
+		if len(s.queue) > 0 {
+			event = s.queue[0]
+		}
+		s.mtx.RUnlock()
+
+		if event == nil {
+			select {
+			case <-s.wake:
+				continue
+			case <-s.ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case s.events <- event:
+			s.mtx.Lock()
+			s.queue[0] = nil
+			s.queue = s.queue[1:]
+			s.mtx.Unlock()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *subscription) Notify(notifyId common.Uint256, proof bloom.MerkleProof,
+	tx types.Transaction) {
+
+	event := &Event{
+		Type:     EventTransaction,
+		NotifyId: notifyId,
+		TxId:     tx.Hash(),
+		Tx:       &tx,
+		Proof:    proof,
+		Height:   proof.Height,
+		service:  s.service,
+	}
+	if proof.Height > 0 {
+		if best, err := s.service.headers.GetBest(); err == nil &&
+			best.Height > proof.Height {
+			event.Confirmations = best.Height - proof.Height
+		}
+	}
+	if !s.send(event) {
+		return
+	}
+
+	if proof.Height > 0 {
+		target := getConfirmations(s, tx)
+		if event.Confirmations < target {
+			s.mtx.Lock()
+			s.watches[event.TxId] = &watched{
+				tx: &tx, height: proof.Height, target: target,
+			}
+			s.mtx.Unlock()
+		}
+	}
+	if s.filter.AutoAck {
+		s.service.SubmitTransactionReceipt(notifyId, event.TxId)
+	}
+}
+
+func (s *subscription) Revoked(notifyId common.Uint256, txId common.Uint256,
+	height uint32) {
+
+	s.mtx.Lock()
+	delete(s.watches, txId)
+	s.mtx.Unlock()
+
+	s.enqueue(&Event{
+		Type:     EventRevoked,
+		NotifyId: notifyId,
+		TxId:     txId,
+		Height:   height,
+		service:  s.service,
+	})
+}
+
+func (s *subscription) Dropped(notifyId common.Uint256, txId common.Uint256,
+	reason DropReason) {
+
+	s.enqueue(&Event{
+		Type:     EventDropped,
+		NotifyId: notifyId,
+		TxId:     txId,
+		Reason:   reason,
+		service:  s.service,
+	})
+}
+
+func (s *subscription) blockCommitted(height uint32) {
+	notifyId := getListenerKey(s)
+	var events []*Event
+	s.mtx.Lock()
+	for txId, w := range s.watches {
+		if height <= w.height {
+			continue
+		}
+		confirmations := height - w.height
+		if confirmations >= w.target {
+			delete(s.watches, txId)
+		}
+		events = append(events, &Event{
+			Type:          EventConfirmation,
+			NotifyId:      notifyId,
+			TxId:          txId,
+			Tx:            w.tx,
+			Height:        w.height,
+			Confirmations: confirmations,
+			service:       s.service,
+		})
+	}
+	s.mtx.Unlock()
+
+	s.enqueue(events...)
+}
+
+// close closes the event channel, the subscription must be unregistered
+// and it's goroutine must be exited before close.
+func (s *subscription) close() {
+	s.mtx.Lock()
+	s.closed = true
+	s.queue = nil
+	close(s.events)
+	s.mtx.Unlock()
+}
+
+// Subscribe registers a listener of the filter and returns the channel of
+// it's events.  The listener is unregistered and the channel is closed when
+// the context is done.
+func (s *spvservice) Subscribe(ctx context.Context,
+	filter *SubscriptionFilter) (<-chan *Event, error) {
+
+	size := filter.BufferSize
+	if size <= 0 {
+		size = defaultSubscriptionBuffer
+	}
+	sub := &subscription{
+		ctx:     ctx,
+		service: s,
+		filter:  *filter,
+		events:  make(chan *Event, size),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		watches: make(map[common.Uint256]*watched),
+	}
+	if err := s.RegisterTransactionListener(sub); err != nil {
+		return nil, err
+	}
+
+	go sub.run()
+	go func() {
+		<-ctx.Done()
+		<-sub.done
+		if err := s.UnregisterTransactionListener(sub); err != nil {
+			log.Warnf("Unregister subscription of address %s failed, %s",
+				filter.Address, err)
+		}
+		sub.close()
+	}()
+	return sub.events, nil
+}
@@ -0,0 +1,76 @@
+package _interface
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionEvents(t *testing.T) {
+	var addr common.Uint168
+	rand.Read(addr[:])
+	addr[0] = 0x21
+	address, err := addr.ToAddress()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub := &subscription{
+		ctx: ctx,
+		filter: SubscriptionFilter{
+			Address: address,
+			Type:    types.TransferAsset,
+			Flags:   FlagNotifyConfirmed,
+		},
+		events:  make(chan *Event, 1),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		watches: make(map[common.Uint256]*watched),
+	}
+	assert.Equal(t, uint64(0), sub.Flags())
+	go sub.run()
+
+	tx := &types.Transaction{TxType: types.TransferAsset}
+	txId := tx.Hash()
+	sub.watches[txId] = &watched{tx: tx, height: 100, target: 2}
+
+	// Confirmations are sent until the target is reached, the sync goroutine
+	// is not blocked by the full channel.
+	for height := uint32(100); height <= 103; height++ {
+		sub.blockCommitted(height)
+	}
+
+	// Transactions are not sent before the queued events.
+	assert.False(t, sub.send(&Event{Type: EventTransaction}))
+
+	for _, confirmations := range []uint32{1, 2} {
+		event := <-sub.events
+		assert.Equal(t, EventConfirmation, event.Type)
+		assert.Equal(t, txId, event.TxId)
+		assert.Equal(t, confirmations, event.Confirmations)
+	}
+	assert.Equal(t, 0, len(sub.events))
+	assert.Equal(t, 0, len(sub.watches))
+
+	// Revocation stops the confirmations.
+	sub.watches[txId] = &watched{tx: tx, height: 100, target: 6}
+	sub.Revoked(getListenerKey(sub), txId, 100)
+	event := <-sub.events
+	assert.Equal(t, EventRevoked, event.Type)
+	assert.Equal(t, 0, len(sub.watches))
+	assert.NoError(t, event.Ack())
+
+	// Nothing is sent after closed.
+	cancel()
+	<-sub.done
+	sub.close()
+	assert.False(t, sub.send(&Event{}))
+	sub.enqueue(&Event{})
+	_, ok := <-sub.events
+	assert.False(t, ok)
+}
@@ -0,0 +1,168 @@
+package _interface
+
+import (
+	"time"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+)
+
+// defaultUnconfirmedTimeout is the default period to drop an unconfirmed
+// transaction not packed.
+const defaultUnconfirmedTimeout = 24 * time.Hour
+
+// unconfirmedTx is an announced transaction notified to listeners with
+// FlagNotifyUnconfirmed and waiting to be packed.
+type unconfirmedTx struct {
+	tx        *types.Transaction
+	notifyIds []common.Uint256
+	seen      time.Time
+}
+
+// TransactionAnnounce will be invoked when received a new announced transaction.
+func (s *spvservice) TransactionAnnounce(tx util.Transaction) {
+	s.announceTx(tx.(*iutil.Tx).Transaction, time.Now())
+}
+
+// announceTx notifies the announced transaction to the matched listeners with
+// FlagNotifyUnconfirmed, and tracks it until it is packed or dropped.
+func (s *spvservice) announceTx(tx *types.Transaction, now time.Time) {
+	txId := tx.Hash()
+	s.pendingMtx.Lock()
+	_, ok := s.pending[txId]
+	s.pendingMtx.Unlock()
+	if ok {
+		return
+	}
+
+	hits := make(map[common.Uint168]struct{})
+	for _, output := range tx.Outputs {
+		if s.db.Addrs().GetFilter().ContainAddr(output.ProgramHash) {
+			hits[output.ProgramHash] = struct{}{}
+		}
+	}
+	for _, input := range tx.Inputs {
+		op := input.Previous
+		addr := s.db.Ops().HaveOp(util.NewOutPoint(op.TxID, op.Index))
+		if addr != nil {
+			hits[*addr] = struct{}{}
+		}
+	}
+	if len(hits) == 0 {
+		return
+	}
+
+	var notified []TransactionListener
+	utx := &unconfirmedTx{tx: tx, seen: now}
+	s.listenerMtx.RLock()
+	for key, listener := range s.listeners {
+		if listener.Flags()&FlagNotifyUnconfirmed != FlagNotifyUnconfirmed {
+			continue
+		}
+		hash, err := common.Uint168FromAddress(listener.Address())
+		if err != nil {
+			log.Errorf("invalid listener address %s, %s",
+				listener.Address(), err)
+			continue
+		}
+		if _, ok := hits[*hash]; !ok {
+			continue
+		}
+		if !matchListener(listener, hash, tx) {
+			continue
+		}
+		utx.notifyIds = append(utx.notifyIds, key)
+		notified = append(notified, listener)
+	}
+	s.listenerMtx.RUnlock()
+	if len(notified) == 0 {
+		return
+	}
+
+	s.pendingMtx.Lock()
+	s.pending[txId] = utx
+	s.pendingMtx.Unlock()
+
+	// The transaction is not packed yet, so there is no proof.
+	for i, listener := range notified {
+		listener.Notify(utx.notifyIds[i], bloom.MerkleProof{}, *tx)
+	}
+}
+
+// isUnconfirmed returns if the unconfirmed transaction is notified to the
+// listener and waiting to be packed, so it's receipt is not an error.
+func (s *spvservice) isUnconfirmed(notifyId, txId common.Uint256) bool {
+	s.pendingMtx.Lock()
+	defer s.pendingMtx.Unlock()
+	utx, ok := s.pending[txId]
+	if !ok {
+		return false
+	}
+	for _, id := range utx.notifyIds {
+		if id == notifyId {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveUnconfirmed stops tracking the unconfirmed transactions packed in
+// the block, the confirmed notifications are queued when the block is
+// stored.  Transactions conflict with the block or not packed within the
+// timeout are dropped and the DropListeners are told.
+func (s *spvservice) resolveUnconfirmed(txs []*types.Transaction, now time.Time) {
+	packed := make(map[common.Uint256]struct{})
+	spent := make(map[types.OutPoint]struct{})
+	for _, tx := range txs {
+		packed[tx.Hash()] = struct{}{}
+		for _, input := range tx.Inputs {
+			spent[input.Previous] = struct{}{}
+		}
+	}
+
+	dropped := make(map[common.Uint256]DropReason)
+	s.pendingMtx.Lock()
+	for txId, utx := range s.pending {
+		if _, ok := packed[txId]; ok {
+			delete(s.pending, txId)
+			continue
+		}
+
+		for _, input := range utx.tx.Inputs {
+			if _, ok := spent[input.Previous]; ok {
+				dropped[txId] = DropDoubleSpent
+				break
+			}
+		}
+		if _, ok := dropped[txId]; !ok && now.Sub(utx.seen) > s.unconfirmedTimeout {
+			dropped[txId] = DropExpired
+		}
+	}
+	var drops []*unconfirmedTx
+	for txId := range dropped {
+		drops = append(drops, s.pending[txId])
+		delete(s.pending, txId)
+	}
+	s.pendingMtx.Unlock()
+
+	for _, utx := range drops {
+		txId := utx.tx.Hash()
+		reason := dropped[txId]
+		log.Infof("Unconfirmed transaction %s dropped, %s", txId, reason)
+		for _, notifyId := range utx.notifyIds {
+			s.listenerMtx.RLock()
+			listener, ok := s.listeners[notifyId]
+			s.listenerMtx.RUnlock()
+			if !ok {
+				continue
+			}
+			if l, ok := listener.(DropListener); ok {
+				l.Dropped(notifyId, txId, reason)
+			}
+		}
+	}
+}
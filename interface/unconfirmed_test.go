@@ -0,0 +1,86 @@
+package _interface
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+type dropListener struct {
+	optionsListener
+	dropped map[common.Uint256]DropReason
+}
+
+func (l *dropListener) Dropped(notifyId, txId common.Uint256, reason DropReason) {
+	l.dropped[txId] = reason
+}
+
+// newUnconfirmedTx creates a transaction spending the inputs, the random
+// nonce makes every transaction different.
+func newUnconfirmedTx(inputs ...types.OutPoint) *types.Transaction {
+	var nonce [8]byte
+	rand.Read(nonce[:])
+	attr := types.NewAttribute(types.Nonce, nonce[:])
+	tx := &types.Transaction{
+		TxType:     types.TransferAsset,
+		Payload:    &payload.TransferAsset{},
+		Attributes: []*types.Attribute{&attr},
+	}
+	for _, op := range inputs {
+		tx.Inputs = append(tx.Inputs, &types.Input{Previous: op})
+	}
+	return tx
+}
+
+func TestResolveUnconfirmed(t *testing.T) {
+	var notifyId common.Uint256
+	rand.Read(notifyId[:])
+	l := &dropListener{dropped: make(map[common.Uint256]DropReason)}
+	s := &spvservice{
+		listeners:          map[common.Uint256]TransactionListener{notifyId: l},
+		pending:            make(map[common.Uint256]*unconfirmedTx),
+		unconfirmedTimeout: time.Hour,
+	}
+
+	var op1, op2, op3 types.OutPoint
+	rand.Read(op1.TxID[:])
+	rand.Read(op2.TxID[:])
+	rand.Read(op3.TxID[:])
+
+	now := time.Now()
+	packed := newUnconfirmedTx(op1)
+	doubleSpent := newUnconfirmedTx(op2)
+	expired := newUnconfirmedTx(op3)
+	waiting := newUnconfirmedTx()
+	for _, tx := range []*types.Transaction{packed, doubleSpent, waiting} {
+		s.pending[tx.Hash()] = &unconfirmedTx{
+			tx: tx, notifyIds: []common.Uint256{notifyId}, seen: now,
+		}
+	}
+	s.pending[expired.Hash()] = &unconfirmedTx{
+		tx: expired, notifyIds: []common.Uint256{notifyId},
+		seen: now.Add(-2 * time.Hour),
+	}
+
+	// Receipts of unconfirmed notifications are accepted.
+	var other common.Uint256
+	rand.Read(other[:])
+	assert.True(t, s.isUnconfirmed(notifyId, waiting.Hash()))
+	assert.False(t, s.isUnconfirmed(other, waiting.Hash()))
+	assert.False(t, s.isUnconfirmed(notifyId, other))
+
+	conflict := newUnconfirmedTx(op2)
+	s.resolveUnconfirmed([]*types.Transaction{packed, conflict}, now)
+
+	assert.Equal(t, 1, len(s.pending))
+	assert.NotNil(t, s.pending[waiting.Hash()])
+	assert.Equal(t, map[common.Uint256]DropReason{
+		doubleSpent.Hash(): DropDoubleSpent,
+		expired.Hash():     DropExpired,
+	}, l.dropped)
+}
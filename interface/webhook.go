@@ -0,0 +1,261 @@
+package _interface
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+)
+
+const (
+	// WebhookSignatureHeader is the HTTP header of the HMAC-SHA256 signature
+	// of the webhook request body, in the form "sha256=<hex>".
+	WebhookSignatureHeader = "X-SPV-Signature"
+
+	// maxWebhookRequests is the max number of requests a webhook listener
+	// sends at the same time.
+	maxWebhookRequests = 8
+)
+
+// WebhookConfig is the configuration of a WebhookListener.
+type WebhookConfig struct {
+	// URL is the URL to POST the notifications to.
+	URL string
+
+	// Secret is the key to sign the request body with HMAC-SHA256.
+	Secret []byte
+
+	// Address is the address the listener interested.
+	Address string
+
+	// Type is the transaction type the listener interested.
+	Type types.TxType
+
+	// Flags are the notification flags of the listener.
+	Flags uint64
+
+	// Options extends the notification conditions of the listener.
+	Options *ListenerOptions
+
+	// Client is the HTTP client to send requests, a client with the notify
+	// timeout will be used if nil.
+	Client *http.Client
+}
+
+// WebhookPayload is the JSON body of a webhook request.
+type WebhookPayload struct {
+	// NotifyId is the key of the listener's notifications.
+	NotifyId string `json:"notifyId"`
+
+	// TxId is the id of the transaction.
+	TxId string `json:"txId"`
+
+	// TxType is the type of the transaction.
+	TxType uint8 `json:"txType"`
+
+	// Tx is the hex string of the serialized transaction.
+	Tx string `json:"tx"`
+
+	// Proof is the hex string of the serialized merkle proof.
+	Proof string `json:"proof"`
+
+	// Height is the height of the block the transaction packed in.
+	Height uint32 `json:"height"`
+
+	// Confirmations is the confirmations of the transaction, zero if the
+	// transaction is not packed.
+	Confirmations uint32 `json:"confirmations"`
+
+	// Timestamp is the unix time the request was created, receivers can
+	// reject old requests to prevent replays.
+	Timestamp int64 `json:"timestamp"`
+}
+
+// Ensure WebhookListener implement OptionsListener interface.
+var _ OptionsListener = (*WebhookListener)(nil)
+
+// WebhookListener is a TransactionListener that POSTs the notifications to a
+// URL as JSON signed with HMAC.  The receipt is submitted after a 2xx
+// response, notifications failed are sent again by the notify queue.
+type WebhookListener struct {
+	service SPVService
+	cfg     WebhookConfig
+	client  *http.Client
+
+	mtx      sync.Mutex
+	inflight map[common.Uint256]struct{}
+}
+
+// NewWebhookListener creates a webhook listener of the service, it must be
+// registered to the service by RegisterTransactionListener.
+func NewWebhookListener(service SPVService, cfg *WebhookConfig) *WebhookListener {
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: notifyTimeout}
+	}
+	return &WebhookListener{
+		service:  service,
+		cfg:      *cfg,
+		client:   client,
+		inflight: make(map[common.Uint256]struct{}),
+	}
+}
+
+func (l *WebhookListener) Address() string {
+	return l.cfg.Address
+}
+
+func (l *WebhookListener) Type() types.TxType {
+	return l.cfg.Type
+}
+
+func (l *WebhookListener) Flags() uint64 {
+	return l.cfg.Flags
+}
+
+func (l *WebhookListener) Options() *ListenerOptions {
+	return l.cfg.Options
+}
+
+// Notify posts the notification in a new goroutine, so the delivery of other
+// notifications is not blocked by the webhook.  The notification is skipped
+// if the transaction is being posted or too many requests are being sent, it
+// will be sent again by the notify queue.
+func (l *WebhookListener) Notify(notifyId common.Uint256,
+	proof bloom.MerkleProof, tx types.Transaction) {
+
+	txId := tx.Hash()
+	if !l.acquire(txId) {
+		return
+	}
+
+	payload, err := l.payload(notifyId, proof, tx)
+	if err != nil {
+		l.release(txId)
+		log.Errorf("Create webhook payload of transaction %s failed, %s",
+			txId, err)
+		return
+	}
+
+	go func() {
+		defer l.release(txId)
+		if err := l.post(payload); err != nil {
+			log.Warnf("Post transaction %s to webhook %s failed, %s",
+				txId, l.cfg.URL, err)
+			return
+		}
+		err := l.service.SubmitTransactionReceipt(notifyId, txId)
+		if err != nil {
+			log.Errorf("Submit receipt of transaction %s failed, %s",
+				txId, err)
+		}
+	}()
+}
+
+// acquire marks the transaction in flight, it returns false if the
+// transaction is in flight or the requests limit is reached.
+func (l *WebhookListener) acquire(txId common.Uint256) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if _, ok := l.inflight[txId]; ok {
+		return false
+	}
+	if len(l.inflight) >= maxWebhookRequests {
+		return false
+	}
+	l.inflight[txId] = struct{}{}
+	return true
+}
+
+func (l *WebhookListener) release(txId common.Uint256) {
+	l.mtx.Lock()
+	delete(l.inflight, txId)
+	l.mtx.Unlock()
+}
+
+func (l *WebhookListener) payload(notifyId common.Uint256,
+	proof bloom.MerkleProof, tx types.Transaction) ([]byte, error) {
+
+	txBuf := new(bytes.Buffer)
+	if err := tx.Serialize(txBuf); err != nil {
+		return nil, err
+	}
+	proofBuf := new(bytes.Buffer)
+	if err := proof.Serialize(proofBuf); err != nil {
+		return nil, err
+	}
+
+	// Unconfirmed transactions are notified with an empty proof.
+	var confirmations uint32
+	if proof.Height > 0 {
+		best, err := l.service.HeaderStore().GetBest()
+		if err != nil {
+			return nil, err
+		}
+		if best.Height >= proof.Height {
+			confirmations = best.Height - proof.Height
+		}
+	}
+
+	txId := tx.Hash()
+	return json.Marshal(&WebhookPayload{
+		NotifyId:      notifyId.String(),
+		TxId:          txId.String(),
+		TxType:        uint8(tx.TxType),
+		Tx:            common.BytesToHexString(txBuf.Bytes()),
+		Proof:         common.BytesToHexString(proofBuf.Bytes()),
+		Height:        proof.Height,
+		Confirmations: confirmations,
+		Timestamp:     time.Now().Unix(),
+	})
+}
+
+func (l *WebhookListener) post(payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, l.cfg.URL,
+		bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(l.cfg.Secret, payload))
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("response status %s", resp.Status)
+	}
+	return nil
+}
+
+// SignWebhook returns the signature header value of the webhook request
+// body signed by the secret.
+func SignWebhook(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + common.BytesToHexString(mac.Sum(nil))
+}
+
+// VerifyWebhook verifies the signature header value of the webhook request
+// body, it is a help for the receivers implemented in Go.
+func VerifyWebhook(secret, body []byte, signature string) error {
+	if !hmac.Equal([]byte(signature), []byte(SignWebhook(secret, body))) {
+		return errors.New("invalid webhook signature")
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package _interface
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+type webhookHeaders struct {
+	database.Headers
+	best *util.Header
+}
+
+func (h *webhookHeaders) GetBest() (*util.Header, error) {
+	return h.best, nil
+}
+
+type webhookService struct {
+	SPVService
+	headers  *webhookHeaders
+	receipts chan common.Uint256
+}
+
+func (s *webhookService) HeaderStore() database.Headers {
+	return s.headers
+}
+
+func (s *webhookService) SubmitTransactionReceipt(notifyId, txId common.Uint256) error {
+	s.receipts <- txId
+	return nil
+}
+
+// recvPayload receives the payload posted to the webhook server.
+func recvPayload(t *testing.T, payloads <-chan *WebhookPayload) *WebhookPayload {
+	select {
+	case p := <-payloads:
+		return p
+	case <-time.After(time.Second):
+		t.Fatal("payload not posted")
+	}
+	return nil
+}
+
+func TestWebhookListener(t *testing.T) {
+	secret := []byte("secret")
+	payloads := make(chan *WebhookPayload, 2)
+	status := int32(http.StatusInternalServerError)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			err := VerifyWebhook(secret, body, r.Header.Get(WebhookSignatureHeader))
+			if !assert.NoError(t, err) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var p WebhookPayload
+			assert.NoError(t, json.Unmarshal(body, &p))
+			w.WriteHeader(int(atomic.LoadInt32(&status)))
+			payloads <- &p
+		}))
+	defer server.Close()
+
+	service := &webhookService{
+		headers:  &webhookHeaders{best: &util.Header{Height: 105}},
+		receipts: make(chan common.Uint256, 2),
+	}
+	l := NewWebhookListener(service, &WebhookConfig{
+		URL:     server.URL,
+		Secret:  secret,
+		Address: "EQSpUzE4XYJhBSx5j7Tf2cteaKdFdixfVB",
+		Type:    types.TransferAsset,
+	})
+
+	var notifyId common.Uint256
+	rand.Read(notifyId[:])
+	tx := types.Transaction{TxType: types.TransferAsset,
+		Payload: &payload.TransferAsset{}}
+	proof := bloom.MerkleProof{Height: 100, Transactions: 1}
+
+	// No receipt is submitted without a 2xx response.
+	l.Notify(notifyId, proof, tx)
+	p := recvPayload(t, payloads)
+	assert.Equal(t, tx.Hash().String(), p.TxId)
+	assert.Equal(t, notifyId.String(), p.NotifyId)
+	assert.Equal(t, uint32(100), p.Height)
+	assert.Equal(t, uint32(5), p.Confirmations)
+	assert.True(t, p.Timestamp > 0)
+	select {
+	case <-service.receipts:
+		t.Fatal("receipt submitted on failed response")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	l.Notify(notifyId, proof, tx)
+	recvPayload(t, payloads)
+	select {
+	case txId := <-service.receipts:
+		assert.Equal(t, tx.Hash(), txId)
+	case <-time.After(time.Second):
+		t.Fatal("receipt not submitted")
+	}
+
+	// Unconfirmed transactions have no confirmations.
+	body, err := l.payload(notifyId, bloom.MerkleProof{}, tx)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, json.Unmarshal(body, p))
+	assert.Equal(t, uint32(0), p.Height)
+	assert.Equal(t, uint32(0), p.Confirmations)
+
+	// Requests signed by other secrets are rejected.
+	assert.Error(t, VerifyWebhook([]byte("other"), []byte("{}"),
+		SignWebhook(secret, []byte("{}"))))
+}
+
+func TestWebhookInflight(t *testing.T) {
+	l := NewWebhookListener(&webhookService{}, &WebhookConfig{})
+
+	// A transaction is not posted again before the request finished.
+	var txIds [maxWebhookRequests + 1]common.Uint256
+	for i := range txIds {
+		rand.Read(txIds[i][:])
+	}
+	assert.True(t, l.acquire(txIds[0]))
+	assert.False(t, l.acquire(txIds[0]))
+	l.release(txIds[0])
+	assert.True(t, l.acquire(txIds[0]))
+
+	// Requests are limited.
+	for i := 1; i < maxWebhookRequests; i++ {
+		assert.True(t, l.acquire(txIds[i]))
+	}
+	assert.False(t, l.acquire(txIds[maxWebhookRequests]))
+	l.release(txIds[0])
+	assert.True(t, l.acquire(txIds[maxWebhookRequests]))
+}
@@ -27,6 +27,14 @@ type IService interface {
 	// in Config.
 	UpdateFilter()
 
+	// AddFilterElement adds a single element, like a new address or a new
+	// received outpoint, to the transaction filter of connected peers by
+	// filteradd messages instead of reloading the whole filter, the full
+	// filter is reloaded only if the false positive rate becomes too high.
+	// The element must also be included in the filter returned by
+	// GetTxFilter() in Config.
+	AddFilterElement(data []byte)
+
 	// SendTransaction broadcast a transaction message to the peer to peer network.
 	SendTransaction(util.Transaction) error
 }
@@ -52,6 +60,11 @@ type StateNotifier interface {
 	// BlockCommitted will be invoked when a block and transactions within it are
 	// successfully committed into database.
 	BlockCommitted(block *util.Block)
+
+	// ChainReorganized will be invoked when the main chain has been switched to
+	// a new best chain, the reorg contains the common ancestor, the blocks
+	// detached from the previous chain and the blocks attached to the new chain.
+	ChainReorganized(reorg *util.Reorg)
 }
 
 // Config is the configuration settings to the SPV service.
@@ -83,6 +96,11 @@ type Config struct {
 	// GetTxFilter() returns a transaction filter like a bloom filter or others.
 	GetTxFilter func() *msg.TxFilterLoad
 
+	// GetTxFilters is an optional config, it returns the filters a large watch
+	// list is partitioned into, each loaded to a part of the connected peers.
+	// GetTxFilter() is used when it is blank or returns no filter.
+	GetTxFilters func() []*msg.TxFilterLoad
+
 	// StateNotifier is an optional config, if you don't want to receive state changes of transactions
 	// or blocks, just keep it blank.
 	StateNotifier StateNotifier
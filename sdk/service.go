@@ -89,8 +89,10 @@ func newService(cfg *Config) (*service, error) {
 	// Create sync manager instance.
 	syncCfg := sync.NewDefaultConfig(chain, cfg.CandidateFlags, cfg.GetTxFilter)
 	syncCfg.MaxPeers = defaultMaxPeers
+	syncCfg.GetTxFilters = cfg.GetTxFilters
 	if cfg.StateNotifier != nil {
 		syncCfg.TransactionAnnounce = cfg.StateNotifier.TransactionAnnounce
+		syncCfg.ChainReorganized = cfg.StateNotifier.ChainReorganized
 	}
 	syncManager, err := sync.New(syncCfg)
 	if err != nil {
@@ -453,12 +455,25 @@ func (s *service) IsCurrent() bool {
 }
 
 func (s *service) UpdateFilter() {
-	// Broadcast filterload message to connected peers.
-	s.IServer.BroadcastMessage(s.cfg.GetTxFilter())
+	// Filters are loaded to peers by the sync manager, so it knows the peers
+	// to send filteradd messages.
+	s.syncManager.UpdateFilter()
+}
+
+func (s *service) AddFilterElement(data []byte) {
+	s.syncManager.AddFilterElement(data)
 }
 
 func (s *service) Start() {
 	s.start()
+
+	// Notify the chain reorganize completed by recovering the database, it
+	// was interrupted before the listeners were notified.
+	if reorg := s.cfg.ChainStore.RecoveredReorg(); reorg != nil &&
+		s.cfg.StateNotifier != nil {
+		s.cfg.StateNotifier.ChainReorganized(reorg)
+	}
+
 	s.syncManager.Start()
 	s.IServer.Start()
 	log.Info("SPV service started...")
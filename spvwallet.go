@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"sync"
 
 	"github.com/elastos/Elastos.ELA.SPV/bloom"
 	"github.com/elastos/Elastos.ELA.SPV/database"
 	"github.com/elastos/Elastos.ELA.SPV/sdk"
 	"github.com/elastos/Elastos.ELA.SPV/util"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/store"
 	"github.com/elastos/Elastos.ELA.SPV/wallet/store/headers"
 	"github.com/elastos/Elastos.ELA.SPV/wallet/store/sqlite"
 	"github.com/elastos/Elastos.ELA.SPV/wallet/sutil"
@@ -32,156 +34,19 @@ type spvwallet struct {
 	sdk.IService
 	db     sqlite.DataStore
 	filter *sdk.AddrFilter
-}
-
-func (w *spvwallet) putTx(batch sqlite.DataBatch, utx util.Transaction,
-	height uint32) (bool, error) {
-
-	tx := utx.(*sutil.Tx)
-	txId := tx.Hash()
-	hits := 0
-
-	// Check if any UTXOs within this wallet have been spent.
-	for _, input := range tx.Inputs {
-		// Move UTXO to STXO
-		op := util.NewOutPoint(input.Previous.TxID, input.Previous.Index)
-		utxo, _ := w.db.UTXOs().Get(op)
-		// Skip if no match.
-		if utxo == nil {
-			continue
-		}
-
-		err := batch.STXOs().Put(sutil.NewSTXO(utxo, height, txId))
-		if err != nil {
-			return false, nil
-		}
-		hits++
-	}
-
-	// Check if there are any output to this wallet address.
-	for index, output := range tx.Outputs {
-		// Filter address
-		if w.getAddrFilter().ContainAddr(output.ProgramHash) {
-			var lockTime = output.OutputLock
-			if tx.TxType == types.CoinBase {
-				lockTime = height + 100
-			}
-			utxo := sutil.NewUTXO(txId, height, index, output.Value, lockTime, output.ProgramHash)
-			err := batch.UTXOs().Put(utxo)
-			if err != nil {
-				return false, err
-			}
-			hits++
-		}
-	}
-
-	// If no hits, no need to save transaction
-	if hits == 0 {
-		return true, nil
-	}
-
-	// Save transaction
-	err := batch.Txs().Put(util.NewTx(tx, height))
-	if err != nil {
-		return false, err
-	}
-
-	return false, nil
-}
-
-// PutTxs persists the main chain transactions into database and can be
-// queried by GetTxs(height).  Returns the false positive transaction count
-// and error.
-func (w *spvwallet) PutTxs(txs []util.Transaction, height uint32) (uint32, error) {
-	fps := uint32(0)
-	batch := w.db.Batch()
-	defer batch.Rollback()
-	for _, tx := range txs {
-		fp, err := w.putTx(batch, tx, height)
-		if err != nil {
-			return 0, err
-		}
-		if fp {
-			fps++
-		}
-	}
-	if err := batch.Commit(); err != nil {
-		return 0, err
-	}
-	return fps, nil
-}
-
-// PutForkTxs persists the fork chain transactions into database with the
-// fork block hash and can be queried by GetForkTxs(hash).
-func (w *spvwallet) PutForkTxs(txs []util.Transaction, hash *common.Uint256) error {
-	ftxs := make([]*util.Tx, 0, len(txs))
-	for _, tx := range txs {
-		ftxs = append(ftxs, util.NewTx(tx, 0))
-	}
-	return w.db.Txs().PutForkTxs(ftxs, hash)
-}
-
-// HaveTx returns if the transaction already saved in database
-// by it's id.
-func (w *spvwallet) HaveTx(txId *common.Uint256) (bool, error) {
-	tx, err := w.db.Txs().Get(txId)
-	return tx != nil, err
-}
-
-// GetTxs returns all transactions in main chain within the given height.
-func (w *spvwallet) GetTxs(height uint32) ([]util.Transaction, error) {
-	txs, err := w.db.Txs().GetAllFrom(height)
-	if err != nil {
-		return nil, err
-	}
 
-	utxs := make([]util.Transaction, 0, len(txs))
-	for _, tx := range txs {
-		wtx := newTransaction()
-		if err := wtx.Deserialize(bytes.NewReader(tx.RawData)); err != nil {
-			return nil, err
-		}
-		utxs = append(utxs, wtx)
-	}
-	return utxs, nil
-}
-
-// GetForkTxs returns all transactions within the fork block hash.
-func (w *spvwallet) GetForkTxs(hash *common.Uint256) ([]util.Transaction, error) {
-	ftxs, err := w.db.Txs().GetForkTxs(hash)
-	if err != nil {
-		return nil, err
-	}
-
-	txs := make([]util.Transaction, 0, len(ftxs))
-	for _, ftx := range ftxs {
-		tx := newTransaction()
-		if err := tx.Deserialize(bytes.NewReader(ftx.RawData)); err != nil {
-			return nil, err
-		}
-		txs = append(txs, tx)
-	}
-	return txs, nil
-}
-
-// DelTxs remove all transactions in main chain within the given height.
-func (w *spvwallet) DelTxs(height uint32) error {
-	batch := w.db.Batch()
-	defer batch.Rollback()
-	if err := batch.RollbackHeight(height); err != nil {
-		return err
-	}
-	return batch.Commit()
-}
-
-// Clear delete all data in database.
-func (w *spvwallet) Clear() error {
-	return w.db.Clear()
+	// newOps are the outpoints received in committing block, which will be
+	// added to the bloom filter of peers after the block committed.
+	opsMtx sync.Mutex
+	newOps []*util.OutPoint
 }
 
-// Close database.
-func (w *spvwallet) Close() error {
-	return w.db.Close()
+// receivedOps keeps the outpoints received in committing block, they are
+// added to the filter of peers after the block committed.
+func (w *spvwallet) receivedOps(ops []*util.OutPoint) {
+	w.opsMtx.Lock()
+	w.newOps = append(w.newOps, ops...)
+	w.opsMtx.Unlock()
 }
 
 func (w *spvwallet) GetFilter() *msg.TxFilterLoad {
@@ -220,8 +85,8 @@ func (w *spvwallet) GetFilter() *msg.TxFilterLoad {
 func (w *spvwallet) NotifyNewAddress(hash []byte) {
 	// Reload address filter to include new address
 	w.loadAddrFilter()
-	// Broadcast filterload message to connected peers
-	w.UpdateFilter()
+	// Add the new address to the filter of connected peers
+	w.AddFilterElement(hash)
 }
 
 func (w *spvwallet) getAddrFilter() *sdk.AddrFilter {
@@ -267,14 +132,34 @@ func (w *spvwallet) TransactionConfirmed(tx *util.Tx) {
 // BlockCommitted will be invoked when a block and transactions within it are
 // successfully committed into database.
 func (w *spvwallet) BlockCommitted(block *util.Block) {
+	// Add received outpoints to the filter of connected peers, so the
+	// transactions spending them can be matched.
+	w.opsMtx.Lock()
+	ops := w.newOps
+	w.newOps = nil
+	w.opsMtx.Unlock()
+	for _, op := range ops {
+		w.AddFilterElement(op.Bytes())
+	}
+
 	if !w.IsCurrent() {
 		return
 	}
 
-	w.db.State().PutHeight(block.Height)
+	if err := w.db.State().PutHeight(block.Height); err != nil {
+		waltlog.Errorf("Put state height %d error: %v", block.Height, err)
+	}
 	// TODO
 }
 
+// ChainReorganized will be invoked when the main chain has been switched to
+// a new best chain.
+func (w *spvwallet) ChainReorganized(reorg *util.Reorg) {
+	waltlog.Infof("Chain reorganized at height %d, detached %d blocks,"+
+		" attached %d blocks", reorg.CommonAncestor.Height,
+		len(reorg.Detached), len(reorg.Attached))
+}
+
 // Functions for RPC service.
 func (w *spvwallet) notifyNewAddress(params http.Params) (interface{}, error) {
 	addrStr, ok := params.String("addr")
@@ -292,8 +177,8 @@ func (w *spvwallet) notifyNewAddress(params http.Params) (interface{}, error) {
 	// Reload address filter to include new address
 	w.loadAddrFilter()
 
-	// Broadcast filterload message to connected peers
-	w.UpdateFilter()
+	// Add the new address to the filter of connected peers
+	w.AddFilterElement(address.Bytes())
 
 	return nil, nil
 }
@@ -330,8 +215,17 @@ func NewWallet(dataDir string) (*spvwallet, error) {
 		return nil, err
 	}
 
+	journal, err := database.NewJournal(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
 	w := spvwallet{db: db}
-	chainStore := database.NewChainDB(headers, &w)
+	txs := store.NewTxsDB(db, w.getAddrFilter, w.receivedOps)
+	chainStore, err := database.NewChainDB(headers, txs, journal, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	var params *config.Params
 	switch cfg.Network {
@@ -18,6 +18,13 @@ type Config struct {
 
 	GetTxFilter         func() *msg.TxFilterLoad
 	TransactionAnnounce func(tx util.Transaction)
+	ChainReorganized    func(reorg *util.Reorg)
+
+	// GetTxFilters is optional, it returns the filters the watch list is
+	// partitioned into when it is too large for one filter.  Each filter is
+	// loaded to a part of the sync candidate peers, and a block is
+	// committed after it's merkle blocks of all partitions are received.
+	GetTxFilters func() []*msg.TxFilterLoad
 }
 
 func NewDefaultConfig(chain *blockchain.BlockChain, candidateFlags []uint64,
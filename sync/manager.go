@@ -4,6 +4,7 @@ import (
 	"sync/atomic"
 
 	"github.com/elastos/Elastos.ELA.SPV/blockchain"
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
 	"github.com/elastos/Elastos.ELA.SPV/fprate"
 	"github.com/elastos/Elastos.ELA.SPV/peer"
 	"github.com/elastos/Elastos.ELA.SPV/util"
@@ -24,6 +25,16 @@ const (
 	// maxBadBlockRate is the maximum bad blocks rate of received blocks.
 	maxBadBlockRate float64 = 0.001
 
+	// maxFilterMismatches is the maximum times a peer sends transactions
+	// not matching the loaded filter within filterMismatchWindow received
+	// blocks, a mismatch may be caused by a filter reloaded while the block
+	// is in flight, so a few are tolerated.
+	maxFilterMismatches = 3
+
+	// filterMismatchWindow is the number of received blocks the filter
+	// mismatches of a peer are counted in.
+	filterMismatchWindow = 1000
+
 	// maxRequestedBlocks is the maximum number of requested block
 	// hashes to store in memory.
 	maxRequestedBlocks = msg.MaxInvPerMsg
@@ -69,6 +80,16 @@ type txMsg struct {
 	reply chan struct{}
 }
 
+// filterAddMsg is a message type to be sent across the message channel for
+// adding an element to the bloom filter of connected peers.
+type filterAddMsg struct {
+	data []byte
+}
+
+// updateFilterMsg is a message type to be sent across the message channel for
+// reloading the filters of connected peers.
+type updateFilterMsg struct{}
+
 // getSyncPeerMsg is a message type to be sent across the message channel for
 // retrieving the current sync peer.
 type getSyncPeerMsg struct {
@@ -100,6 +121,26 @@ type peerSyncState struct {
 	receivedBlocks  uint32
 	badBlocks       uint32
 	fpRate          *fprate.FpRate
+
+	// filterLoaded indicates a filter has been loaded to the peer, so it is
+	// updated by filteradd messages.
+	filterLoaded bool
+
+	// filter is a copy of the bloom filter loaded to the peer, it is updated
+	// like the peer's filter by received transactions and filteradd
+	// messages, nil if the filter can not be updated incrementally.
+	filter *bloom.Filter
+
+	// filterMismatches is the number of blocks with transactions not
+	// matching the filter in the current filterMismatchWindow.
+	filterMismatches uint32
+
+	// partition is the index of the filter partition loaded to the peer.
+	partition int
+
+	// staleBlocks are the blocks requested before the peer is loaded with
+	// the filter of another partition, they are dropped when received.
+	staleBlocks map[common.Uint256]struct{}
 }
 
 func (s *peerSyncState) badBlockRate() float64 {
@@ -124,6 +165,12 @@ type SyncManager struct {
 	txMemPool       map[common.Uint256]struct{}
 	syncPeer        *peer.Peer
 	peerStates      map[*peer.Peer]*peerSyncState
+
+	// partitions is the number of filters the watch list is partitioned
+	// into, and partials are the blocks waiting for the merkle blocks of
+	// other partitions.
+	partitions int
+	partials   map[common.Uint256]*partialBlock
 }
 
 // current returns true if we believe we are synced with our peers, false if we
@@ -193,6 +240,7 @@ func (sm *SyncManager) syncWith(peer *peer.Peer) {
 	// Clear the requestedBlocks if the sync peer changes, otherwise we
 	// may ignore blocks we need that the last sync peer failed to send.
 	sm.requestedBlocks = make(map[common.Uint256]struct{})
+	sm.partials = make(map[common.Uint256]*partialBlock)
 
 	log.Infof("Syncing to block height %d from peer %v", peer.Height(),
 		peer.Addr())
@@ -227,9 +275,101 @@ func (sm *SyncManager) getSyncCandidates() []*peer.Peer {
 	return candidates
 }
 
-// pushBloomFilter update and send the bloom filter to the given peer.
+// pushBloomFilter update and send the bloom filter to the given peer, the
+// sync candidate peers are partitioned again if the number of partitions
+// changed, and other peers are loaded with the unpartitioned filter.
 func (sm *SyncManager) pushBloomFilter(p *peer.Peer) {
-	p.QueueMessage(sm.cfg.GetTxFilter(), nil)
+	state, exists := sm.peerStates[p]
+	if !exists {
+		p.QueueMessage(sm.cfg.GetTxFilter(), nil)
+		return
+	}
+	if !state.syncCandidate {
+		sm.loadFilter(p, state, sm.cfg.GetTxFilter())
+		return
+	}
+
+	filters := sm.partitionFilters()
+	if len(filters) != sm.partitions {
+		sm.repartition(filters)
+		return
+	}
+	sm.loadFilter(p, state, filters[state.partition])
+}
+
+// matchFilter matches and updates the filter with the block transactions in
+// order, returns false if any transaction is not matched.
+func (sm *SyncManager) matchFilter(bf *bloom.Filter, block *util.Block) bool {
+	matched := true
+	for _, tx := range block.Transactions {
+		if !bf.MatchTxAndUpdate(tx) {
+			matched = false
+		}
+	}
+	return matched
+}
+
+// handleFilterAddMsg adds the element to the bloom filter of peers with a
+// loaded filter by filteradd message, the full filter is reloaded instead if
+// the measured or estimated false positive rate is too high.  When the watch
+// list is partitioned, the element is only added to the sync candidate peers
+// of the partition it belongs to, and the peers are partitioned again if the
+// number of partitions changed.
+func (sm *SyncManager) handleFilterAddMsg(fmsg *filterAddMsg) {
+	var owners []bool
+	repartitioned := false
+	if sm.partitioned() {
+		filters := sm.partitionFilters()
+		if len(filters) != sm.partitions {
+			sm.repartition(filters)
+			repartitioned = true
+		} else {
+			owners = make([]bool, len(filters))
+			for i, txFilter := range filters {
+				owners[i] = filterContains(txFilter, fmsg.data)
+			}
+		}
+	}
+
+	for peer, state := range sm.peerStates {
+		if !state.filterLoaded {
+			continue
+		}
+
+		// The sync candidate peers partitioned again are loaded with the
+		// element already.
+		if state.syncCandidate && (repartitioned ||
+			owners != nil && !owners[state.partition]) {
+			continue
+		}
+
+		if state.filter == nil || state.fpRate.Rate() >
+			fprate.DefaultFalsePositiveRate {
+			sm.pushBloomFilter(peer)
+			state.fpRate.Reset()
+			continue
+		}
+
+		state.filter.Add(fmsg.data)
+		if state.filter.EstimatedFalsePositiveRate() >
+			fprate.DefaultFalsePositiveRate {
+			sm.pushBloomFilter(peer)
+			state.fpRate.Reset()
+			continue
+		}
+
+		peer.QueueMessage(&msg.FilterAdd{Data: fmsg.data}, nil)
+	}
+}
+
+// handleUpdateFilterMsg reloads the filters of connected peers, sync candidate
+// peers are loaded with the filter of their partition and other peers with
+// the unpartitioned filter.
+func (sm *SyncManager) handleUpdateFilterMsg() {
+	for peer, state := range sm.peerStates {
+		sm.pushBloomFilter(peer)
+		state.fpRate.Reset()
+	}
 }
 
 // handleNewPeerMsg deals with new peers that have signalled they may
@@ -250,12 +390,19 @@ func (sm *SyncManager) handleNewPeerMsg(peer *peer.Peer) {
 		requestedTxns:   make(map[common.Uint256]struct{}),
 		requestedBlocks: make(map[common.Uint256]struct{}),
 		fpRate:          fprate.NewFpRate(),
+		partition:       sm.leastLoadedPartition(),
+		staleBlocks:     make(map[common.Uint256]struct{}),
 	}
 
 	if isSyncCandidate {
 		// Update bloom filter for the candidate peer.
 		sm.pushBloomFilter(peer)
 
+		// Request the partial blocks waiting for a peer of the partition.
+		if sm.partitioned() {
+			sm.reassignPartitions(nil)
+		}
+
 		// Start syncing by choosing the best candidate if needed.
 		if sm.syncPeer == nil {
 			sm.startSync()
@@ -291,6 +438,18 @@ func (sm *SyncManager) handleDonePeerMsg(peer *peer.Peer) {
 		delete(sm.requestedBlocks, blockHash)
 	}
 
+	// Request the partial blocks requested from the peer from other peers
+	// of the same partition, or partition again if no peer is left for a
+	// partition.
+	if sm.partitioned() {
+		if filters := sm.partitionFilters(); len(filters) != sm.partitions ||
+			sm.emptyPartition() {
+			sm.repartition(filters)
+		} else {
+			sm.reassignPartitions(peer)
+		}
+	}
+
 	// Attempt to find a new peer to sync from if the quitting peer is the
 	// sync peer.
 	if sm.syncPeer == peer {
@@ -334,9 +493,24 @@ func (sm *SyncManager) handleTxMsg(tmsg *txMsg) {
 // in response to inv packets both during initial sync and after.
 func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	peer := bmsg.peer
+	block := bmsg.block
+	blockHash := block.Hash()
 
-	// We don't need to process blocks when we're syncing. They wont connect anyway
-	if peer != sm.syncPeer && !sm.current() {
+	// Drop the blocks filtered by the filters loaded before partitioned
+	// again, they have been requested again.
+	if state, ok := sm.peerStates[peer]; ok {
+		if _, ok := state.staleBlocks[blockHash]; ok {
+			delete(state.staleBlocks, blockHash)
+			log.Debugf("Drop block %s filtered by the previous filter",
+				blockHash)
+			return
+		}
+	}
+
+	// We don't need to process blocks when we're syncing. They wont connect
+	// anyway, except the partitions of blocks requested from the sync peer.
+	_, partial := sm.partials[blockHash]
+	if peer != sm.syncPeer && !sm.current() && !partial {
 		log.Warnf("Received block from %s when we aren't current", peer)
 		return
 	}
@@ -348,8 +522,6 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	}
 
 	// If we didn't ask for this block then the peer is misbehaving.
-	block := bmsg.block
-	blockHash := block.Hash()
 	if _, exists = state.requestedBlocks[blockHash]; !exists {
 		log.Warnf("Received unrequested block from peer %s", peer)
 		peer.Disconnect()
@@ -363,6 +535,42 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	delete(state.requestedBlocks, blockHash)
 	delete(sm.requestedBlocks, blockHash)
 
+	// Apply the received transactions to the copy of peer's filter, so it
+	// has the outpoints added by the peer.  A transaction not matched by the
+	// copy means the peer's filter differs from what we loaded, the peer may
+	// omit transactions that match our filter too.  Only the extra
+	// transactions are detected here, a peer omitting matched transactions
+	// with the loaded filter is not.
+	if state.receivedBlocks%filterMismatchWindow == 0 {
+		state.filterMismatches = 0
+	}
+	if state.filter != nil && !sm.matchFilter(state.filter, block) {
+		log.Warnf("Peer %s sent transactions not matching the loaded"+
+			" filter, reloading filter", peer)
+		state.filterMismatches++
+		if state.filterMismatches > maxFilterMismatches {
+			log.Warnf("Disconnecting from peer %s because it's filter"+
+				" differs from the loaded filter", peer)
+			peer.Disconnect()
+			return
+		}
+		sm.pushBloomFilter(peer)
+	}
+
+	// Wait for the blocks of all partitions and continue with the merged
+	// block as received from the peer it was requested from.
+	if sm.partitioned() {
+		if !partial {
+			log.Debugf("Drop block %s of outdated partitions", blockHash)
+			return
+		}
+		merged, origin, ok := sm.mergePartition(peer, state, block)
+		if !ok {
+			return
+		}
+		block, peer, state = merged, origin, sm.peerStates[origin]
+	}
+
 	newBlock, reorg, newHeight, fps, err := sm.cfg.Chain.CommitBlock(block)
 	// If this is an orphan block which doesn't connect to the chain, it's possible
 	// that we might be synced on the longest chain, but not the most-work chain like
@@ -403,15 +611,21 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		return
 	}
 
-	// Check false positive rate.
+	// Check false positive rate, a merged block has the false positives of
+	// every partition's filter, so the limits are scaled by the number of
+	// partitions.
+	maxFpRate := fprate.DefaultFalsePositiveRate
+	if sm.partitioned() {
+		maxFpRate *= float64(sm.partitions)
+	}
 	fpRate := state.fpRate.Update(block, fps)
-	if fpRate > fprate.DefaultFalsePositiveRate*10 {
+	if fpRate > maxFpRate*10 {
 		log.Warnf("bloom filter false positive rate %f too high,"+
 			" disconnecting...", fpRate)
 		peer.Disconnect()
 		return
 	}
-	if newHeight+500 < peer.Height() && fpRate > fprate.DefaultFalsePositiveRate {
+	if newHeight+500 < peer.Height() && fpRate > maxFpRate {
 		sm.pushBloomFilter(peer)
 		state.fpRate.Reset()
 	}
@@ -419,11 +633,18 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	log.Infof("Received block %s at height %d", blockHash.String(), newHeight)
 
 	// Check reorg
-	if reorg && sm.current() {
-		// Clear request state for new sync
-		state.requestQueue = []*msg.InvVect{}
-		state.requestedBlocks = make(map[common.Uint256]struct{})
-		sm.requestedBlocks = make(map[common.Uint256]struct{})
+	if reorg != nil {
+		if sm.current() {
+			// Clear request state for new sync
+			state.requestQueue = []*msg.InvVect{}
+			state.requestedBlocks = make(map[common.Uint256]struct{})
+			sm.requestedBlocks = make(map[common.Uint256]struct{})
+			sm.partials = make(map[common.Uint256]*partialBlock)
+		}
+
+		if sm.cfg.ChainReorganized != nil {
+			sm.cfg.ChainReorganized(reorg)
+		}
 	}
 
 	// Clear mempool
@@ -530,6 +751,7 @@ func (sm *SyncManager) requestQueuedInv(peer *peer.Peer, state *peerSyncState) {
 	// Request as much as possible at once.  Anything that won't fit into
 	// the request will be requested on the next inv message.
 	numRequested := 0
+	var blockHashes []common.Uint256
 	gdmsg := msg.NewGetData()
 	requestQueue := state.requestQueue
 	for len(requestQueue) != 0 {
@@ -548,6 +770,7 @@ func (sm *SyncManager) requestQueuedInv(peer *peer.Peer, state *peerSyncState) {
 
 				iv.Type = msg.InvTypeFilteredBlock
 				gdmsg.AddInvVect(iv)
+				blockHashes = append(blockHashes, iv.Hash)
 				numRequested++
 			}
 
@@ -573,6 +796,11 @@ func (sm *SyncManager) requestQueuedInv(peer *peer.Peer, state *peerSyncState) {
 		log.Debugf("QueueMessage getdata size %d", len(gdmsg.InvList))
 		peer.QueueMessage(gdmsg, nil)
 	}
+
+	// Request the blocks from peers of other partitions too.
+	if sm.partitioned() && len(blockHashes) > 0 {
+		sm.requestPartitions(peer, state, blockHashes)
+	}
 }
 
 // limitMap is a helper function for maps that require a maximum limit by
@@ -622,6 +850,12 @@ out:
 			case *donePeerMsg:
 				sm.handleDonePeerMsg(msg.peer)
 
+			case *filterAddMsg:
+				sm.handleFilterAddMsg(msg)
+
+			case updateFilterMsg:
+				sm.handleUpdateFilterMsg()
+
 			case getSyncPeerMsg:
 				var peerID uint64
 				if sm.syncPeer != nil {
@@ -712,6 +946,29 @@ func (sm *SyncManager) DonePeer(peer *peer.Peer) {
 	sm.msgChan <- &donePeerMsg{peer: peer}
 }
 
+// AddFilterElement adds the element to the bloom filter of connected peers,
+// the element must also be included in the filter returned by GetTxFilter.
+func (sm *SyncManager) AddFilterElement(data []byte) {
+	// Ignore if we are shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		return
+	}
+
+	sm.msgChan <- &filterAddMsg{data: data}
+}
+
+// UpdateFilter reloads the filters of connected peers, sync candidate peers
+// are loaded with the filter of their partition when the watch list is
+// partitioned.
+func (sm *SyncManager) UpdateFilter() {
+	// Ignore if we are shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		return
+	}
+
+	sm.msgChan <- updateFilterMsg{}
+}
+
 // Start begins the core block handler which processes block and inv messages.
 func (sm *SyncManager) Start() {
 	// Already started?
@@ -770,6 +1027,7 @@ func New(cfg *Config) (*SyncManager, error) {
 		requestedTxns:   make(map[common.Uint256]struct{}),
 		requestedBlocks: make(map[common.Uint256]struct{}),
 		peerStates:      make(map[*peer.Peer]*peerSyncState),
+		partials:        make(map[common.Uint256]*partialBlock),
 		msgChan:         make(chan interface{}, cfg.MaxPeers*3),
 		quit:            make(chan struct{}),
 	}
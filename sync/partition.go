@@ -0,0 +1,327 @@
+package sync
+
+import (
+	"bytes"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/peer"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/elanet/filter"
+	"github.com/elastos/Elastos.ELA/p2p/msg"
+)
+
+// partialBlock is a block requested from peers of every filter partition,
+// it is committed after the merkle blocks of all partitions received.
+type partialBlock struct {
+	// origin is the peer the block was requested from by inventory.
+	origin *peer.Peer
+
+	// peers are the peers the block is requested from by partition.
+	peers map[int]*peer.Peer
+
+	// parts are the received blocks by partition.
+	parts map[int]*util.Block
+}
+
+// partitioned returns if the watch list is partitioned into multiple filters
+// loaded to different peers.
+func (sm *SyncManager) partitioned() bool {
+	return sm.partitions > 1
+}
+
+// partitionFilters returns the filters to load to the sync candidate peers.
+// The unpartitioned filter is returned if there are fewer sync candidate
+// peers than partitions, so no block waits for a partition without a peer.
+func (sm *SyncManager) partitionFilters() []*msg.TxFilterLoad {
+	var filters []*msg.TxFilterLoad
+	if sm.cfg.GetTxFilters != nil {
+		filters = sm.cfg.GetTxFilters()
+	}
+	if len(filters) > 1 && len(filters) > len(sm.getSyncCandidates()) {
+		log.Debugf("Not enough peers for %d filter partitions, using the"+
+			" unpartitioned filter", len(filters))
+		filters = nil
+	}
+	if len(filters) == 0 {
+		filters = []*msg.TxFilterLoad{sm.cfg.GetTxFilter()}
+	}
+	return filters
+}
+
+// emptyPartition returns if any partition has no sync candidate peer.
+func (sm *SyncManager) emptyPartition() bool {
+	for i := 0; i < sm.partitions; i++ {
+		if sm.partitionPeer(i, nil) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// repartition assigns the sync candidate peers to the partitions of the
+// filters and loads the filters to them.  The blocks in flight are filtered
+// by the previous filters, so they are dropped when received and requested
+// again after the new filters loaded.
+func (sm *SyncManager) repartition(filters []*msg.TxFilterLoad) {
+	if len(filters) > 1 {
+		log.Infof("Watch list partitioned into %d filters", len(filters))
+	}
+	sm.partitions = len(filters)
+
+	requests := make(map[*peer.Peer][]common.Uint256)
+	i := 0
+	for p, state := range sm.peerStates {
+		if !state.syncCandidate {
+			continue
+		}
+		for hash := range state.requestedBlocks {
+			state.staleBlocks[hash] = struct{}{}
+			// The blocks requested for other peers by partition are
+			// requested again by their origin peers.
+			if partial, ok := sm.partials[hash]; ok && partial.origin != p {
+				continue
+			}
+			requests[p] = append(requests[p], hash)
+		}
+		state.requestedBlocks = make(map[common.Uint256]struct{})
+
+		state.partition = i % len(filters)
+		i++
+		sm.loadFilter(p, state, filters[state.partition])
+	}
+	sm.partials = make(map[common.Uint256]*partialBlock)
+
+	for p, hashes := range requests {
+		state := sm.peerStates[p]
+		gdmsg := msg.NewGetData()
+		for _, hash := range hashes {
+			hash := hash
+			state.requestedBlocks[hash] = struct{}{}
+			gdmsg.AddInvVect(msg.NewInvVect(msg.InvTypeFilteredBlock, &hash))
+		}
+		p.QueueMessage(gdmsg, nil)
+
+		if sm.partitioned() {
+			sm.requestPartitions(p, state, hashes)
+		}
+	}
+}
+
+// loadFilter sends the filter to the peer and keeps a copy of the bloom filter
+// to update it incrementally.
+func (sm *SyncManager) loadFilter(p *peer.Peer, state *peerSyncState,
+	txFilter *msg.TxFilterLoad) {
+	p.QueueMessage(txFilter, nil)
+
+	state.filterLoaded = true
+	state.filter = nil
+	if txFilter.Type == filter.FTBloom {
+		var filterLoad msg.FilterLoad
+		err := filterLoad.Deserialize(bytes.NewReader(txFilter.Data))
+		if err == nil {
+			state.filter = bloom.LoadFilter(&filterLoad)
+			state.filter.SetUpdateType(bloom.UpdateAll)
+		}
+	}
+}
+
+// filterContains returns if the element may be in the filter, filters other
+// than bloom filters are assumed to contain it.
+func filterContains(txFilter *msg.TxFilterLoad, data []byte) bool {
+	if txFilter.Type != filter.FTBloom {
+		return true
+	}
+	var filterLoad msg.FilterLoad
+	err := filterLoad.Deserialize(bytes.NewReader(txFilter.Data))
+	if err != nil {
+		return true
+	}
+	return bloom.LoadFilter(&filterLoad).Matches(data)
+}
+
+// leastLoadedPartition returns the partition assigned to the fewest sync
+// candidate peers.
+func (sm *SyncManager) leastLoadedPartition() int {
+	if !sm.partitioned() {
+		return 0
+	}
+
+	loads := make([]int, sm.partitions)
+	for _, state := range sm.peerStates {
+		if state.syncCandidate && state.partition < sm.partitions {
+			loads[state.partition]++
+		}
+	}
+	least := 0
+	for i, load := range loads {
+		if load < loads[least] {
+			least = i
+		}
+	}
+	return least
+}
+
+// partitionPeer returns a sync candidate peer of the partition, or nil if no
+// peer is assigned to the partition.
+func (sm *SyncManager) partitionPeer(partition int, exclude *peer.Peer) *peer.Peer {
+	for p, state := range sm.peerStates {
+		if p != exclude && state.syncCandidate && state.partition == partition {
+			return p
+		}
+	}
+	return nil
+}
+
+// requestPartitions requests the blocks requested from the origin peer from
+// peers of the other partitions.
+func (sm *SyncManager) requestPartitions(origin *peer.Peer,
+	state *peerSyncState, hashes []common.Uint256) {
+
+	getData := make(map[*peer.Peer]*msg.GetData)
+	for _, hash := range hashes {
+		partial := &partialBlock{
+			origin: origin,
+			peers:  map[int]*peer.Peer{state.partition: origin},
+			parts:  make(map[int]*util.Block),
+		}
+		sm.partials[hash] = partial
+
+		for i := 0; i < sm.partitions; i++ {
+			if i == state.partition {
+				continue
+			}
+			p := sm.partitionPeer(i, nil)
+			if p == nil {
+				log.Warnf("No peer for filter partition %d, block %s"+
+					" waits for a peer", i, hash)
+				continue
+			}
+			partial.peers[i] = p
+			sm.peerStates[p].requestedBlocks[hash] = struct{}{}
+
+			gdmsg, ok := getData[p]
+			if !ok {
+				gdmsg = msg.NewGetData()
+				getData[p] = gdmsg
+			}
+			gdmsg.AddInvVect(msg.NewInvVect(msg.InvTypeFilteredBlock, &hash))
+		}
+	}
+
+	for p, gdmsg := range getData {
+		p.QueueMessage(gdmsg, nil)
+	}
+}
+
+// reassignPartitions requests the partial blocks requested from the
+// disconnected peer from other peers of the same partition.
+func (sm *SyncManager) reassignPartitions(done *peer.Peer) {
+	getData := make(map[*peer.Peer]*msg.GetData)
+	for hash, partial := range sm.partials {
+		for i := 0; i < sm.partitions; i++ {
+			if _, ok := partial.parts[i]; ok {
+				continue
+			}
+			if p, ok := partial.peers[i]; ok && p != done {
+				continue
+			}
+
+			delete(partial.peers, i)
+			p := sm.partitionPeer(i, done)
+			if p == nil {
+				continue
+			}
+			partial.peers[i] = p
+			sm.peerStates[p].requestedBlocks[hash] = struct{}{}
+
+			gdmsg, ok := getData[p]
+			if !ok {
+				gdmsg = msg.NewGetData()
+				getData[p] = gdmsg
+			}
+			hash := hash
+			gdmsg.AddInvVect(msg.NewInvVect(msg.InvTypeFilteredBlock, &hash))
+		}
+	}
+
+	for p, gdmsg := range getData {
+		p.QueueMessage(gdmsg, nil)
+	}
+}
+
+// mergePartition saves the block received from a partition peer, returns the
+// merged block and the origin peer when blocks of all partitions received,
+// or false if more partitions are pending or the block was not requested
+// from the peer for it's partition.
+func (sm *SyncManager) mergePartition(p *peer.Peer, state *peerSyncState,
+	block *util.Block) (*util.Block, *peer.Peer, bool) {
+
+	hash := block.Hash()
+	partial, ok := sm.partials[hash]
+	if !ok || partial.peers[state.partition] != p {
+		return nil, nil, false
+	}
+
+	partial.parts[state.partition] = block
+	if len(partial.parts) < sm.partitions {
+		return nil, nil, false
+	}
+	delete(sm.partials, hash)
+
+	merged, err := mergeBlocks(partial.parts)
+	if err != nil {
+		log.Warnf("Merge partitions of block %s failed, %s", hash, err)
+		return nil, nil, false
+	}
+
+	origin := partial.origin
+	if _, ok := sm.peerStates[origin]; !ok {
+		origin = p
+	}
+	return merged, origin, true
+}
+
+// mergeBlocks merges the blocks filtered by different partitions, the
+// transactions are ordered as in the block.
+func mergeBlocks(parts map[int]*util.Block) (*util.Block, error) {
+	mblocks := make([]*msg.MerkleBlock, 0, len(parts))
+	txs := make(map[common.Uint256]util.Transaction)
+	for _, part := range parts {
+		mblocks = append(mblocks, &msg.MerkleBlock{
+			Header:       part.BlockHeader,
+			Transactions: part.NumTxs,
+			Hashes:       part.Hashes,
+			Flags:        part.Flags,
+		})
+		for _, tx := range part.Transactions {
+			txs[tx.Hash()] = tx
+		}
+	}
+
+	merged, err := bloom.MergeMerkleBlocks(mblocks)
+	if err != nil {
+		return nil, err
+	}
+	txIds, err := bloom.CheckMerkleBlock(*merged)
+	if err != nil {
+		return nil, err
+	}
+
+	block := &util.Block{
+		Header: util.Header{
+			BlockHeader: merged.Header.(util.BlockHeader),
+			NumTxs:      merged.Transactions,
+			Hashes:      merged.Hashes,
+			Flags:       merged.Flags,
+		},
+		Transactions: make([]util.Transaction, 0, len(txIds)),
+	}
+	for _, txId := range txIds {
+		if tx, ok := txs[*txId]; ok {
+			block.Transactions = append(block.Transactions, tx)
+		}
+	}
+	return block, nil
+}
@@ -0,0 +1,239 @@
+package sync
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/fprate"
+	"github.com/elastos/Elastos.ELA.SPV/interface/iutil"
+	"github.com/elastos/Elastos.ELA.SPV/peer"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+	"github.com/elastos/Elastos.ELA/elanet/filter"
+	"github.com/elastos/Elastos.ELA/p2p/msg"
+	p2ppeer "github.com/elastos/Elastos.ELA/p2p/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestFilter creates a bloom filter of the addresses, it is sized for more
+// elements so the few bits of a tiny filter do not match other transactions.
+func newTestFilter(addrs ...common.Uint168) *bloom.Filter {
+	f := bloom.NewFilter(uint32(len(addrs))+100, 0,
+		fprate.ReducedFalsePositiveRate)
+	for _, addr := range addrs {
+		f.Add(addr.Bytes())
+	}
+	return f
+}
+
+// newTestManager creates a sync manager of the watch list partitioned into a
+// filter per address, the returned partitions can be updated by tests.
+func newTestManager(addrs ...common.Uint168) (*SyncManager, [][]common.Uint168) {
+	parts := make([][]common.Uint168, 0, len(addrs))
+	for _, addr := range addrs {
+		parts = append(parts, []common.Uint168{addr})
+	}
+	sm, _ := New(&Config{
+		GetTxFilter: func() *msg.TxFilterLoad {
+			var all []common.Uint168
+			for _, part := range parts {
+				all = append(all, part...)
+			}
+			return newTestFilter(all...).ToTxFilterMsg(filter.FTBloom)
+		},
+		GetTxFilters: func() []*msg.TxFilterLoad {
+			loads := make([]*msg.TxFilterLoad, 0, len(parts))
+			for _, part := range parts {
+				loads = append(loads,
+					newTestFilter(part...).ToTxFilterMsg(filter.FTBloom))
+			}
+			return loads
+		},
+	})
+	return sm, parts
+}
+
+// addTestPeer adds a disconnected sync candidate peer to the sync manager.
+func addTestPeer(sm *SyncManager) (*peer.Peer, *peerSyncState) {
+	p := peer.NewPeer(p2ppeer.NewInboundPeer(&p2ppeer.Config{}),
+		&peer.Config{})
+	state := &peerSyncState{
+		syncCandidate:   true,
+		requestedTxns:   make(map[common.Uint256]struct{}),
+		requestedBlocks: make(map[common.Uint256]struct{}),
+		fpRate:          fprate.NewFpRate(),
+		partition:       sm.leastLoadedPartition(),
+		staleBlocks:     make(map[common.Uint256]struct{}),
+	}
+	sm.peerStates[p] = state
+	return p, state
+}
+
+func TestMergeBlocks(t *testing.T) {
+	txs := make([]util.Transaction, 0, 6)
+	hashes := make([]common.Uint256, 0, 6)
+	addrs := make([]common.Uint168, 0, 6)
+	for i := 0; i < 6; i++ {
+		var addr common.Uint168
+		rand.Read(addr[:])
+		tx := iutil.NewTx(&types.Transaction{
+			TxType:  types.TransferAsset,
+			Payload: &payload.TransferAsset{},
+			Outputs: []*types.Output{{ProgramHash: addr}},
+		})
+		txs = append(txs, tx)
+		hashes = append(hashes, tx.Hash())
+		addrs = append(addrs, addr)
+	}
+	root, err := crypto.ComputeRoot(hashes)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	block := &util.Block{
+		Header: util.Header{
+			BlockHeader: iutil.NewHeader(&types.Header{MerkleRoot: root}),
+		},
+		Transactions: txs,
+	}
+
+	// newPart returns the block filtered by the filter.
+	newPart := func(f *bloom.Filter) *util.Block {
+		mb, matches := bloom.NewMerkleBlock(block, f)
+		part := &util.Block{
+			Header: util.Header{
+				BlockHeader: mb.Header.(util.BlockHeader),
+				NumTxs:      mb.Transactions,
+				Hashes:      mb.Hashes,
+				Flags:       mb.Flags,
+			},
+		}
+		for _, i := range matches {
+			part.Transactions = append(part.Transactions, txs[i])
+		}
+		return part
+	}
+
+	parts := map[int]*util.Block{
+		0: newPart(newTestFilter(addrs[4], addrs[1])),
+		1: newPart(newTestFilter(addrs[2])),
+	}
+	merged, err := mergeBlocks(parts)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, uint32(6), merged.NumTxs)
+	if !assert.Len(t, merged.Transactions, 3) {
+		t.FailNow()
+	}
+	for i, index := range []int{1, 2, 4} {
+		assert.Equal(t, hashes[index], merged.Transactions[i].Hash())
+	}
+	txIds, err := bloom.CheckMerkleBlock(msg.MerkleBlock{
+		Header:       merged.BlockHeader,
+		Transactions: merged.NumTxs,
+		Hashes:       merged.Hashes,
+		Flags:        merged.Flags,
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Len(t, txIds, 3)
+
+	// Partitions of different blocks can not be merged.
+	other := newPart(newTestFilter(addrs[0]))
+	other.BlockHeader = iutil.NewHeader(&types.Header{MerkleRoot: hashes[0]})
+	parts[1] = other
+	_, err = mergeBlocks(parts)
+	assert.Error(t, err)
+}
+
+func TestRepartition(t *testing.T) {
+	var addr1, addr2 common.Uint168
+	rand.Read(addr1[:])
+	rand.Read(addr2[:])
+	sm, _ := newTestManager(addr1, addr2)
+	header := iutil.NewHeader(&types.Header{Height: 1})
+	hash := header.Hash()
+
+	// The unpartitioned filter is used without enough peers for every
+	// partition.
+	p1, s1 := addTestPeer(sm)
+	sm.pushBloomFilter(p1)
+	assert.Equal(t, 1, sm.partitions)
+	assert.False(t, sm.partitioned())
+	assert.True(t, s1.filterLoaded)
+	assert.NotNil(t, s1.filter)
+	s1.requestedBlocks[hash] = struct{}{}
+
+	// The watch list is partitioned when a peer is available for every
+	// partition, the block in flight is requested again and from the peer
+	// of the other partition.
+	p2, s2 := addTestPeer(sm)
+	sm.pushBloomFilter(p2)
+	assert.Equal(t, 2, sm.partitions)
+	assert.NotEqual(t, s1.partition, s2.partition)
+	assert.Contains(t, s1.staleBlocks, hash)
+	assert.Contains(t, s1.requestedBlocks, hash)
+	assert.Contains(t, s2.requestedBlocks, hash)
+	partial, ok := sm.partials[hash]
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	assert.Equal(t, p1, partial.origin)
+	assert.Equal(t, p2, partial.peers[s2.partition])
+
+	// A block from a peer not requested for the partition is not merged.
+	_, _, ok = sm.mergePartition(p1, &peerSyncState{
+		partition: s2.partition}, &util.Block{Header: util.Header{
+		BlockHeader: header}})
+	assert.False(t, ok)
+
+	// The partition is requested from another peer of the same partition
+	// when the peer is done.
+	p3, s3 := addTestPeer(sm)
+	s3.partition = s2.partition
+	delete(sm.peerStates, p2)
+	sm.reassignPartitions(p2)
+	assert.Equal(t, p3, partial.peers[s2.partition])
+	assert.Contains(t, s3.requestedBlocks, hash)
+
+	// The unpartitioned filter is used again when a partition has no peer.
+	sm.handleDonePeerMsg(p3)
+	assert.Equal(t, 1, sm.partitions)
+	assert.Empty(t, sm.partials)
+	assert.Contains(t, s1.requestedBlocks, hash)
+	assert.Contains(t, s1.staleBlocks, hash)
+}
+
+func TestPartitionFilterAdd(t *testing.T) {
+	var addr1, addr2, addr3 common.Uint168
+	rand.Read(addr1[:])
+	rand.Read(addr2[:])
+	rand.Read(addr3[:])
+	sm, parts := newTestManager(addr1, addr2)
+	p1, s1 := addTestPeer(sm)
+	sm.pushBloomFilter(p1)
+	p2, s2 := addTestPeer(sm)
+	sm.pushBloomFilter(p2)
+	if !assert.Equal(t, 2, sm.partitions) {
+		t.FailNow()
+	}
+
+	// A peer not a sync candidate is loaded with the unpartitioned filter.
+	p3, s3 := addTestPeer(sm)
+	s3.syncCandidate = false
+	sm.pushBloomFilter(p3)
+
+	// The new element is only added to the peer of it's partition and the
+	// peers with the unpartitioned filter.
+	parts[s2.partition] = append(parts[s2.partition], addr3)
+	sm.handleFilterAddMsg(&filterAddMsg{data: addr3.Bytes()})
+	assert.False(t, s1.filter.Matches(addr3.Bytes()))
+	assert.True(t, s2.filter.Matches(addr3.Bytes()))
+	assert.True(t, s3.filter.Matches(addr3.Bytes()))
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// ReorgBlock represents a block that has been detached from or attached to
+// the main chain during a chain reorganization.
+type ReorgBlock struct {
+	// The header of this block.
+	*Header
+
+	// Ids of the transactions stored within this block.
+	TxIds []*common.Uint256
+}
+
+// Reorg is a data structure describing a chain reorganization.
+type Reorg struct {
+	// CommonAncestor is the last header both the previous and the new
+	// best chain share.
+	CommonAncestor *Header
+
+	// Detached are the blocks removed from the main chain, ordered from the
+	// previous chain tip down to the common ancestor.
+	Detached []*ReorgBlock
+
+	// Attached are the blocks connected to the main chain, ordered from the
+	// common ancestor up to the new chain tip.
+	Attached []*ReorgBlock
+}
+
+// NewReorgBlock creates a ReorgBlock by the given header and transactions.
+func NewReorgBlock(header *Header, txs []Transaction) *ReorgBlock {
+	txIds := make([]*common.Uint256, 0, len(txs))
+	for _, tx := range txs {
+		txId := tx.Hash()
+		txIds = append(txIds, &txId)
+	}
+	return &ReorgBlock{Header: header, TxIds: txIds}
+}
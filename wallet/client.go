@@ -27,7 +27,9 @@ func RunClient(version, dataDir, rpcUrl string, assetId common.Uint256) {
 	app.Commands = []cli.Command{
 		wallet.NewCreateCommand(),
 		wallet.NewChangePasswordCommand(),
+		wallet.NewMnemonicCommand(),
 		wallet.NewResetCommand(),
+		wallet.NewCheckCommand(),
 		account.NewCommand(),
 		transaction.NewCommand(),
 	}
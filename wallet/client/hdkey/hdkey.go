@@ -0,0 +1,187 @@
+// Package hdkey implements BIP32 hierarchical deterministic keys over the
+// P-256 curve used by ELA, with BIP39 mnemonic seeds and the BIP44 path of
+// the ELA coin type.
+package hdkey
+
+import (
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"math/big"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// HardenedKeyStart is the index of the first hardened child key.
+	HardenedKeyStart = 0x80000000
+
+	// Purpose is the BIP44 purpose.
+	Purpose = 44
+
+	// CoinType is the ELA coin type registered in SLIP-0044.
+	CoinType = 2305
+
+	// EntropyBits is the entropy size of new mnemonics, 12 words.
+	EntropyBits = 128
+)
+
+var (
+	// masterKey is the HMAC key to create the master key from a seed.
+	masterKey = []byte("Bitcoin seed")
+
+	// ErrInvalidKey is returned when a derived key is invalid for the
+	// curve, the next index should be used, which happens with probability
+	// lower than 1 in 2^127.
+	ErrInvalidKey = errors.New("derived key is invalid")
+
+	// ErrInvalidMnemonic is returned when the mnemonic words or checksum
+	// are invalid.
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+)
+
+var curve = elliptic.P256()
+
+// ExtendedKey is a private key with the chain code to derive child keys.
+type ExtendedKey struct {
+	key       []byte
+	chainCode []byte
+	depth     uint8
+	index     uint32
+}
+
+// NewMaster creates the master key from the seed.
+func NewMaster(seed []byte) (*ExtendedKey, error) {
+	mac := hmac.New(sha512.New, masterKey)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	k := new(big.Int).SetBytes(sum[:32])
+	if k.Sign() == 0 || k.Cmp(curve.Params().N) >= 0 {
+		return nil, ErrInvalidKey
+	}
+	return &ExtendedKey{key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// Child derives the child key of the index, indexes from HardenedKeyStart
+// are hardened keys.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	var data []byte
+	if index >= HardenedKeyStart {
+		data = make([]byte, 1, 37)
+		data = append(data, k.key...)
+	} else {
+		data = k.publicKey()
+	}
+	var i [4]byte
+	binary.BigEndian.PutUint32(i[:], index)
+	data = append(data, i[:]...)
+
+	mac := hmac.New(sha512.New, k.chainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	n := curve.Params().N
+	il := new(big.Int).SetBytes(sum[:32])
+	if il.Cmp(n) >= 0 {
+		return nil, ErrInvalidKey
+	}
+	child := il.Add(il, new(big.Int).SetBytes(k.key))
+	child.Mod(child, n)
+	if child.Sign() == 0 {
+		return nil, ErrInvalidKey
+	}
+
+	key := make([]byte, 32)
+	b := child.Bytes()
+	copy(key[32-len(b):], b)
+	return &ExtendedKey{
+		key:       key,
+		chainCode: sum[32:],
+		depth:     k.depth + 1,
+		index:     index,
+	}, nil
+}
+
+// Derive derives the key of the path from the key.
+func (k *ExtendedKey) Derive(path ...uint32) (*ExtendedKey, error) {
+	key := k
+	for _, index := range path {
+		var err error
+		key, err = key.Child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// PrivateKey returns the 32 bytes private key.
+func (k *ExtendedKey) PrivateKey() []byte {
+	return k.key
+}
+
+// ChainCode returns the chain code of the key.
+func (k *ExtendedKey) ChainCode() []byte {
+	return k.chainCode
+}
+
+// Depth returns the depth of the key from the master key.
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+// Index returns the index of the key in it's parent.
+func (k *ExtendedKey) Index() uint32 {
+	return k.index
+}
+
+// publicKey returns the compressed public key.
+func (k *ExtendedKey) publicKey() []byte {
+	x, y := curve.ScalarBaseMult(k.key)
+	pub := make([]byte, 33)
+	pub[0] = 0x02 + byte(y.Bit(0))
+	b := x.Bytes()
+	copy(pub[33-len(b):], b)
+	return pub
+}
+
+// AccountPath returns the BIP44 path of the ELA account key,
+// m/44'/2305'/account'/change.
+func AccountPath(account, change uint32) []uint32 {
+	return []uint32{
+		Purpose + HardenedKeyStart,
+		CoinType + HardenedKeyStart,
+		account + HardenedKeyStart,
+		change,
+	}
+}
+
+// NewEntropy returns the random entropy of a new mnemonic.
+func NewEntropy() ([]byte, error) {
+	return bip39.NewEntropy(EntropyBits)
+}
+
+// NewMnemonic returns the mnemonic words of the entropy.
+func NewMnemonic(entropy []byte) (string, error) {
+	return bip39.NewMnemonic(entropy)
+}
+
+// EntropyFromMnemonic returns the entropy of the mnemonic words.
+func EntropyFromMnemonic(mnemonic string) ([]byte, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, ErrInvalidMnemonic
+	}
+	return bip39.EntropyFromMnemonic(mnemonic)
+}
+
+// NewSeed returns the seed of the mnemonic words with the passphrase.
+func NewSeed(mnemonic, passphrase string) ([]byte, error) {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, ErrInvalidMnemonic
+	}
+	return seed, nil
+}
@@ -0,0 +1,96 @@
+package hdkey
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDerive(t *testing.T) {
+	// The master key and hardened children are independent of the curve,
+	// so they match the BIP32 test vector 1.
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, err := NewMaster(seed)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+		hex.EncodeToString(master.PrivateKey()))
+	assert.Equal(t, "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508",
+		hex.EncodeToString(master.ChainCode()))
+
+	child, err := master.Child(HardenedKeyStart)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea",
+		hex.EncodeToString(child.PrivateKey()))
+	assert.Equal(t, "47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141",
+		hex.EncodeToString(child.ChainCode()))
+
+	// Derivation is deterministic.
+	path := append(AccountPath(0, 0), 1)
+	key1, err := master.Derive(path...)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	key2, err := master.Derive(path...)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, key1, key2)
+	assert.Equal(t, uint8(5), key1.Depth())
+	assert.Equal(t, uint32(1), key1.Index())
+	assert.Equal(t, 32, len(key1.PrivateKey()))
+
+	key0, err := master.Derive(append(AccountPath(0, 0), 0)...)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NotEqual(t, key0.PrivateKey(), key1.PrivateKey())
+}
+
+func TestMnemonic(t *testing.T) {
+	entropy, err := NewEntropy()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	mnemonic, err := NewMnemonic(entropy)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	got, err := EntropyFromMnemonic(mnemonic)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, entropy, got)
+
+	_, err = NewSeed(mnemonic, "")
+	assert.NoError(t, err)
+
+	_, err = EntropyFromMnemonic("abandon abandon abandon")
+	assert.Equal(t, ErrInvalidMnemonic, err)
+	_, err = NewSeed("abandon abandon abandon", "")
+	assert.Equal(t, ErrInvalidMnemonic, err)
+}
+
+func TestMnemonicVector(t *testing.T) {
+	// The first BIP39 test vector, the seed is derived with the passphrase
+	// "TREZOR".
+	entropy := make([]byte, 16)
+	mnemonic, err := NewMnemonic(entropy)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "abandon abandon abandon abandon abandon abandon"+
+		" abandon abandon abandon abandon abandon about", mnemonic)
+
+	seed, err := NewSeed(mnemonic, "TREZOR")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e5349553"+
+		"1f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		hex.EncodeToString(seed))
+}
@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"crypto/aes"
 	"crypto/rand"
 	"crypto/sha256"
 	"errors"
@@ -9,6 +10,8 @@ import (
 	"sync"
 
 	"github.com/elastos/Elastos.ELA.SPV/sdk"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/client/hdkey"
+
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/crypto"
 )
@@ -24,10 +27,34 @@ type Keystore struct {
 
 	masterKey []byte
 
+	// account is the BIP44 external chain key of a HD keystore, the
+	// accounts are derived from it by index.
+	account *hdkey.ExtendedKey
+
 	accounts []*sdk.Account
 }
 
 func CreateKeystore(password []byte) (*Keystore, error) {
+	return createKeystore(password, nil)
+}
+
+// CreateHDKeystore creates a HD keystore from the mnemonic, a new mnemonic
+// is generated if it is empty.
+func CreateHDKeystore(password []byte, mnemonic string) (*Keystore, error) {
+	var entropy []byte
+	var err error
+	if len(mnemonic) == 0 {
+		entropy, err = hdkey.NewEntropy()
+	} else {
+		entropy, err = hdkey.EntropyFromMnemonic(mnemonic)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return createKeystore(password, entropy)
+}
+
+func createKeystore(password, entropy []byte) (*Keystore, error) {
 	keystoreFile, err := CreateKeystoreFile()
 	if err != nil {
 		return nil, err
@@ -63,8 +90,32 @@ func CreateKeystore(password []byte) (*Keystore, error) {
 	// Set master key encrypted
 	keystoreFile.SetMasterKeyEncrypted(masterKeyEncrypted)
 
-	// Generate new key pair
-	privateKey, publicKey, err := crypto.GenerateKeyPair()
+	// Generate new key pair, or derive the main account of a HD keystore
+	var privateKey []byte
+	var publicKey *crypto.PublicKey
+	if entropy == nil {
+		privateKey, publicKey, err = crypto.GenerateKeyPair()
+	} else {
+		// AesEncrypt does not pad, so the entropy of 15, 18 and 21 words
+		// is padded to the block size and it's length is saved.
+		padded := make([]byte, (len(entropy)+aes.BlockSize-1)/
+			aes.BlockSize*aes.BlockSize)
+		copy(padded, entropy)
+		var entropyEncrypted []byte
+		entropyEncrypted, err = crypto.AesEncrypt(padded, masterKey, iv)
+		if err != nil {
+			return nil, err
+		}
+		// Set entropy encrypted
+		keystoreFile.SetEntropyEncrypted(entropyEncrypted)
+		keystoreFile.SetEntropyLength(len(entropy))
+
+		keystore.account, err = newAccountKey(entropy)
+		if err != nil {
+			return nil, err
+		}
+		privateKey, publicKey, err = keystore.deriveKeyPair(0)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -113,9 +164,80 @@ func (store *Keystore) initKeystore(keystoreFile *KeystoreFile, password []byte)
 		return err
 	}
 
+	if len(store.EntropyEncrypted) > 0 {
+		entropy, err := store.decryptEntropy(masterKey)
+		if err != nil {
+			return err
+		}
+		store.account, err = newAccountKey(entropy)
+		if err != nil {
+			return err
+		}
+	}
+
 	return store.initAccounts(masterKey, privateKey, publicKey)
 }
 
+// newAccountKey derives the BIP44 external chain key of the first ELA
+// account from the mnemonic entropy.
+func newAccountKey(entropy []byte) (*hdkey.ExtendedKey, error) {
+	mnemonic, err := hdkey.NewMnemonic(entropy)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hdkey.NewSeed(mnemonic, "")
+	if err != nil {
+		return nil, err
+	}
+	master, err := hdkey.NewMaster(seed)
+	if err != nil {
+		return nil, err
+	}
+	return master.Derive(hdkey.AccountPath(0, 0)...)
+}
+
+// deriveKeyPair derives the key pair of the account index in a HD keystore.
+func (store *Keystore) deriveKeyPair(index int) ([]byte, *crypto.PublicKey, error) {
+	key, err := store.account.Child(uint32(index))
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey := key.PrivateKey()
+	return privateKey, crypto.NewPubKey(privateKey), nil
+}
+
+// subKeyPair returns the key pair of the sub account index.
+func (store *Keystore) subKeyPair(index int) ([]byte, *crypto.PublicKey, error) {
+	if store.account != nil {
+		return store.deriveKeyPair(index)
+	}
+	return crypto.GenerateSubKeyPair(index, store.masterKey, store.accounts[0].PrivateKey())
+}
+
+// IsHD returns if the keystore is a HD keystore.
+func (store *Keystore) IsHD() bool {
+	return store.account != nil
+}
+
+// Mnemonic returns the mnemonic words of a HD keystore.
+func (store *Keystore) Mnemonic(password []byte) (string, error) {
+	if store.account == nil {
+		return "", errors.New("not a HD keystore")
+	}
+	if err := store.verifyPassword(password); err != nil {
+		return "", err
+	}
+	masterKey, err := store.decryptMasterKey(crypto.ToAesKey(password))
+	if err != nil {
+		return "", err
+	}
+	entropy, err := store.decryptEntropy(masterKey)
+	if err != nil {
+		return "", err
+	}
+	return hdkey.NewMnemonic(entropy)
+}
+
 func (store *Keystore) initAccounts(masterKey, privateKey []byte, publicKey *crypto.PublicKey) error {
 	// initiate main account
 	mainAccount, err := sdk.NewAccount(privateKey, publicKey)
@@ -131,7 +253,7 @@ func (store *Keystore) initAccounts(masterKey, privateKey []byte, publicKey *cry
 
 	// initiate sub accounts
 	for i := 1; i <= store.SubAccountsCount; i++ {
-		privateKey, publicKey, err := crypto.GenerateSubKeyPair(i, masterKey, privateKey)
+		privateKey, publicKey, err := store.subKeyPair(i)
 		if err != nil {
 			return err
 		}
@@ -212,8 +334,7 @@ func (store *Keystore) MainAccount() *sdk.Account {
 
 func (store *Keystore) NewAccount() *sdk.Account {
 	// create sub account
-	privateKey, publicKey, err := crypto.GenerateSubKeyPair(
-		store.SubAccountsCount+1, store.masterKey, store.accounts[0].PrivateKey())
+	privateKey, publicKey, err := store.subKeyPair(store.SubAccountsCount + 1)
 	if err != nil {
 		panic(fmt.Sprint("New sub account failed,", err))
 	}
@@ -339,6 +460,27 @@ func (store *Keystore) decryptPrivateKey(masterKey, passwordKey []byte) ([]byte,
 	return privateKey, crypto.NewPubKey(privateKey), nil
 }
 
+func (store *Keystore) decryptEntropy(masterKey []byte) ([]byte, error) {
+	entropyEncrypted, err := store.GetEntropyEncrypted()
+	if err != nil {
+		return nil, err
+	}
+
+	iv, err := store.GetIV()
+	if err != nil {
+		return nil, err
+	}
+
+	entropy, err := crypto.AesDecrypt(entropyEncrypted, masterKey, iv)
+	if err != nil {
+		return nil, err
+	}
+	if store.EntropyLength < 0 || store.EntropyLength > len(entropy) {
+		return nil, errors.New("invalid entropy length")
+	}
+	return entropy[:store.EntropyLength], nil
+}
+
 func (store *Keystore) FromJson(str string, password string) error {
 	file := new(KeystoreFile)
 	file.FromJson(str)
@@ -24,6 +24,14 @@ type KeystoreFile struct {
 	MasterKeyEncrypted  string
 	PrivateKeyEncrypted string
 
+	// EntropyEncrypted is the mnemonic entropy of a HD keystore, empty if
+	// the keystore is not a HD keystore.
+	EntropyEncrypted string
+
+	// EntropyLength is the length of the entropy, which is padded to the
+	// AES block size before encrypted.
+	EntropyLength int
+
 	SubAccountsCount int
 }
 
@@ -67,6 +75,14 @@ func (store *KeystoreFile) SetPrivateKeyEncrypted(privateKeyEncrypted []byte) {
 	store.PrivateKeyEncrypted = common.BytesToHexString(privateKeyEncrypted)
 }
 
+func (store *KeystoreFile) SetEntropyEncrypted(entropyEncrypted []byte) {
+	store.EntropyEncrypted = common.BytesToHexString(entropyEncrypted)
+}
+
+func (store *KeystoreFile) SetEntropyLength(length int) {
+	store.EntropyLength = length
+}
+
 func (store *KeystoreFile) GetIV() ([]byte, error) {
 
 	iv, err := common.HexStringToBytes(store.IV)
@@ -107,6 +123,16 @@ func (store *KeystoreFile) GetPrivetKeyEncrypted() ([]byte, error) {
 	return privateKeyEncrypted, nil
 }
 
+func (store *KeystoreFile) GetEntropyEncrypted() ([]byte, error) {
+
+	entropyEncrypted, err := common.HexStringToBytes(store.EntropyEncrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	return entropyEncrypted, nil
+}
+
 func (store *KeystoreFile) LoadFromFile() error {
 	store.Lock()
 	defer store.Unlock()
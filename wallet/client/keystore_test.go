@@ -0,0 +1,124 @@
+package client
+
+import (
+	"crypto/rand"
+	"os"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/sdk"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/client/hdkey"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// chdirTemp changes the working directory to a temporary directory, because
+// the keystore file is stored in the working directory.
+func chdirTemp(t *testing.T) {
+	wd, err := os.Getwd()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, os.Chdir(t.TempDir())) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// assertAccounts asserts the keystores have the same accounts.
+func assertAccounts(t *testing.T, expect, accounts []*sdk.Account) {
+	if !assert.Len(t, accounts, len(expect)) {
+		t.FailNow()
+	}
+	for i, account := range accounts {
+		assert.Equal(t, expect[i].ProgramHash(), account.ProgramHash())
+		assert.Equal(t, expect[i].PrivateKey(), account.PrivateKey())
+	}
+}
+
+func TestHDKeystore(t *testing.T) {
+	chdirTemp(t)
+	password := []byte("password")
+
+	// Create a HD keystore with two sub accounts.
+	store, err := CreateHDKeystore(password, "")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, store.IsHD())
+	store.NewAccount()
+	store.NewAccount()
+	accounts := store.GetAccounts()
+	assert.Len(t, accounts, 3)
+
+	// The accounts are derived again when reopened.
+	opened, err := OpenKeystore(password)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, opened.IsHD())
+	assertAccounts(t, accounts, opened.GetAccounts())
+
+	_, err = opened.Mnemonic([]byte("wrong"))
+	assert.Error(t, err)
+	mnemonic, err := opened.Mnemonic(password)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// Restoring from the mnemonic gives the same main and sub accounts.
+	if !assert.NoError(t, os.Remove(KeystoreFilename)) {
+		t.FailNow()
+	}
+	restored, err := CreateHDKeystore([]byte("another"), mnemonic)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	restored.NewAccount()
+	restored.NewAccount()
+	assertAccounts(t, accounts, restored.GetAccounts())
+
+	// A keystore which is not HD has no mnemonic.
+	if !assert.NoError(t, os.Remove(KeystoreFilename)) {
+		t.FailNow()
+	}
+	plain, err := CreateKeystore(password)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.False(t, plain.IsHD())
+	_, err = plain.Mnemonic(password)
+	assert.Error(t, err)
+}
+
+func TestHDKeystoreMnemonicLength(t *testing.T) {
+	chdirTemp(t)
+	password := []byte("password")
+
+	// Mnemonics of 12 to 24 words are restored, the entropy of 15, 18 and
+	// 21 words is not a multiple of the AES block size.
+	for _, size := range []int{16, 20, 24, 28, 32} {
+		entropy := make([]byte, size)
+		rand.Read(entropy)
+		mnemonic, err := hdkey.NewMnemonic(entropy)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		store, err := CreateHDKeystore(password, mnemonic)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		opened, err := OpenKeystore(password)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		assertAccounts(t, store.GetAccounts(), opened.GetAccounts())
+		restored, err := opened.Mnemonic(password)
+		assert.NoError(t, err)
+		assert.Equal(t, mnemonic, restored)
+
+		if !assert.NoError(t, os.Remove(KeystoreFilename)) {
+			t.FailNow()
+		}
+	}
+}
@@ -43,12 +43,30 @@ func Setup(dataDir, rpcUrl string, assetId common.Uint256) {
 	sysAssetId = assetId
 }
 
+// DataDir returns the data directory of the wallet.
+func DataDir() string {
+	return dataPath
+}
+
 func Create(password []byte) error {
 	keyStore, err := CreateKeystore(password)
 	if err != nil {
 		return err
 	}
+	return create(keyStore)
+}
+
+// CreateHD creates a HD wallet from the mnemonic, a new mnemonic is
+// generated if it is empty.
+func CreateHD(password []byte, mnemonic string) error {
+	keyStore, err := CreateHDKeystore(password, mnemonic)
+	if err != nil {
+		return err
+	}
+	return create(keyStore)
+}
 
+func create(keyStore *Keystore) error {
 	db, err := database.New(dataPath)
 	if err != nil {
 		return err
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/elastos/Elastos.ELA.SPV/wallet/client"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/store/fsck"
 
 	"github.com/urfave/cli"
 )
@@ -18,13 +19,52 @@ func createWallet(context *cli.Context) {
 		return
 	}
 
-	err = client.Create(password)
+	mnemonic := context.String("mnemonic")
+	if context.Bool("hd") || len(mnemonic) > 0 {
+		err = client.CreateHD(password, mnemonic)
+	} else {
+		err = client.Create(password)
+	}
 	if err != nil {
-		fmt.Println("--CREATE WALLET FAILED--")
+		fmt.Println("--CREATE WALLET FAILED--", err)
 		return
 	}
 
 	client.ShowAccountInfo(password)
+
+	// Show the new generated mnemonic to write down.
+	if context.Bool("hd") && len(mnemonic) == 0 {
+		showMnemonic(password)
+	}
+}
+
+func showMnemonic(password []byte) {
+	keyStore, err := client.OpenKeystore(password)
+	if err != nil {
+		fmt.Println("--OPEN KEYSTORE FAILED--")
+		return
+	}
+
+	mnemonic, err := keyStore.Mnemonic(password)
+	if err != nil {
+		fmt.Println("--GET MNEMONIC FAILED--", err)
+		return
+	}
+
+	fmt.Println("--PLEASE WRITE DOWN THE MNEMONIC AND KEEP IT SAFE--")
+	fmt.Println(mnemonic)
+}
+
+func exportMnemonic(context *cli.Context) {
+	password := []byte(context.String("password"))
+
+	password, err := client.GetPassword(password, false)
+	if err != nil {
+		fmt.Println("--GET PASSWORD FAILED--")
+		return
+	}
+
+	showMnemonic(password)
 }
 
 func changePassword(context *cli.Context) {
@@ -96,11 +136,97 @@ func resetDatabase(context *cli.Context) {
 	fmt.Println("--WALLET DATABASE HAS BEEN RESET--")
 }
 
+func checkDatabase(context *cli.Context) {
+	checker, err := fsck.New(client.DataDir())
+	if err != nil {
+		fmt.Println("--OPEN DATABASE FAILED--", err)
+		fmt.Println("--PLEASE STOP THE SPV SERVICE AND TRY AGAIN--")
+		return
+	}
+	defer checker.Close()
+
+	report, err := checker.Check()
+	if err != nil {
+		fmt.Println("--CHECK DATABASE FAILED--", err)
+		return
+	}
+
+	chain := report.Chain
+	fmt.Println("chain tip height:", chain.Best.Height)
+	if chain.LastGood != nil {
+		fmt.Println("last good height:", chain.LastGood.Height)
+	} else {
+		fmt.Println("last good height: unknown")
+	}
+	fmt.Println("synced height:", report.StateHeight)
+	for _, err := range chain.Errors {
+		fmt.Println("header error:", err)
+	}
+	for _, tx := range report.OrphanTxs {
+		fmt.Println("orphan transaction:", tx.Hash, "height:", tx.Height)
+	}
+	for _, tx := range report.BadProofTxs {
+		fmt.Println("transaction not proved:", tx.Hash, "height:", tx.Height)
+	}
+	for _, utxo := range report.OrphanUTXOs {
+		fmt.Println("orphan", utxo)
+	}
+	for _, stxo := range report.OrphanSTXOs {
+		fmt.Println("orphan", stxo)
+	}
+
+	repair := context.String("repair")
+	if report.OK() && repair != "rescan" {
+		fmt.Println("--WALLET DATABASE IS CONSISTENT--")
+		return
+	}
+
+	switch repair {
+	case "":
+		fmt.Println("--WALLET DATABASE IS INCONSISTENT--")
+		fmt.Println("use --repair truncate, orphans or rescan to repair")
+		return
+
+	case "truncate":
+		if chain.LastGood == nil {
+			fmt.Println("--NO GOOD HEADER TO TRUNCATE TO, PLEASE RESCAN--")
+			return
+		}
+		err = checker.Truncate(report, chain.LastGood.Height)
+
+	case "orphans":
+		err = checker.DropOrphans(report)
+
+	case "rescan":
+		err = checker.Rescan()
+
+	default:
+		fmt.Println("--UNKNOWN REPAIR ACTION--", repair)
+		return
+	}
+	if err != nil {
+		fmt.Println("--REPAIR WALLET DATABASE FAILED--", err)
+		return
+	}
+
+	fmt.Println("--WALLET DATABASE HAS BEEN REPAIRED--")
+}
+
 func NewCreateCommand() cli.Command {
 	return cli.Command{
-		Name:   "create",
-		Usage:  "create wallet",
-		Flags:  append(client.CommonFlags),
+		Name:  "create",
+		Usage: "create wallet",
+		Flags: append(client.CommonFlags,
+			cli.BoolFlag{
+				Name:  "hd",
+				Usage: "create a HD wallet with a new mnemonic",
+			},
+			cli.StringFlag{
+				Name: "mnemonic, m",
+				Usage: "restore a HD wallet from the mnemonic words, " +
+					"create the sub accounts again to restore them",
+			},
+		),
 		Action: createWallet,
 		OnUsageError: func(c *cli.Context, err error, subCommand bool) error {
 			return cli.NewExitError(err, 1)
@@ -108,6 +234,18 @@ func NewCreateCommand() cli.Command {
 	}
 }
 
+func NewMnemonicCommand() cli.Command {
+	return cli.Command{
+		Name:   "mnemonic",
+		Usage:  "export the mnemonic of HD wallet",
+		Flags:  append(client.CommonFlags),
+		Action: exportMnemonic,
+		OnUsageError: func(c *cli.Context, err error, subCommand bool) error {
+			return cli.NewExitError(err, 1)
+		},
+	}
+}
+
 func NewChangePasswordCommand() cli.Command {
 	return cli.Command{
 		Name:   "changepassword",
@@ -131,3 +269,21 @@ func NewResetCommand() cli.Command {
 		},
 	}
 }
+
+func NewCheckCommand() cli.Command {
+	return cli.Command{
+		Name:  "check",
+		Usage: "check wallet database consistency, the SPV service must be stopped",
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name: "repair",
+				Usage: "repair action, \"truncate\" rollback to the last good height, " +
+					"\"orphans\" delete orphaned data, \"rescan\" synchronize again",
+			},
+		},
+		Action: checkDatabase,
+		OnUsageError: func(c *cli.Context, err error, subCommand bool) error {
+			return cli.NewExitError(err, 1)
+		},
+	}
+}
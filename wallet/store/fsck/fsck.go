@@ -0,0 +1,260 @@
+// Package fsck checks the consistency of the wallet data directory, which may
+// be broken by a crash or power failure, and repairs it.
+package fsck
+
+import (
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA.SPV/blockchain"
+	"github.com/elastos/Elastos.ELA.SPV/bloom"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/store/headers"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/store/sqlite"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/sutil"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/p2p/msg"
+)
+
+// Report is the result of Checker.Check.
+type Report struct {
+	// Chain is the result of checking header chain.
+	Chain *blockchain.ChainCheck
+
+	// StateHeight is the synced height recorded in wallet database.
+	StateHeight uint32
+
+	// OrphanTxs are transactions stored on heights without a header on the
+	// main chain.
+	OrphanTxs []*util.Tx
+
+	// BadProofTxs are transactions not included in the merkle proof of the
+	// header on their heights.
+	BadProofTxs []*util.Tx
+
+	// OrphanUTXOs are UTXOs which transaction does not exist.
+	OrphanUTXOs []*sutil.UTXO
+
+	// OrphanSTXOs are STXOs which transaction or spend transaction does not
+	// exist.
+	OrphanSTXOs []*sutil.STXO
+}
+
+// OK returns if no inconsistency was found.
+func (r *Report) OK() bool {
+	return len(r.Chain.Errors) == 0 && len(r.OrphanTxs) == 0 &&
+		len(r.BadProofTxs) == 0 && len(r.OrphanUTXOs) == 0 &&
+		len(r.OrphanSTXOs) == 0 && r.StateHeight <= r.Chain.Best.Height
+}
+
+// Checker checks and repairs the headers and wallet database in a data
+// directory, the SPV service using the data directory must be stopped.
+type Checker struct {
+	headers *headers.Database
+	store   sqlite.DataStore
+}
+
+// New opens the databases in dataDir.
+func New(dataDir string) (*Checker, error) {
+	h, err := headers.NewDatabase(dataDir)
+	if err != nil {
+		return nil, fmt.Errorf("open headers database failed, %s", err)
+	}
+
+	store, err := sqlite.NewDatabase(dataDir)
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("open wallet database failed, %s", err)
+	}
+
+	return &Checker{headers: h, store: store}, nil
+}
+
+// Check walks the header chain from chain tip to genesis, verifies stored
+// transactions with the merkle proofs of their headers and finds UTXOs and
+// STXOs without transactions.
+func (c *Checker) Check() (*Report, error) {
+	chain, err := blockchain.CheckChain(c.headers)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		Chain:       chain,
+		StateHeight: c.store.State().GetHeight(),
+	}
+
+	txs, err := c.store.Txs().GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Group transactions by height, so the merkle proof of each header is
+	// checked once.
+	txIds := make(map[common.Uint256]struct{}, len(txs))
+	heightTxs := make(map[uint32][]*util.Tx)
+	for _, tx := range txs {
+		txIds[tx.Hash] = struct{}{}
+
+		// Unconfirmed transactions have no header.
+		if tx.Height == 0 {
+			continue
+		}
+		heightTxs[tx.Height] = append(heightTxs[tx.Height], tx)
+	}
+
+	for height, txs := range heightTxs {
+		hash, ok := chain.Hashes[height]
+		if !ok {
+			report.OrphanTxs = append(report.OrphanTxs, txs...)
+			continue
+		}
+
+		header, err := c.headers.Get(&hash)
+		if err != nil {
+			report.OrphanTxs = append(report.OrphanTxs, txs...)
+			continue
+		}
+
+		proved := make(map[common.Uint256]struct{})
+		ids, err := bloom.CheckMerkleBlock(msg.MerkleBlock{
+			Header:       header.BlockHeader,
+			Transactions: header.NumTxs,
+			Hashes:       header.Hashes,
+			Flags:        header.Flags,
+		})
+		if err == nil {
+			for _, id := range ids {
+				proved[*id] = struct{}{}
+			}
+		}
+		for _, tx := range txs {
+			if _, ok := proved[tx.Hash]; !ok {
+				report.BadProofTxs = append(report.BadProofTxs, tx)
+			}
+		}
+	}
+
+	utxos, err := c.store.UTXOs().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, utxo := range utxos {
+		if _, ok := txIds[utxo.Op.TxID]; !ok {
+			report.OrphanUTXOs = append(report.OrphanUTXOs, utxo)
+		}
+	}
+
+	stxos, err := c.store.STXOs().GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, stxo := range stxos {
+		_, ok := txIds[stxo.Op.TxID]
+		_, spent := txIds[stxo.SpendTxId]
+		if !ok || !spent {
+			report.OrphanSTXOs = append(report.OrphanSTXOs, stxo)
+		}
+	}
+
+	return report, nil
+}
+
+// Truncate rolls back the chain tip and all wallet data to the given height,
+// which is usually the height of Report.Chain.LastGood.  The height must not
+// be above LastGood, so all headers from genesis to it are consistent.
+func (c *Checker) Truncate(report *Report, height uint32) error {
+	lastGood := report.Chain.LastGood
+	if lastGood == nil {
+		return fmt.Errorf("no last good header, the chain can not be" +
+			" walked to genesis")
+	}
+	if height > lastGood.Height {
+		return fmt.Errorf("height %d above last good header on height %d",
+			height, lastGood.Height)
+	}
+	for h := uint32(0); h < height; h++ {
+		if _, ok := report.Chain.Hashes[h]; !ok {
+			return fmt.Errorf("no good header on height %d", h)
+		}
+	}
+	hash, ok := report.Chain.Hashes[height]
+	if !ok {
+		return fmt.Errorf("no good header on height %d", height)
+	}
+	header, err := c.headers.Get(&hash)
+	if err != nil {
+		return err
+	}
+
+	// Rollback from the highest height of chain tip, state and transactions.
+	top := report.Chain.Best.Height
+	if report.StateHeight > top {
+		top = report.StateHeight
+	}
+	for _, txs := range [][]*util.Tx{report.OrphanTxs, report.BadProofTxs} {
+		for _, tx := range txs {
+			if tx.Height > top {
+				top = tx.Height
+			}
+		}
+	}
+
+	batch := c.store.Batch()
+	for h := top; h > height; h-- {
+		if err := batch.RollbackHeight(h); err != nil {
+			batch.Rollback()
+			return err
+		}
+	}
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+
+	if err := c.store.State().PutHeight(height); err != nil {
+		return err
+	}
+	return c.headers.Put(header, true)
+}
+
+// DropOrphans deletes the orphaned and not proved transactions, UTXOs and
+// STXOs found by Check.
+func (c *Checker) DropOrphans(report *Report) error {
+	batch := c.store.Batch()
+	for _, txs := range [][]*util.Tx{report.OrphanTxs, report.BadProofTxs} {
+		for _, tx := range txs {
+			if err := batch.Txs().Del(&tx.Hash); err != nil {
+				batch.Rollback()
+				return err
+			}
+		}
+	}
+	for _, utxo := range report.OrphanUTXOs {
+		if err := batch.UTXOs().Del(utxo.Op); err != nil {
+			batch.Rollback()
+			return err
+		}
+	}
+	for _, stxo := range report.OrphanSTXOs {
+		if err := batch.STXOs().Del(stxo.Op); err != nil {
+			batch.Rollback()
+			return err
+		}
+	}
+	return batch.Commit()
+}
+
+// Rescan deletes all headers and wallet data except addresses, so the SPV
+// service will synchronize the chain from genesis again.
+func (c *Checker) Rescan() error {
+	if err := c.headers.Clear(); err != nil {
+		return err
+	}
+	return c.store.Clear()
+}
+
+// Close the databases.
+func (c *Checker) Close() error {
+	c.store.Close()
+	return c.headers.Close()
+}
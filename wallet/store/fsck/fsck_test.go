@@ -0,0 +1,165 @@
+package fsck
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/blockchain"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/sutil"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestTx creates a transaction stored on the given height.
+func newTestTx(t *testing.T, height uint32) *util.Tx {
+	var addr common.Uint168
+	rand.Read(addr[:])
+	tx := types.Transaction{
+		TxType:  types.TransferAsset,
+		Payload: &payload.TransferAsset{},
+		Outputs: []*types.Output{{ProgramHash: addr, Value: 100}},
+	}
+	buf := new(bytes.Buffer)
+	if !assert.NoError(t, tx.Serialize(buf)) {
+		t.FailNow()
+	}
+	return &util.Tx{Hash: tx.Hash(), Height: height, RawData: buf.Bytes()}
+}
+
+// newTestHeader creates the header after prev, which merkle proof includes
+// only the given transaction.
+func newTestHeader(prev *util.Header, tx *util.Tx) *util.Header {
+	header := &types.Header{Bits: 0x1d00ffff}
+	if tx != nil {
+		header.MerkleRoot = tx.Hash
+	}
+	if prev == nil {
+		return &util.Header{BlockHeader: sutil.NewHeader(header),
+			TotalWork: new(big.Int)}
+	}
+	header.Previous = prev.Hash()
+	header.Height = prev.Height + 1
+	h := &util.Header{
+		BlockHeader: sutil.NewHeader(header),
+		Height:      prev.Height + 1,
+		TotalWork: new(big.Int).Add(prev.TotalWork,
+			blockchain.CalcWork(header.Bits)),
+	}
+	if tx != nil {
+		h.NumTxs = 1
+		h.Hashes = []*common.Uint256{&tx.Hash}
+		h.Flags = []byte{0x01}
+	}
+	return h
+}
+
+func TestChecker(t *testing.T) {
+	c, err := New(t.TempDir())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer c.Close()
+
+	// A chain of three headers, the transactions on height 1 and 2 are
+	// included in the merkle proofs.
+	tx1, tx2 := newTestTx(t, 1), newTestTx(t, 2)
+	genesis := newTestHeader(nil, nil)
+	h1 := newTestHeader(genesis, tx1)
+	h2 := newTestHeader(h1, tx2)
+	for _, header := range []*util.Header{genesis, h1, h2} {
+		if !assert.NoError(t, c.headers.Put(header, true)) {
+			t.FailNow()
+		}
+	}
+
+	// A transaction without header, a transaction not in the merkle proof
+	// and an UTXO without transaction.
+	orphan, badProof := newTestTx(t, 5), newTestTx(t, 1)
+	for _, tx := range []*util.Tx{tx1, tx2, orphan, badProof} {
+		if !assert.NoError(t, c.store.Txs().Put(tx)) {
+			t.FailNow()
+		}
+	}
+	var addr common.Uint168
+	rand.Read(addr[:])
+	utxo := sutil.NewUTXO(newTestTx(t, 2).Hash, 2, 0, 100, 0, addr)
+	if !assert.NoError(t, c.store.UTXOs().Put(utxo)) ||
+		!assert.NoError(t, c.store.State().PutHeight(2)) {
+		t.FailNow()
+	}
+
+	report, err := c.Check()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Empty(t, report.Chain.Errors)
+	assert.Equal(t, uint32(2), report.StateHeight)
+	if assert.Len(t, report.OrphanTxs, 1) {
+		assert.Equal(t, orphan.Hash, report.OrphanTxs[0].Hash)
+	}
+	if assert.Len(t, report.BadProofTxs, 1) {
+		assert.Equal(t, badProof.Hash, report.BadProofTxs[0].Hash)
+	}
+	if assert.Len(t, report.OrphanUTXOs, 1) {
+		assert.Equal(t, utxo.Op, report.OrphanUTXOs[0].Op)
+	}
+	assert.False(t, report.OK())
+
+	// Drop the orphans found.
+	if !assert.NoError(t, c.DropOrphans(report)) {
+		t.FailNow()
+	}
+	report, err = c.Check()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, report.OK())
+
+	// Truncate to height 1 rolls back the chain tip, state height and
+	// transactions above it.
+	if !assert.NoError(t, c.Truncate(report, 1)) {
+		t.FailNow()
+	}
+	best, err := c.headers.GetBest()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, h1.Hash(), best.Hash())
+	assert.Equal(t, uint32(1), c.store.State().GetHeight())
+	_, err = c.store.Txs().Get(&tx1.Hash)
+	assert.NoError(t, err)
+	_, err = c.store.Txs().Get(&tx2.Hash)
+	assert.Error(t, err)
+
+	report, err = c.Check()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, report.OK())
+	assert.Equal(t, uint32(1), report.Chain.Best.Height)
+
+	// Truncate to a height without a good header fails.
+	assert.Error(t, c.Truncate(report, 2))
+
+	// Truncate fails without a last good header, or with a gap below the
+	// height, and the chain tip is not changed.
+	noLastGood := *report.Chain
+	noLastGood.LastGood = nil
+	assert.Error(t, c.Truncate(&Report{Chain: &noLastGood}, 1))
+
+	gap := *report.Chain
+	gap.Hashes = map[uint32]common.Uint256{1: h1.Hash()}
+	assert.Error(t, c.Truncate(&Report{Chain: &gap}, 1))
+
+	best, err = c.headers.GetBest()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, h1.Hash(), best.Hash())
+}
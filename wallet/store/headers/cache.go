@@ -37,6 +37,10 @@ func (cache *cache) set(header *util.Header) {
 	cache.headers.Set(header.Hash().String(), header)
 }
 
+func (cache *cache) del(hash *common.Uint256) {
+	cache.headers.Delete(hash.String())
+}
+
 func (cache *cache) get(hash *common.Uint256) (*util.Header, error) {
 	sh, ok := cache.headers.Get(hash.String())
 	if !ok {
@@ -0,0 +1,48 @@
+package headers
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/database/dbtest"
+)
+
+// databases creates an empty headers database of every storage.
+var databases = []struct {
+	name string
+	new  func(t *testing.T) (*Database, error)
+}{
+	{"LevelDB", func(t *testing.T) (*Database, error) {
+		return NewDatabase(t.TempDir())
+	}},
+	{"Mem", func(t *testing.T) (*Database, error) {
+		return NewMemDatabase()
+	}},
+}
+
+func TestDatabaseConformance(t *testing.T) {
+	for _, db := range databases {
+		db := db
+		t.Run(db.name, func(t *testing.T) {
+			dbtest.TestHeaders(t, func(t *testing.T) (database.Headers, error) {
+				return db.new(t)
+			})
+		})
+	}
+}
+
+func TestChainStoreConformance(t *testing.T) {
+	for _, db := range databases {
+		db := db
+		t.Run(db.name, func(t *testing.T) {
+			dbtest.TestChainStore(t, func(t *testing.T) (database.ChainStore, error) {
+				h, err := db.new(t)
+				if err != nil {
+					return nil, err
+				}
+				return database.NewChainDB(h, database.NewMemTxsDB(),
+					database.NewMemJournal(), nil)
+			})
+		})
+	}
+}
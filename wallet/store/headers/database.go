@@ -1,6 +1,8 @@
 package headers
 
 import (
+	"bytes"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"path/filepath"
@@ -12,6 +14,8 @@ import (
 
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/storage"
 )
 
 // Ensure Database implement headers interface
@@ -27,6 +31,7 @@ type Database struct {
 
 var (
 	BKTHeaders  = []byte("H")
+	BKTIndexes  = []byte("I")
 	BKTChainTip = []byte("B")
 )
 
@@ -36,6 +41,27 @@ func NewDatabase(dataDir string) (*Database, error) {
 		return nil, err
 	}
 
+	return newDatabase(db)
+}
+
+// NewMemDatabase creates a headers database that keeps all headers in memory,
+// all data will be lost after the process exits.
+func NewMemDatabase() (*Database, error) {
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDatabase(db)
+}
+
+func newDatabase(db *leveldb.DB) (*Database, error) {
+	err := database.Upgrade("headers", &version{db: db}, migrations(db))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	headers := &Database{
 		RWMutex:   new(sync.RWMutex),
 		db:        db,
@@ -71,10 +97,6 @@ func (d *Database) Put(header *util.Header, newTip bool) error {
 	d.Lock()
 	defer d.Unlock()
 
-	d.cache.set(header)
-	if newTip {
-		d.cache.tip = header
-	}
 	key := toKey(BKTHeaders, header.Hash().Bytes()...)
 
 	bytes, err := header.Serialize()
@@ -82,18 +104,71 @@ func (d *Database) Put(header *util.Header, newTip bool) error {
 		return err
 	}
 
-	err = d.db.Put(key, bytes, nil)
-	if err != nil {
+	// Write header, chain tip and height index in one batch, so they are
+	// updated atomically.  A new tip is synced, so it is on disk before the
+	// block commit is removed from journal.
+	batch := new(leveldb.Batch)
+	batch.Put(key, bytes)
+	var wo *opt.WriteOptions
+	if newTip {
+		batch.Put(BKTChainTip, bytes)
+		d.index(batch, header)
+		wo = &opt.WriteOptions{Sync: true}
+	}
+	if err := d.db.Write(batch, wo); err != nil {
 		return err
 	}
 
+	d.cache.set(header)
 	if newTip {
-		err = d.db.Put(BKTChainTip, bytes, nil)
+		d.cache.tip = header
+	}
+	return nil
+}
+
+// index updates the height index to the main chain ending with tip.  Heights
+// above tip are removed, and heights of the previous main chain are replaced
+// back to the common ancestor, or a missing header.
+func (d *Database) index(batch *leveldb.Batch, tip *util.Header) {
+	if d.cache.tip != nil {
+		for height := d.cache.tip.Height; height > tip.Height; height-- {
+			batch.Delete(indexKey(height))
+		}
+	}
+
+	batch.Put(indexKey(tip.Height), tip.Hash().Bytes())
+	for header := tip; header.Height > 0; {
+		prev := header.Previous()
+		hash, err := d.db.Get(indexKey(header.Height-1), nil)
+		if err == nil && bytes.Equal(hash, prev.Bytes()) {
+			break
+		}
+
+		header, err = d.get(&prev)
 		if err != nil {
-			return err
+			break
 		}
+		batch.Put(indexKey(header.Height), prev.Bytes())
 	}
-	return nil
+}
+
+// buildIndex indexes the main chain from the chain tip to genesis.
+func buildIndex(db *leveldb.DB) error {
+	d := &Database{
+		RWMutex:   new(sync.RWMutex),
+		db:        db,
+		cache:     newCache(100),
+		newHeader: sutil.NewEmptyHeader,
+	}
+	tip, err := d.getHeader(BKTChainTip)
+	if err != nil {
+		// Nothing to index in an empty database.
+		return nil
+	}
+
+	batch := new(leveldb.Batch)
+	d.index(batch, tip)
+	return db.Write(batch, nil)
 }
 
 func (d *Database) GetPrevious(header *util.Header) (*util.Header, error) {
@@ -105,12 +180,7 @@ func (d *Database) Get(hash *common.Uint256) (header *util.Header, err error) {
 	d.RLock()
 	defer d.RUnlock()
 
-	header, err = d.cache.get(hash)
-	if err == nil {
-		return header, nil
-	}
-
-	return d.getHeader(toKey(BKTHeaders, hash.Bytes()...))
+	return d.get(hash)
 }
 
 func (d *Database) GetBest() (header *util.Header, err error) {
@@ -124,13 +194,54 @@ func (d *Database) GetBest() (header *util.Header, err error) {
 	return d.getHeader(BKTChainTip)
 }
 
+func (d *Database) Del(hash *common.Uint256) error {
+	d.Lock()
+	defer d.Unlock()
+
+	d.cache.del(hash)
+	return d.db.Delete(toKey(BKTHeaders, hash.Bytes()...), nil)
+}
+
+func (d *Database) GetByHeight(height uint32) (*util.Header, error) {
+	d.RLock()
+	defer d.RUnlock()
+
+	hashBytes, err := d.db.Get(indexKey(height), nil)
+	if err != nil {
+		return nil, fmt.Errorf("header on height %d does not exist in"+
+			" database", height)
+	}
+
+	hash, err := common.Uint256FromBytes(hashBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.get(hash)
+}
+
+func (d *Database) GetRange(from, to uint32) ([]*util.Header, error) {
+	return database.GetHeadersRange(d, from, to)
+}
+
+func (d *Database) Iterate(from uint32, fn func(header *util.Header) bool) error {
+	return database.IterateHeaders(d, from, fn)
+}
+
 func (d *Database) Clear() error {
 	d.Lock()
 	defer d.Unlock()
 
+	// Reset cache, or the cached chain tip will be returned.
+	d.cache = newCache(d.cache.size)
+
 	batch := new(leveldb.Batch)
 	inter := d.db.NewIterator(nil, nil)
 	for inter.Next() {
+		// Keep the data format version.
+		if bytes.Equal(inter.Key(), BKTVersion) {
+			continue
+		}
 		batch.Delete(inter.Key())
 	}
 	inter.Release()
@@ -144,6 +255,15 @@ func (d *Database) Close() error {
 	return d.db.Close()
 }
 
+func (d *Database) get(hash *common.Uint256) (*util.Header, error) {
+	header, err := d.cache.get(hash)
+	if err == nil {
+		return header, nil
+	}
+
+	return d.getHeader(toKey(BKTHeaders, hash.Bytes()...))
+}
+
 func (d *Database) getHeader(key []byte) (*util.Header, error) {
 	data, err := d.db.Get(key, nil)
 	if err != nil {
@@ -161,6 +281,12 @@ func (d *Database) getHeader(key []byte) (*util.Header, error) {
 	return &header, nil
 }
 
+func indexKey(height uint32) []byte {
+	var key [4]byte
+	binary.LittleEndian.PutUint32(key[:], height)
+	return toKey(BKTIndexes, key[:]...)
+}
+
 func toKey(bucket []byte, index ...byte) []byte {
 	return append(bucket, index...)
 }
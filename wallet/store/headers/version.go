@@ -0,0 +1,55 @@
+package headers
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA.SPV/database"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+var (
+	BKTVersion = []byte("V")
+)
+
+// migrations returns the ordered migration steps of the headers database, add
+// a step with the next version when the data format changes.
+func migrations(db *leveldb.DB) []database.Migration {
+	return []database.Migration{
+		{Version: 1, Description: "data format before versioning"},
+		{Version: 2, Description: "build height index",
+			Upgrade: func() error {
+				return buildIndex(db)
+			}},
+	}
+}
+
+// Ensure version implement database.Versioned interface.
+var _ database.Versioned = (*version)(nil)
+
+// version records the data format version of the headers database, the
+// version record is kept when the database is cleared.
+type version struct {
+	db *leveldb.DB
+}
+
+func (v *version) Version() (uint32, error) {
+	data, err := v.db.Get(BKTVersion, nil)
+	if err == leveldb.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if len(data) != 4 {
+		return 0, fmt.Errorf("invalid version record %x", data)
+	}
+	return binary.BigEndian.Uint32(data), nil
+}
+
+func (v *version) SetVersion(version uint32) error {
+	var data [4]byte
+	binary.BigEndian.PutUint32(data[:], version)
+	return v.db.Put(BKTVersion, data[:], nil)
+}
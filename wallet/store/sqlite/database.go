@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"sync"
 
+	spvdb "github.com/elastos/Elastos.ELA.SPV/database"
+
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -33,6 +35,12 @@ func NewDatabase(dataDir string) (*database, error) {
 		fmt.Println("Open sqlite db error:", err)
 		return nil, err
 	}
+	// Upgrade tables to the current version
+	err = spvdb.Upgrade("wallet", &version{DB: db}, migrations(db))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
 	// Use the same lock
 	lock := new(sync.RWMutex)
 
@@ -119,7 +127,7 @@ func (d *database) Clear() error {
 	_, err = tx.Exec(`DROP TABLE IF EXISTS State;
 							DROP TABLE IF EXISTS UTXOs;
 							DROP TABLE IF EXISTS STXOs;
-							DROP TABLE IF EXISTS TXNs;`)
+							DROP TABLE IF EXISTS Txs;`)
 	if err != nil {
 		return err
 	}
@@ -74,7 +74,7 @@ func (d *dataBatch) RollbackHeight(height uint32) error {
 		return err
 	}
 
-	// Rollback TXNs
-	_, err = d.Exec("DELETE FROM TXNs WHERE Height=?", height)
+	// Rollback Txs
+	_, err = d.Exec("DELETE FROM Txs WHERE Height=?", height)
 	return err
 }
@@ -34,7 +34,7 @@ type batch interface {
 
 type State interface {
 	// save state height
-	PutHeight(height uint32)
+	PutHeight(height uint32) error
 
 	// get state height
 	GetHeight() uint32
@@ -47,9 +47,10 @@ func (s *state) GetHeight() uint32 {
 }
 
 // save state height
-func (s *state) PutHeight(height uint32) {
+func (s *state) PutHeight(height uint32) error {
 	s.Lock()
 	defer s.Unlock()
 
-	s.Exec("INSERT OR REPLACE INTO State(Key, Value) VALUES(?,?)", HeightKey, height)
+	_, err := s.Exec("INSERT OR REPLACE INTO State(Key, Value) VALUES(?,?)", HeightKey, height)
+	return err
 }
@@ -0,0 +1,37 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	spvdb "github.com/elastos/Elastos.ELA.SPV/database"
+)
+
+// migrations returns the ordered migration steps of the wallet database, add
+// a step with the next version when the tables change.
+func migrations(db *sql.DB) []spvdb.Migration {
+	return []spvdb.Migration{
+		{Version: 1, Description: "tables before versioning"},
+	}
+}
+
+// Ensure version implement spvdb.Versioned interface.
+var _ spvdb.Versioned = (*version)(nil)
+
+// version records the wallet database version in the sqlite user_version
+// pragma, which is kept when tables are dropped.
+type version struct {
+	*sql.DB
+}
+
+func (v *version) Version() (uint32, error) {
+	var version uint32
+	err := v.QueryRow("PRAGMA user_version").Scan(&version)
+	return version, err
+}
+
+func (v *version) SetVersion(version uint32) error {
+	// PRAGMA statements do not support parameters.
+	_, err := v.Exec(fmt.Sprintf("PRAGMA user_version = %d", version))
+	return err
+}
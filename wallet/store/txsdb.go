@@ -0,0 +1,197 @@
+package store
+
+import (
+	"bytes"
+
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/sdk"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/store/sqlite"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/sutil"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+)
+
+// Ensure TxsDB implement database.TxsDB interface.
+var _ database.TxsDB = (*TxsDB)(nil)
+
+// TxsDB stores the wallet transactions, UTXOs and STXOs of the main chain
+// and fork chains into the sqlite wallet database.
+type TxsDB struct {
+	db       sqlite.DataStore
+	filter   func() *sdk.AddrFilter
+	received func(ops []*util.OutPoint)
+}
+
+// NewTxsDB creates a TxsDB on the wallet database, filter returns the wallet
+// addresses and received is called with the outpoints received by the
+// transactions after they are committed, it can be nil.
+func NewTxsDB(db sqlite.DataStore, filter func() *sdk.AddrFilter,
+	received func(ops []*util.OutPoint)) *TxsDB {
+	return &TxsDB{db: db, filter: filter, received: received}
+}
+
+func (d *TxsDB) putTx(batch sqlite.DataBatch, utx util.Transaction,
+	height uint32, ops *[]*util.OutPoint) (bool, error) {
+
+	tx := utx.(*sutil.Tx)
+	txId := tx.Hash()
+	hits := 0
+
+	// Check if any UTXOs within this wallet have been spent.
+	for _, input := range tx.Inputs {
+		// Move UTXO to STXO
+		op := util.NewOutPoint(input.Previous.TxID, input.Previous.Index)
+		utxo, _ := d.db.UTXOs().Get(op)
+		// Skip if no match.
+		if utxo == nil {
+			continue
+		}
+
+		err := batch.STXOs().Put(sutil.NewSTXO(utxo, height, txId))
+		if err != nil {
+			return false, err
+		}
+		if err := batch.UTXOs().Del(op); err != nil {
+			return false, err
+		}
+		hits++
+	}
+
+	// Check if there are any output to this wallet address.
+	for index, output := range tx.Outputs {
+		// Filter address
+		if d.filter().ContainAddr(output.ProgramHash) {
+			var lockTime = output.OutputLock
+			if tx.TxType == types.CoinBase {
+				lockTime = height + 100
+			}
+			utxo := sutil.NewUTXO(txId, height, index, output.Value, lockTime, output.ProgramHash)
+			err := batch.UTXOs().Put(utxo)
+			if err != nil {
+				return false, err
+			}
+			*ops = append(*ops, utxo.Op)
+			hits++
+		}
+	}
+
+	// If no hits, no need to save transaction
+	if hits == 0 {
+		return true, nil
+	}
+
+	// Save transaction
+	err := batch.Txs().Put(util.NewTx(tx, height))
+	if err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// PutTxs persists the main chain transactions into database and can be
+// queried by GetTxs(height).  Returns the false positive transaction count
+// and error.
+func (d *TxsDB) PutTxs(txs []util.Transaction, height uint32) (uint32, error) {
+	fps := uint32(0)
+	var ops []*util.OutPoint
+	batch := d.db.Batch()
+	defer batch.Rollback()
+	for _, tx := range txs {
+		// Skip the transaction already stored, the block is put again when
+		// an interrupted chain reorganize is continued, and putting the
+		// transaction again would add its spent outputs back to UTXOs.
+		txId := tx.Hash()
+		if stored, _ := d.db.Txs().Get(&txId); stored != nil &&
+			stored.Height == height {
+			continue
+		}
+
+		fp, err := d.putTx(batch, tx, height, &ops)
+		if err != nil {
+			return 0, err
+		}
+		if fp {
+			fps++
+		}
+	}
+	if err := batch.Commit(); err != nil {
+		return 0, err
+	}
+
+	// The received outpoints are reported only if committed.
+	if d.received != nil && len(ops) > 0 {
+		d.received(ops)
+	}
+	return fps, nil
+}
+
+// PutForkTxs persists the fork chain transactions into database with the
+// fork block hash and can be queried by GetForkTxs(hash).
+func (d *TxsDB) PutForkTxs(txs []util.Transaction, hash *common.Uint256) error {
+	ftxs := make([]*util.Tx, 0, len(txs))
+	for _, tx := range txs {
+		ftxs = append(ftxs, util.NewTx(tx, 0))
+	}
+	return d.db.Txs().PutForkTxs(ftxs, hash)
+}
+
+// HaveTx returns if the transaction already saved in database
+// by it's id.
+func (d *TxsDB) HaveTx(txId *common.Uint256) (bool, error) {
+	tx, err := d.db.Txs().Get(txId)
+	return tx != nil, err
+}
+
+// GetTxs returns all transactions in main chain within the given height.
+func (d *TxsDB) GetTxs(height uint32) ([]util.Transaction, error) {
+	txs, err := d.db.Txs().GetAllFrom(height)
+	if err != nil {
+		return nil, err
+	}
+	return toTransactions(txs)
+}
+
+// GetForkTxs returns all transactions within the fork block hash.
+func (d *TxsDB) GetForkTxs(hash *common.Uint256) ([]util.Transaction, error) {
+	ftxs, err := d.db.Txs().GetForkTxs(hash)
+	if err != nil {
+		return nil, err
+	}
+	return toTransactions(ftxs)
+}
+
+// toTransactions deserializes the stored transactions.
+func toTransactions(txs []*util.Tx) ([]util.Transaction, error) {
+	utxs := make([]util.Transaction, 0, len(txs))
+	for _, tx := range txs {
+		wtx := sutil.NewTx(&types.Transaction{})
+		if err := wtx.Deserialize(bytes.NewReader(tx.RawData)); err != nil {
+			return nil, err
+		}
+		utxs = append(utxs, wtx)
+	}
+	return utxs, nil
+}
+
+// DelTxs remove all transactions in main chain within the given height.
+func (d *TxsDB) DelTxs(height uint32) error {
+	batch := d.db.Batch()
+	defer batch.Rollback()
+	if err := batch.RollbackHeight(height); err != nil {
+		return err
+	}
+	return batch.Commit()
+}
+
+// Clear delete all data in database.
+func (d *TxsDB) Clear() error {
+	return d.db.Clear()
+}
+
+// Close database.
+func (d *TxsDB) Close() error {
+	return d.db.Close()
+}
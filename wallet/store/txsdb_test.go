@@ -0,0 +1,161 @@
+package store
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA.SPV/database"
+	"github.com/elastos/Elastos.ELA.SPV/sdk"
+	"github.com/elastos/Elastos.ELA.SPV/util"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/store/headers"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/store/sqlite"
+	"github.com/elastos/Elastos.ELA.SPV/wallet/sutil"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestBlock creates the block after prev with the transactions, the nonce
+// makes blocks on the same height different.
+func newTestBlock(prev *util.Header, nonce uint32,
+	txs ...*types.Transaction) *util.Block {
+	header := &types.Header{Nonce: nonce}
+	height := uint32(0)
+	work := big.NewInt(1)
+	if prev != nil {
+		header.Previous = prev.Hash()
+		height = prev.Height + 1
+		work.Add(work, prev.TotalWork)
+	}
+	header.Height = height
+
+	block := &util.Block{Header: util.Header{
+		BlockHeader: sutil.NewHeader(header),
+		Height:      height,
+		TotalWork:   work,
+	}}
+	for _, tx := range txs {
+		block.Transactions = append(block.Transactions, sutil.NewTx(tx))
+	}
+	return block
+}
+
+// newTestTx creates a transaction spending the inputs and paying value to
+// addr.
+func newTestTx(addr common.Uint168, value common.Fixed64,
+	inputs ...*types.OutPoint) *types.Transaction {
+	var nonce [8]byte
+	rand.Read(nonce[:])
+	attr := types.NewAttribute(types.Nonce, nonce[:])
+	tx := &types.Transaction{
+		TxType:     types.TransferAsset,
+		Payload:    &payload.TransferAsset{},
+		Attributes: []*types.Attribute{&attr},
+		Outputs:    []*types.Output{{ProgramHash: addr, Value: value}},
+	}
+	for _, input := range inputs {
+		tx.Inputs = append(tx.Inputs, &types.Input{Previous: *input})
+	}
+	return tx
+}
+
+func TestTxsDB_RecoverReorganize(t *testing.T) {
+	dataDir := t.TempDir()
+	h, err := headers.NewDatabase(dataDir)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	db, err := sqlite.NewDatabase(dataDir)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	j, err := database.NewJournal(dataDir)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	var addr common.Uint168
+	rand.Read(addr[:])
+	filter := sdk.NewAddrFilter([]*common.Uint168{&addr})
+	txs := NewTxsDB(db, func() *sdk.AddrFilter { return filter }, nil)
+	chain, err := database.NewChainDB(h, txs, j, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer chain.Close()
+
+	// Main chain 0 <- 1 <- 2, tx1 on height 1 pays to addr.
+	tx1 := newTestTx(addr, 100)
+	main := []*util.Block{newTestBlock(nil, 0)}
+	main = append(main, newTestBlock(&main[0].Header, 0, tx1))
+	main = append(main, newTestBlock(&main[1].Header, 0, newTestTx(addr, 50)))
+	for _, block := range main {
+		if _, err := chain.CommitBlock(block, true); !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+
+	// Fork chain 1 <- 2' <- 3', tx2 on 2' spends tx1 and tx3 on 3' spends
+	// tx2.
+	tx2 := newTestTx(addr, 100, types.NewOutPoint(tx1.Hash(), 0))
+	tx3 := newTestTx(addr, 100, types.NewOutPoint(tx2.Hash(), 0))
+	fork := []*util.Block{newTestBlock(&main[1].Header, 1, tx2)}
+	fork = append(fork, newTestBlock(&fork[0].Header, 1, tx3))
+	for _, block := range fork {
+		if _, err := chain.CommitBlock(block, false); !assert.NoError(t, err) {
+			t.FailNow()
+		}
+	}
+	_, err = chain.ProcessReorganize(&main[1].Header, &main[2].Header,
+		&fork[1].Header)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// assertWallet asserts only the output of tx3 is unspent.
+	assertWallet := func() {
+		utxos, err := db.UTXOs().GetAll()
+		if !assert.NoError(t, err) || !assert.Len(t, utxos, 1) {
+			t.FailNow()
+		}
+		assert.Equal(t, tx3.Hash(), utxos[0].Op.TxID)
+		stxos, err := db.STXOs().GetAll()
+		assert.NoError(t, err)
+		assert.Len(t, stxos, 2)
+	}
+	assertWallet()
+
+	// Crash after the fork is attached but before the journal is removed,
+	// the attached blocks are put again on the next start.
+	err = j.Put(&database.JournalEntry{
+		Op:    database.OpReorganize,
+		Stage: database.StageAttach,
+		Hashes: []common.Uint256{main[1].Hash(), main[2].Hash(),
+			fork[1].Hash()},
+	})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	recovered, err := database.NewChainDB(h, txs, j, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assertWallet()
+
+	// The recovered reorganize is returned once to be notified.
+	reorg := recovered.RecoveredReorg()
+	if !assert.NotNil(t, reorg) {
+		t.FailNow()
+	}
+	assert.Equal(t, main[1].Hash(), reorg.CommonAncestor.Hash())
+	assert.Len(t, reorg.Detached, 1)
+	assert.Len(t, reorg.Attached, 2)
+	assert.Nil(t, recovered.RecoveredReorg())
+
+	entry, err := j.Get()
+	assert.NoError(t, err)
+	assert.Nil(t, entry)
+}